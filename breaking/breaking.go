@@ -0,0 +1,250 @@
+// Package breaking classifies the changes between two versions of API
+// documentation into breaking, additive and informational changes, using a
+// configurable rule set.
+package breaking
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/micrease/go-yapi/diff"
+	"gopkg.in/yaml.v3"
+)
+
+// Severity is the classification of a change.
+type Severity string
+
+const (
+	Breaking Severity = "breaking"
+	Additive Severity = "additive"
+	Info     Severity = "info"
+	Ignore   Severity = "ignore"
+)
+
+// Rule classifies changes. Endpoint level rules (added or removed interfaces)
+// have a nil Match and are applied by Classify directly.
+type Rule struct {
+	ID          string
+	Description string
+	Severity    Severity
+	Match       func(c *diff.Change) bool
+}
+
+// Rule IDs of the endpoint level rules.
+const (
+	RuleEndpointRemoved = "endpoint-removed"
+	RuleEndpointAdded   = "endpoint-added"
+	RuleDocChanged      = "doc-changed"
+)
+
+func isRequest(l diff.Location) bool {
+	switch l {
+	case diff.LocParams, diff.LocQuery, diff.LocHeaders, diff.LocForm, diff.LocReqBody:
+		return true
+	}
+	return false
+}
+
+func attrChanged(c *diff.Change, attr string, old, new interface{}) bool {
+	return c.Kind == diff.Changed && c.Attribute == attr && c.Old == old && c.New == new
+}
+
+// DefaultRules returns the built-in rule set. The first matching rule wins;
+// changes matching no rule are classified by RuleDocChanged.
+func DefaultRules() []Rule {
+	return []Rule{
+		{RuleEndpointRemoved, "an interface was removed", Breaking, nil},
+		{RuleEndpointAdded, "an interface was added", Additive, nil},
+		{"method-changed", "the request method changed", Breaking, func(c *diff.Change) bool {
+			return c.Location == diff.LocInterface && c.Name == "method"
+		}},
+		{"path-changed", "the request path changed", Breaking, func(c *diff.Change) bool {
+			return c.Location == diff.LocInterface && c.Name == "path"
+		}},
+		{"body-type-changed", "the request or response body type changed", Breaking, func(c *diff.Change) bool {
+			return c.Location == diff.LocInterface && (c.Name == "req_body_type" || c.Name == "res_body_type")
+		}},
+		{"response-field-removed", "a response field was removed", Breaking, func(c *diff.Change) bool {
+			return c.Location == diff.LocResBody && c.Kind == diff.Removed
+		}},
+		{"response-field-optional", "a response field is no longer required", Breaking, func(c *diff.Change) bool {
+			return c.Location == diff.LocResBody && attrChanged(c, "required", true, false)
+		}},
+		{"response-type-changed", "the type of a response field changed", Breaking, func(c *diff.Change) bool {
+			return c.Location == diff.LocResBody && c.Kind == diff.Changed && c.Attribute == "type"
+		}},
+		{"response-field-added", "a response field was added", Additive, func(c *diff.Change) bool {
+			return c.Location == diff.LocResBody && c.Kind == diff.Added
+		}},
+		{"request-required-added", "a required request field was added", Breaking, func(c *diff.Change) bool {
+			return isRequest(c.Location) && c.Kind == diff.Added && c.Required
+		}},
+		{"request-became-required", "a request field became required", Breaking, func(c *diff.Change) bool {
+			return isRequest(c.Location) && attrChanged(c, "required", false, true)
+		}},
+		{"request-type-changed", "the type of a request field changed", Breaking, func(c *diff.Change) bool {
+			return isRequest(c.Location) && c.Kind == diff.Changed && c.Attribute == "type"
+		}},
+		{"request-optional-added", "an optional request field was added", Additive, func(c *diff.Change) bool {
+			return isRequest(c.Location) && c.Kind == diff.Added && !c.Required
+		}},
+		{"request-became-optional", "a request field became optional", Additive, func(c *diff.Change) bool {
+			return isRequest(c.Location) && attrChanged(c, "required", true, false)
+		}},
+		{"request-field-removed", "a request field was removed", Info, func(c *diff.Change) bool {
+			return isRequest(c.Location) && c.Kind == diff.Removed
+		}},
+		{RuleDocChanged, "documentation only change", Info, nil},
+	}
+}
+
+// Config overrides the severity of rules by ID, e.g. {"rules": {"request-field-removed": "breaking"}}.
+type Config struct {
+	Rules map[string]Severity `json:"rules" yaml:"rules"`
+}
+
+// LoadConfig reads a JSON or YAML config file.
+func LoadConfig(path string) (*Config, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	c := new(Config)
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, c)
+	default:
+		err = json.Unmarshal(data, c)
+	}
+	return c, err
+}
+
+// Classifier applies a rule set to diff reports.
+type Classifier struct {
+	rules []Rule
+}
+
+// NewClassifier returns a Classifier using DefaultRules with the severities of
+// config applied. config may be nil.
+func NewClassifier(config *Config) (*Classifier, error) {
+	rules := DefaultRules()
+	if config != nil {
+		known := make(map[string]int, len(rules))
+		for i, r := range rules {
+			known[r.ID] = i
+		}
+		for id, severity := range config.Rules {
+			i, ok := known[id]
+			if !ok {
+				return nil, fmt.Errorf("breaking: unknown rule %q", id)
+			}
+			switch severity {
+			case Breaking, Additive, Info, Ignore:
+			default:
+				return nil, fmt.Errorf("breaking: unknown severity %q for rule %q", severity, id)
+			}
+			rules[i].Severity = severity
+		}
+	}
+	return &Classifier{rules: rules}, nil
+}
+
+// Finding is a classified change.
+type Finding struct {
+	Rule      string       `json:"rule"`
+	Severity  Severity     `json:"severity"`
+	Interface diff.Ref     `json:"interface"`
+	Change    *diff.Change `json:"change,omitempty"`
+	Message   string       `json:"message"`
+}
+
+// Result is the outcome of classifying a diff report.
+type Result struct {
+	Breaking int       `json:"breaking"`
+	Additive int       `json:"additive"`
+	Info     int       `json:"info"`
+	Findings []Finding `json:"findings"`
+}
+
+// HasBreaking reports whether a breaking change was found.
+func (r *Result) HasBreaking() bool {
+	return r.Breaking > 0
+}
+
+func (r *Result) add(f Finding) {
+	switch f.Severity {
+	case Ignore:
+		return
+	case Breaking:
+		r.Breaking++
+	case Additive:
+		r.Additive++
+	default:
+		r.Info++
+	}
+	r.Findings = append(r.Findings, f)
+}
+
+func (c *Classifier) rule(id string) Rule {
+	for _, r := range c.rules {
+		if r.ID == id {
+			return r
+		}
+	}
+	return Rule{ID: id, Severity: Info}
+}
+
+// Classify classifies every change of the report.
+func (c *Classifier) Classify(report *diff.Report) *Result {
+	res := &Result{Findings: []Finding{}}
+	for _, ref := range report.Removed {
+		r := c.rule(RuleEndpointRemoved)
+		res.add(Finding{Rule: r.ID, Severity: r.Severity, Interface: ref, Message: r.Description})
+	}
+	for _, ref := range report.Added {
+		r := c.rule(RuleEndpointAdded)
+		res.add(Finding{Rule: r.ID, Severity: r.Severity, Interface: ref, Message: r.Description})
+	}
+	for _, d := range report.Changed {
+		for i := range d.Changes {
+			change := &d.Changes[i]
+			r := c.match(change)
+			res.add(Finding{Rule: r.ID, Severity: r.Severity, Interface: d.New, Change: change, Message: change.String()})
+		}
+	}
+	return res
+}
+
+func (c *Classifier) match(change *diff.Change) Rule {
+	for _, r := range c.rules {
+		if r.Match != nil && r.Match(change) {
+			return r
+		}
+	}
+	return c.rule(RuleDocChanged)
+}
+
+// WriteText writes the findings grouped by severity, breaking changes first.
+func (r *Result) WriteText(w io.Writer) error {
+	for _, severity := range []Severity{Breaking, Additive, Info} {
+		for _, f := range r.Findings {
+			if f.Severity != severity {
+				continue
+			}
+			fmt.Fprintf(w, "[%s] %s: %s (%s)\n", f.Severity, f.Interface, f.Message, f.Rule)
+		}
+	}
+	_, err := fmt.Fprintf(w, "%d breaking, %d additive, %d info\n", r.Breaking, r.Additive, r.Info)
+	return err
+}
+
+// WriteJSON writes the result as indented JSON.
+func (r *Result) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
@@ -0,0 +1,103 @@
+package breaking
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	yapi "github.com/micrease/go-yapi"
+	"github.com/micrease/go-yapi/diff"
+)
+
+func testInterface(id int, method, path string) yapi.InterfaceData {
+	var d yapi.InterfaceData
+	d.ID, d.Method, d.Path, d.Title = id, method, path, path
+	d.ReqQuery = []yapi.ReqKVItemDetail{{ReqKVItemSimple: yapi.ReqKVItemSimple{Name: "page"}, Required: "0"}}
+	d.ResBodyType, d.ResBodyIsJsonSchema = "json", true
+	d.ResBody = `{"type":"object","properties":{"id":{"type":"integer"},"name":{"type":"string"}}}`
+	return d
+}
+
+func summarize(r *Result) []string {
+	var out []string
+	for _, f := range r.Findings {
+		out = append(out, string(f.Severity)+" "+f.Rule)
+	}
+	return out
+}
+
+func TestClassify(t *testing.T) {
+	old := []yapi.InterfaceData{testInterface(1, "GET", "/users"), testInterface(2, "GET", "/orders")}
+	users := testInterface(1, "GET", "/users")
+	users.Title = "list users"
+	users.ReqQuery[0].Required = "1"
+	users.ReqQuery = append(users.ReqQuery, yapi.ReqKVItemDetail{ReqKVItemSimple: yapi.ReqKVItemSimple{Name: "size"}})
+	users.ResBody = `{"type":"object","properties":{"id":{"type":"string"},"email":{"type":"string"}}}`
+	new := []yapi.InterfaceData{users, testInterface(3, "POST", "/carts")}
+
+	c, err := NewClassifier(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res := c.Classify(diff.Compare(old, new))
+	want := []string{
+		"breaking endpoint-removed",
+		"additive endpoint-added",
+		"info doc-changed",
+		"breaking request-became-required",
+		"additive request-optional-added",
+		"breaking response-type-changed",
+		"additive response-field-added",
+		"breaking response-field-removed",
+	}
+	if got := summarize(res); !reflect.DeepEqual(got, want) {
+		t.Errorf("findings =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if !res.HasBreaking() || res.Breaking != 4 || res.Additive != 3 || res.Info != 1 {
+		t.Errorf("counts = %d/%d/%d, want 4/3/1", res.Breaking, res.Additive, res.Info)
+	}
+
+	var text bytes.Buffer
+	res.WriteText(&text)
+	if !strings.HasPrefix(text.String(), "[breaking] GET /orders (/orders): an interface was removed (endpoint-removed)\n") ||
+		!strings.HasSuffix(text.String(), "4 breaking, 3 additive, 1 info\n") {
+		t.Errorf("unexpected text:\n%s", text.String())
+	}
+}
+
+func TestConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "breaking")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "rules.yaml")
+	ioutil.WriteFile(path, []byte("rules:\n  endpoint-removed: ignore\n  endpoint-added: breaking\n"), 0644)
+
+	config, err := LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := NewClassifier(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := diff.Compare(
+		[]yapi.InterfaceData{testInterface(1, "GET", "/users")},
+		[]yapi.InterfaceData{testInterface(2, "GET", "/orders")},
+	)
+	if got := summarize(c.Classify(report)); !reflect.DeepEqual(got, []string{"breaking endpoint-added"}) {
+		t.Errorf("findings = %v", got)
+	}
+
+	if _, err := NewClassifier(&Config{Rules: map[string]Severity{"no-such-rule": Info}}); err == nil {
+		t.Error("unknown rule accepted")
+	}
+	if _, err := NewClassifier(&Config{Rules: map[string]Severity{"path-changed": "fatal"}}); err == nil {
+		t.Error("unknown severity accepted")
+	}
+}
@@ -8,17 +8,18 @@ import (
 type CatMenuData []CatData
 
 type CatData struct {
-	ID   int    `json:"_id" structs:"_id"`
-	UID  int    `json:"uid" structs:"uid"`
-	Name string `json:"name" structs:"name"`
-	Desc string `json:"desc" structs:"desc"`
+	ID    int    `json:"_id" structs:"_id"`
+	UID   int    `json:"uid" structs:"uid"`
+	Name  string `json:"name" structs:"name"`
+	Desc  string `json:"desc" structs:"desc"`
+	Index int    `json:"index,omitempty" structs:"index"`
 }
 
 type CatMenu struct {
 	ErrCode int         `json:"errcode" structs:"errcode"`
 	ErrMsg  string      `json:"errmsg" structs:"errmsg"`
 	Data    CatMenuData `json:"data" structs:"data"`
-	string  string      `json:"string"`
+	string  string
 }
 
 func (p *CatMenu) ToString() string {
@@ -48,7 +49,7 @@ type ModifyMenumReq struct {
 type ModifyMenuResp struct {
 	CommonResp
 	Data   interface{} `json:"data" structs:"data"`
-	string string      `json:"string"`
+	string string
 }
 
 func (p *ModifyMenuResp) ToString() string {
@@ -89,3 +90,54 @@ func (s *CatMenuService) AddOrUpdate(param *ModifyMenuParam) (*ModifyMenuResp, e
 	err = json.Unmarshal([]byte(resp), &result)
 	return &result, err
 }
+
+type DelCatReq struct {
+	Token string `json:"token"`
+	CatID int    `json:"catid"`
+}
+
+// Delete removes a category together with its interfaces.
+func (s *CatMenuService) Delete(catID int) (*ModifyMenuResp, error) {
+	apiEndpoint := "api/interface/del_cat"
+	delCatReq := DelCatReq{}
+	delCatReq.Token = s.client.Authentication.token
+	delCatReq.CatID = catID
+
+	resp, err := s.client.Post(apiEndpoint, delCatReq)
+	if err != nil {
+		return nil, err
+	}
+	result := ModifyMenuResp{}
+	result.string = resp
+	err = json.Unmarshal([]byte(resp), &result)
+	return &result, err
+}
+
+// IndexItem is the position of a category or interface in the menu.
+type IndexItem struct {
+	ID    int `json:"id"`
+	Index int `json:"index"`
+}
+
+// UpIndex sets the menu position of categories.
+func (s *CatMenuService) UpIndex(items []IndexItem) (*ModifyResp, error) {
+	return s.client.upIndex("api/interface/up_cat_index", items)
+}
+
+func (c *Client) upIndex(apiEndpoint string, items []IndexItem) (*ModifyResp, error) {
+	apiEndpoint, err := addOptions(apiEndpoint, &ProjectParam{Token: c.Authentication.token})
+	if err != nil {
+		return nil, err
+	}
+	if items == nil {
+		items = []IndexItem{}
+	}
+	resp, err := c.Post(apiEndpoint, items)
+	if err != nil {
+		return nil, err
+	}
+	result := ModifyResp{}
+	result.string = resp
+	err = json.Unmarshal([]byte(resp), &result)
+	return &result, err
+}
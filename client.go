@@ -13,9 +13,9 @@ import (
 	"strings"
 )
 
-// httpClient defines an interface for an http.Client implementation so that alternative
+// HTTPClient defines an interface for an http.Client implementation so that alternative
 // http Clients can be passed in for making requests
-type httpClient interface {
+type HTTPClient interface {
 	Do(request *http.Request) (response *http.Response, err error)
 }
 
@@ -33,6 +33,19 @@ type AuthenticationService struct {
 	password string
 
 	token string
+
+	// Session cookies set by Login
+	cookies []*http.Cookie
+}
+
+// authenticate adds the basic auth credentials and session cookies to req.
+func (s *AuthenticationService) authenticate(req *http.Request) {
+	if s.authType == authTypeBasic && s.username != "" {
+		req.SetBasicAuth(s.username, s.password)
+	}
+	for _, cookie := range s.cookies {
+		req.AddCookie(cookie)
+	}
 }
 
 // A Client manages communication with the API.
@@ -40,11 +53,18 @@ type Client struct {
 	// Base URL for API requests.
 	baseURL *url.URL
 
+	// HTTP client used to communicate with the API, a new http.Client when nil.
+	httpClient HTTPClient
+
 	// Services used for talking to different parts of the API.
 	Authentication *AuthenticationService
 	Interface      *InterfaceService
 	Project        *ProjectService
 	CatMenu        *CatMenuService
+	Mock           *MockService
+	User           *UserService
+	Log            *LogService
+	Search         *SearchService
 }
 
 const (
@@ -77,6 +97,10 @@ func NewClient(baseURL string, apiToken string) (*Client, error) {
 	c.Project = &ProjectService{client: c}
 	c.Interface = &InterfaceService{client: c}
 	c.CatMenu = &CatMenuService{client: c}
+	c.Mock = &MockService{client: c}
+	c.User = &UserService{client: c}
+	c.Log = &LogService{client: c}
+	c.Search = &SearchService{client: c}
 	return c, nil
 }
 
@@ -115,12 +139,7 @@ func (c *Client) NewRawRequest(method, urlStr string, body io.Reader) (*http.Req
 		return nil, err
 	}
 
-	if c.Authentication.authType == authTypeBasic {
-		// Set basic auth information
-		if c.Authentication.username != "" {
-			req.SetBasicAuth(c.Authentication.username, c.Authentication.password)
-		}
-	}
+	c.Authentication.authenticate(req)
 
 	return req, nil
 }
@@ -152,12 +171,7 @@ func (c *Client) NewRequest(method, urlStr string, body interface{}) (*http.Requ
 		return nil, err
 	}
 
-	if c.Authentication.authType == authTypeBasic {
-		// Set basic auth information
-		if c.Authentication.username != "" {
-			req.SetBasicAuth(c.Authentication.username, c.Authentication.password)
-		}
-	}
+	c.Authentication.authenticate(req)
 
 	return req, nil
 }
@@ -202,12 +216,7 @@ func (c *Client) NewMultiPartRequest(method, urlStr string, buf *bytes.Buffer) (
 		return nil, err
 	}
 
-	if c.Authentication.authType == authTypeBasic {
-		// Set basic auth information
-		if c.Authentication.username != "" {
-			req.SetBasicAuth(c.Authentication.username, c.Authentication.password)
-		}
-	}
+	c.Authentication.authenticate(req)
 
 	return req, nil
 }
@@ -215,9 +224,7 @@ func (c *Client) NewMultiPartRequest(method, urlStr string, buf *bytes.Buffer) (
 // Do sends an API request and returns the API response.
 // The API response is JSON decoded and stored in the value pointed to by v, or returned as an error if an API error has occurred.
 func (c *Client) Do(req *http.Request, v interface{}) (string, error) {
-
-	httpClient := http.Client{}
-	resp, err := httpClient.Do(req)
+	resp, err := c.doer().Do(req)
 	if err != nil {
 		return "", err
 	}
@@ -251,6 +258,20 @@ func CheckResponse(r *http.Response) error {
 	return err
 }
 
+// doer returns the client used to send requests.
+func (c *Client) doer() HTTPClient {
+	if c.httpClient != nil {
+		return c.httpClient
+	}
+	return &http.Client{}
+}
+
+// SetHTTPClient sets the client used to send requests, for example an
+// *http.Client with a timeout or a custom Transport. nil restores the default.
+func (c *Client) SetHTTPClient(hc HTTPClient) {
+	c.httpClient = hc
+}
+
 // GetBaseURL will return you the Base URL.
 // This is the same URL as in the NewClient constructor
 func (c *Client) GetBaseURL() url.URL {
@@ -1,8 +1,6 @@
-package yapi
+package yapi_test
 
 import (
-	"encoding/json"
-	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
@@ -10,27 +8,25 @@ import (
 	"reflect"
 	"strings"
 	"testing"
-	"time"
-)
 
-const (
-	testInstanceURL = "http://yapi.iacorn.cn"
-	testToken = "8cde6e3bcbdb1c7bc0d8a2992fe0d79b0f07d186bfe7ebc5c6723b403e6830d8"
+	yapi "github.com/micrease/go-yapi"
+	"github.com/micrease/go-yapi/yapitest"
 )
 
+// testInstanceURL is only used to build requests, it is never contacted.
+const testInstanceURL = "http://yapi.example.com/"
+
 var (
 	// testMux is the HTTP request multiplexer used with the test server.
 	testMux *http.ServeMux
 
 	// testClient is the client being tested.
-	testClient *Client
+	testClient *yapi.Client
 
 	// testServer is a test HTTP server used to provide mock API responses.
 	testServer *httptest.Server
 )
 
-type testValues map[string]string
-
 type BodyTest struct {
 	ID string `json:"id,omitempty" structs:"id,omitempty"`
 }
@@ -43,7 +39,7 @@ func setup() {
 	testServer = httptest.NewServer(testMux)
 
 	// test client configured to use test server
-	testClient, _ = NewClient(nil, testServer.URL, "")
+	testClient, _ = yapi.NewClient(testServer.URL, "")
 }
 
 // teardown closes the test HTTP server.
@@ -51,34 +47,8 @@ func teardown() {
 	testServer.Close()
 }
 
-func testMethod(t *testing.T, r *http.Request, want string) {
-	if got := r.Method; got != want {
-		t.Errorf("Request method: %v, want %v", got, want)
-	}
-}
-
-func testRequestURL(t *testing.T, r *http.Request, want string) {
-	if got := r.URL.String(); !strings.HasPrefix(got, want) {
-		t.Errorf("Request URL: %v, want %v", got, want)
-	}
-}
-
-func testRequestParams(t *testing.T, r *http.Request, want map[string]string) {
-	params := r.URL.Query()
-
-	if len(params) != len(want) {
-		t.Errorf("Request params: %d, want %d", len(params), len(want))
-	}
-
-	for key, val := range want {
-		if got := params.Get(key); val != got {
-			t.Errorf("Request params: %s, want %s", got, val)
-		}
-	}
-}
-
 func TestNewClient_WrongUrl(t *testing.T) {
-	c, err := NewClient(nil, ":/3000/", "")
+	c, err := yapi.NewClient(":/3000/", "")
 
 	if err == nil {
 		t.Error("Expected an error. Got none")
@@ -88,24 +58,33 @@ func TestNewClient_WrongUrl(t *testing.T) {
 	}
 }
 
+// countingClient counts the requests sent through it.
+type countingClient struct {
+	n int
+}
+
+func (c *countingClient) Do(req *http.Request) (*http.Response, error) {
+	c.n++
+	return http.DefaultClient.Do(req)
+}
+
 func TestNewClient_WithHttpClient(t *testing.T) {
-	httpClient := http.DefaultClient
-	httpClient.Timeout = 10 * time.Minute
-	c, err := NewClient(httpClient, testInstanceURL, "")
+	setup()
+	defer teardown()
+	testMux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {})
 
-	if err != nil {
+	hc := new(countingClient)
+	testClient.SetHTTPClient(hc)
+	if _, err := testClient.Get("/", nil); err != nil {
 		t.Errorf("Got an error: %s", err)
 	}
-	if c == nil {
-		t.Error("Expected a client. Got none")
-	}
-	if !reflect.DeepEqual(c.client, httpClient) {
-		t.Errorf("HTTP clients are not equal. Injected %+v, got %+v", httpClient, c.client)
+	if hc.n != 1 {
+		t.Errorf("Injected HTTP client sent %d requests, want 1", hc.n)
 	}
 }
 
 func TestNewClient_WithServices(t *testing.T) {
-	c, err := NewClient(nil, testInstanceURL, "")
+	c, err := yapi.NewClient(testInstanceURL, "")
 
 	if err != nil {
 		t.Errorf("Got an error: %s", err)
@@ -116,6 +95,9 @@ func TestNewClient_WithServices(t *testing.T) {
 	if c.Project == nil {
 		t.Error("No ProjectService provided")
 	}
+	if c.CatMenu == nil {
+		t.Error("No CatMenuService provided")
+	}
 
 	if c.Authentication == nil {
 		t.Error("No AuthenticationService provided")
@@ -131,14 +113,14 @@ func TestCheckResponse(t *testing.T) {
 		r := &http.Response{
 			StatusCode: c,
 		}
-		if err := CheckResponse(r); err != nil {
+		if err := yapi.CheckResponse(r); err != nil {
 			t.Errorf("CheckResponse throws an error: %s", err)
 		}
 	}
 }
 
 func TestClient_NewRequest(t *testing.T) {
-	c, err := NewClient(nil, testInstanceURL, "")
+	c, err := yapi.NewClient(testInstanceURL, "")
 	if err != nil {
 		t.Errorf("An error occurred. Expected nil. Got %+v.", err)
 	}
@@ -160,7 +142,7 @@ func TestClient_NewRequest(t *testing.T) {
 }
 
 func TestClient_NewRawRequest(t *testing.T) {
-	c, err := NewClient(nil, testInstanceURL, "")
+	c, err := yapi.NewClient(testInstanceURL, "")
 	if err != nil {
 		t.Errorf("An error occurred. Expected nil. Got %+v.", err)
 	}
@@ -193,7 +175,7 @@ func testURLParseError(t *testing.T, err error) {
 }
 
 func TestClient_NewRequest_BadURL(t *testing.T) {
-	c, err := NewClient(nil, testInstanceURL, "")
+	c, err := yapi.NewClient(testInstanceURL, "")
 	if err != nil {
 		t.Errorf("An error occurred. Expected nil. Got %+v.", err)
 	}
@@ -206,7 +188,7 @@ func TestClient_NewRequest_BadURL(t *testing.T) {
 // since there is no difference between an HTTP request body that is an empty string versus one that is not set at all.
 // However in certain cases, intermediate systems may treat these differently resulting in subtle errors.
 func TestClient_NewRequest_EmptyBody(t *testing.T) {
-	c, err := NewClient(nil, testInstanceURL, "")
+	c, err := yapi.NewClient(testInstanceURL, "")
 	if err != nil {
 		t.Errorf("An error occurred. Expected nil. Got %+v.", err)
 	}
@@ -223,51 +205,24 @@ func TestClient_Do(t *testing.T) {
 	setup()
 	defer teardown()
 
-	type foo struct {
-		A string
-	}
-
 	testMux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		if m := "GET"; m != r.Method {
 			t.Errorf("Request method = %v, want %v", r.Method, m)
 		}
-		fmt.Fprint(w, `{"A":"a"}`)
+		w.Write([]byte(`{"A":"a"}`))
 	})
 
 	req, _ := testClient.NewRequest("GET", "/", nil)
-	body := new(foo)
-	_, err := testClient.Do(req, body)
+	body, err := testClient.Do(req, nil)
 	if err != nil {
-		t.Error("Expected error to be returned.")
+		t.Errorf("Unexpected error: %v", err)
 	}
 
-	want := &foo{"a"}
-	if !reflect.DeepEqual(body, want) {
+	if want := `{"A":"a"}`; body != want {
 		t.Errorf("Response body = %v, want %v", body, want)
 	}
 }
 
-func TestClient_Do_HTTPResponse(t *testing.T) {
-	setup()
-	defer teardown()
-	testMux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-		if m := "GET"; m != r.Method {
-			t.Errorf("Request method = %v, want %v", r.Method, m)
-		}
-		fmt.Fprint(w, `{"A":"a"}`)
-	})
-
-	req, _ := testClient.NewRequest("GET", "/", nil)
-	res, _ := testClient.Do(req, nil)
-	_, err := ioutil.ReadAll(res.Body)
-
-	if err != nil {
-		t.Errorf("Error on parsing HTTP Response = %v", err.Error())
-	} else if res.StatusCode != 200 {
-		t.Errorf("Response code = %v, want %v", res.StatusCode, 200)
-	}
-}
-
 func TestClient_Do_HTTPError(t *testing.T) {
 	setup()
 	defer teardown()
@@ -311,7 +266,7 @@ func TestClient_GetBaseURL_WithURL(t *testing.T) {
 		t.Errorf("URL parsing -> Got an error: %s", err)
 	}
 
-	c, err := NewClient(nil, testInstanceURL, "")
+	c, err := yapi.NewClient(testInstanceURL, "")
 	if err != nil {
 		t.Errorf("Client creation -> Got an error: %s", err)
 	}
@@ -325,106 +280,159 @@ func TestClient_GetBaseURL_WithURL(t *testing.T) {
 }
 
 func TestClient_AddOrUpdateInterfaceData(t *testing.T) {
-	c, err := NewClient(nil, testInstanceURL, testToken)
-	if err != nil {
-		t.Errorf("Client creation -> Got an error: %s", err)
-	}
-	if c == nil {
-		t.Error("Expected a client. Got none")
-	}
+	srv := yapitest.NewServer()
+	defer srv.Close()
+	c := srv.Client(srv.AddProject(yapi.ProjectData{Name: "demo"}))
 
 	markMenu := "test"
 
 	// 获取项目id
-	project, _, _ := c.Project.Get()
+	project, err := c.Project.Get()
+	if err != nil || project.ErrCode != 0 {
+		t.Fatalf("Project -> %+v, %v", project, err)
+	}
 	projectId := project.Data.ID
-	fmt.Printf("项目id:%d\n", projectId)
 
-	// 获取项目下的分类
-	catMenu,_,_ := c.CatMenu.Get(projectId)
-	fmt.Println("项目目录分类：")
-	printResult(catMenu)
-
-	var menuExsit *CatData
-	for _, menu := range catMenu.Data {
+	// 获取项目下的分类，不存在则创建
+	modifyMenuParam := new(yapi.ModifyMenuParam)
+	modifyMenuParam.ProjectID = projectId
+	modifyMenuParam.Name = markMenu
+	if resp, err := c.CatMenu.AddOrUpdate(modifyMenuParam); err != nil || resp.ErrCode != 0 {
+		t.Fatalf("AddOrUpdate category -> %+v, %v", resp, err)
+	}
+	catMenu, err := c.CatMenu.Get(projectId)
+	if err != nil || catMenu.ErrCode != 0 {
+		t.Fatalf("CatMenu -> %+v, %v", catMenu, err)
+	}
+	var menuExsit *yapi.CatData
+	for i, menu := range catMenu.Data {
 		if menu.Name == markMenu {
-			menuExsit = &menu
+			menuExsit = &catMenu.Data[i]
 		}
 	}
-
 	if menuExsit == nil {
-		// 不存在，创建新目录
-		modifyMenuParam := new(ModifyMenuParam)
-		modifyMenuParam.ProjectID = projectId
-		modifyMenuParam.Name = markMenu
-		modifyMenuResp,_,_ := c.CatMenu.AddOrUpdate(modifyMenuParam)
-		fmt.Println(modifyMenuResp)
+		t.Fatalf("Category %q not found in %+v", markMenu, catMenu.Data)
 	}
 
-	interfaceDataResp, _, _  := c.Interface.Get(415)
-	printResult(interfaceDataResp)
-
 	// 新增或者修改
-	interfaceData := interfaceDataResp.Data
-	interfaceData.Title = "测试修idea1111111改1111"
+	var interfaceData yapi.InterfaceData
+	interfaceData.Title = "测试"
+	interfaceData.Method = "POST"
+	interfaceData.Path = "/test"
 	interfaceData.ReqBodyType = "json"
 
 	// 添加body
-	reqKVItemSimple := new(ReqKVItemSimple)
+	reqKVItemSimple := new(yapi.ReqKVItemSimple)
 	reqKVItemSimple.Name = "test"
 	reqKVItemSimple.Desc = "测试字段"
 	reqKVItemSimple.Example = "test"
-	reqKVItemSimple.Value = ""
 
-	reqKVItemDetail := new(ReqKVItemDetail)
+	reqKVItemDetail := new(yapi.ReqKVItemDetail)
 	reqKVItemDetail.Type = "text"
-	reqKVItemDetail.Value = ""
 	reqKVItemDetail.Example = "111"
 	reqKVItemDetail.Desc = "111"
 	reqKVItemDetail.Required = "1"
 	reqKVItemDetail.Name = "11111"
 
-	details := append([]ReqKVItemDetail{}, *reqKVItemDetail)
+	details := append([]yapi.ReqKVItemDetail{}, *reqKVItemDetail)
 
-	//marshal, err := json.Marshal(reqKVItemSimple)
-	interfaceData.ReqBodyOther = "{\n    \"$schema\": \"http://json-schema.org/schema#\",\n    \"type\": \"object\",\n    \"properties\": {\n        \"Bar\": {\n            \"type\": \"string\"\n        },\n        \"Baz\": {\n            \"type\": \"array\",\n            \"items\": {\n                \"type\": \"string\"\n            }\n        },\n        \"List\": {\n            \"type\": \"array\",\n            \"items\": {\n                \"type\": \"object\",\n                \"properties\": {\n                    \"Value\": {\n                        \"type\": \"string\"\n                    }\n                },\n                \"required\": [\n                    \"Value\"\n                ]\n            }\n        },\n        \"Qux\": {\n            \"type\": \"integer\"\n        },\n        \"Zoo\": {\n            \"type\": \"string\"\n        },\n        \"foo\": {\n            \"type\": \"boolean\"\n        }\n    },\n    \"required\": [\n        \"foo\",\n        \"Qux\",\n        \"Baz\",\n        \"Zoo\",\n        \"List\"\n    ]\n}"
-	interfaceData.ID = 0
+	interfaceData.ReqBodyOther = `{"$schema": "http://json-schema.org/schema#", "type": "object", "properties": {"foo": {"type": "boolean"}}, "required": ["foo"]}`
+	interfaceData.ReqBodyIsJsonSchema = true
 	interfaceData.ProjectID = projectId
 	interfaceData.CatID = menuExsit.ID
-
-	//interfaceData.ResBodyIsJsonSchema = false
 	interfaceData.ReqBodyForm = details
-	interfaceData.ReqParams = append([]ReqKVItemSimple{}, *reqKVItemSimple)
-	interfaceData.ReqQuery= details
+	interfaceData.ReqParams = append([]yapi.ReqKVItemSimple{}, *reqKVItemSimple)
+	interfaceData.ReqQuery = details
 	interfaceData.ReqHeaders = details
 
-	addOrUpdateResp, _, _:= c.Interface.AddOrUpdate(&interfaceData)
-	printResult(addOrUpdateResp)
+	for _, title := range []string{"测试", "测试修改"} {
+		interfaceData.Title = title
+		addOrUpdateResp, err := c.Interface.AddOrUpdate(&interfaceData)
+		if err != nil || addOrUpdateResp.ErrCode != 0 {
+			t.Fatalf("AddOrUpdate interface -> %+v, %v", addOrUpdateResp, err)
+		}
+	}
+
+	saved := srv.Interfaces(projectId)
+	if len(saved) != 1 {
+		t.Fatalf("Saved %d interfaces, want 1", len(saved))
+	}
+	got, err := c.Interface.Get(saved[0].ID)
+	if err != nil || got.ErrCode != 0 {
+		t.Fatalf("Get interface -> %+v, %v", got, err)
+	}
+	if got.Data.Title != "测试修改" || got.Data.CatID != menuExsit.ID || !reflect.DeepEqual(got.Data.ReqQuery, details) {
+		t.Errorf("Saved interface = %+v", got.Data)
+	}
 }
 
-func printResult(result interface{}) {
-	marshal,_ := json.Marshal(result)
-	api := string(marshal)
-	fmt.Println(api)
+// AddOrUpdate sends the whole interface to api/interface/save; it used to
+// send the token alone.
+func TestClient_AddOrUpdateKeepsFields(t *testing.T) {
+	srv := yapitest.NewServer()
+	defer srv.Close()
+	c := srv.Client(srv.AddProject(yapi.ProjectData{Name: "demo"}))
+	project, err := c.Project.Get()
+	if err != nil || project.ErrCode != 0 {
+		t.Fatalf("Project -> %+v, %v", project, err)
+	}
+	catID := srv.AddCategory(project.Data.ID, yapi.CatData{Name: "users"})
+
+	var d yapi.InterfaceData
+	d.ProjectID, d.CatID = project.Data.ID, catID
+	d.Method, d.Path, d.Title, d.Status = "POST", "/users/{id}", "update user", "done"
+	d.Tag = []string{"v1"}
+	d.ReqParams = []yapi.ReqKVItemSimple{{Name: "id", Example: "7", Desc: "user id"}}
+	d.ReqHeaders = []yapi.ReqKVItemDetail{{ReqKVItemSimple: yapi.ReqKVItemSimple{Name: "X-Token"}, Required: "1"}}
+	d.ReqQuery = []yapi.ReqKVItemDetail{{ReqKVItemSimple: yapi.ReqKVItemSimple{Name: "dry"}, Required: "0"}}
+	d.ReqBodyType, d.ReqBodyIsJsonSchema = "json", true
+	d.ReqBodyOther = `{"type":"object","properties":{"name":{"type":"string"}}}`
+	d.ResBodyType, d.ResBodyIsJsonSchema = "json", true
+	d.ResBody = `{"type":"object","properties":{"id":{"type":"integer"}}}`
+	if resp, err := c.Interface.AddOrUpdate(&d); err != nil || resp.ErrCode != 0 {
+		t.Fatalf("AddOrUpdate -> %+v, %v", resp, err)
+	}
+
+	saved := srv.Interfaces(project.Data.ID)
+	if len(saved) != 1 {
+		t.Fatalf("Saved %d interfaces, want 1", len(saved))
+	}
+	got := saved[0]
+	if got.Method != d.Method || got.Path != d.Path || got.Title != d.Title || got.Status != d.Status || got.CatID != catID || !reflect.DeepEqual(got.Tag, d.Tag) {
+		t.Errorf("Saved interface = %+v", got)
+	}
+	if !reflect.DeepEqual(got.ReqParams, d.ReqParams) || !reflect.DeepEqual(got.ReqHeaders, d.ReqHeaders) || !reflect.DeepEqual(got.ReqQuery, d.ReqQuery) {
+		t.Errorf("Saved request parameters = %+v %+v %+v", got.ReqParams, got.ReqHeaders, got.ReqQuery)
+	}
+	if got.ReqBodyType != "json" || !got.ReqBodyIsJsonSchema || got.ReqBodyOther != d.ReqBodyOther || got.ResBodyType != "json" || !got.ResBodyIsJsonSchema || got.ResBody != d.ResBody {
+		t.Errorf("Saved bodies = %+v", got)
+	}
 }
 
 func TestClient_UploadSwagger(t *testing.T) {
-	// 使用ioutil一次性读取文件
-	data, err := ioutil.ReadFile("E:\\golang\\global_gopath\\src\\github.com\\swaggo\\swag\\testdata\\composition\\docs\\swagger.json")
-	if err != nil {
-		fmt.Println("read file err:", err.Error())
-		return
+	srv := yapitest.NewServer()
+	defer srv.Close()
+	token := srv.AddProject(yapi.ProjectData{Name: "demo"})
+	c := srv.Client(token)
+
+	swagger := `{
+		"swagger": "2.0",
+		"tags": [{"name": "pets", "description": "Everything about pets"}],
+		"paths": {
+			"/pets": {
+				"get": {"summary": "List pets", "tags": ["pets"]},
+				"post": {"summary": "Create a pet", "tags": ["pets"]}
+			}
+		}
+	}`
+	result, err := c.Interface.UploadSwagger(&swagger)
+	if err != nil || result.ErrCode != 0 {
+		t.Fatalf("UploadSwagger -> %+v, %v", result, err)
 	}
 
-	c, err := NewClient(nil, testInstanceURL, testToken)
-	if err != nil {
-		t.Errorf("Client creation -> Got an error: %s", err)
+	project, _ := c.Project.Get()
+	interfaces := srv.Interfaces(project.Data.ID)
+	if len(interfaces) != 2 || interfaces[0].Title != "List pets" || interfaces[1].Method != "POST" {
+		t.Errorf("Imported interfaces = %+v", interfaces)
 	}
-	if c == nil {
-		t.Error("Expected a client. Got none")
-	}
-
-	swagger := string(data)
-	result,_,_ := c.Interface.UploadSwagger(&swagger)
-	printResult(result)
-}
\ No newline at end of file
+}
@@ -0,0 +1,83 @@
+// Command yapi-breaking compares two versions of a YApi project and exits with
+// status 1 when the new version contains breaking changes. Each version is a
+// snapshot directory or a "json" data export file; when -old is empty the
+// current state of the remote project is used.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	yapi "github.com/micrease/go-yapi"
+	"github.com/micrease/go-yapi/breaking"
+	"github.com/micrease/go-yapi/diff"
+	"github.com/micrease/go-yapi/snapshot"
+)
+
+func main() {
+	oldPath := flag.String("old", "", "snapshot directory or export file of the current version; the remote project when empty")
+	newPath := flag.String("new", "", "snapshot directory or export file of the new version")
+	baseURL := flag.String("url", os.Getenv("YAPI_BASE_URL"), "YApi base URL, used when -old is empty")
+	token := flag.String("token", os.Getenv("YAPI_TOKEN"), "YApi project token, used when -old is empty")
+	rules := flag.String("rules", "", "JSON or YAML file overriding rule severities")
+	format := flag.String("format", "text", "output format, text or json")
+	flag.Parse()
+
+	if *newPath == "" || (*oldPath == "" && (*baseURL == "" || *token == "")) {
+		fmt.Fprintln(os.Stderr, "-new and either -old or -url and -token are required")
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var config *breaking.Config
+	if *rules != "" {
+		var err error
+		if config, err = breaking.LoadConfig(*rules); err != nil {
+			fail(err)
+		}
+	}
+	classifier, err := breaking.NewClassifier(config)
+	if err != nil {
+		fail(err)
+	}
+
+	var old []yapi.InterfaceData
+	if *oldPath != "" {
+		old, err = snapshot.LoadInterfaces(*oldPath)
+	} else {
+		var client *yapi.Client
+		client, err = yapi.NewClient(*baseURL, *token)
+		if err == nil {
+			var s *snapshot.Snapshot
+			if s, err = snapshot.Dump(client); err == nil {
+				old = s.Interfaces()
+			}
+		}
+	}
+	if err != nil {
+		fail(err)
+	}
+	new, err := snapshot.LoadInterfaces(*newPath)
+	if err != nil {
+		fail(err)
+	}
+
+	result := classifier.Classify(diff.Compare(old, new))
+	if *format == "json" {
+		err = result.WriteJSON(os.Stdout)
+	} else {
+		err = result.WriteText(os.Stdout)
+	}
+	if err != nil {
+		fail(err)
+	}
+	if result.HasBreaking() {
+		os.Exit(1)
+	}
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(2)
+}
@@ -0,0 +1,48 @@
+// Command yapi-mock serves mock responses for the interfaces of a YApi project,
+// loaded either from the YApi API or from a "json" data export file.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+
+	yapi "github.com/micrease/go-yapi"
+	"github.com/micrease/go-yapi/mockserver"
+)
+
+func main() {
+	addr := flag.String("addr", ":3000", "listen address")
+	file := flag.String("file", "", "YApi json export file to load interfaces from")
+	baseURL := flag.String("url", os.Getenv("YAPI_BASE_URL"), "YApi base URL, used when -file is empty")
+	token := flag.String("token", os.Getenv("YAPI_TOKEN"), "YApi project token, used when -file is empty")
+	prefix := flag.String("prefix", "", "path prefix stripped before matching, e.g. /mock/11")
+	seed := flag.Int64("seed", 0, "seed of the random data generator")
+	flag.Parse()
+
+	var interfaces []yapi.InterfaceData
+	var err error
+	if *file != "" {
+		interfaces, err = mockserver.LoadFile(*file)
+	} else {
+		if *baseURL == "" || *token == "" {
+			fmt.Fprintln(os.Stderr, "either -file or -url and -token are required")
+			flag.Usage()
+			os.Exit(2)
+		}
+		var client *yapi.Client
+		client, err = yapi.NewClient(*baseURL, *token)
+		if err == nil {
+			interfaces, err = mockserver.LoadProject(client)
+		}
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	log.Printf("serving %d interfaces on %s", len(interfaces), *addr)
+	server := mockserver.New(interfaces, &mockserver.Options{Seed: *seed, Prefix: *prefix})
+	log.Fatal(http.ListenAndServe(*addr, server))
+}
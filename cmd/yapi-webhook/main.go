@@ -0,0 +1,111 @@
+// Command yapi-webhook polls a YApi project and posts its changes to the
+// webhook targets of a JSON or YAML config file:
+//
+//	url: http://yapi.example.com
+//	token: ...
+//	interval: 1m
+//	checkpoint: yapi-webhook.checkpoint.json
+//	dead_letter: yapi-webhook.dead.jsonl
+//	retries: 3
+//	backoff: 2s
+//	targets:
+//	  - name: team
+//	    url: https://oapi.dingtalk.com/robot/send?access_token=...
+//	    format: dingtalk
+//	    secret: SEC...
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	yapi "github.com/micrease/go-yapi"
+	"github.com/micrease/go-yapi/watch"
+	"github.com/micrease/go-yapi/webhook"
+	"gopkg.in/yaml.v3"
+)
+
+type config struct {
+	URL        string           `json:"url" yaml:"url"`
+	Token      string           `json:"token" yaml:"token"`
+	Interval   string           `json:"interval" yaml:"interval"`
+	Checkpoint string           `json:"checkpoint" yaml:"checkpoint"`
+	DeadLetter string           `json:"dead_letter" yaml:"dead_letter"`
+	Retries    int              `json:"retries" yaml:"retries"`
+	Backoff    string           `json:"backoff" yaml:"backoff"`
+	Targets    []webhook.Target `json:"targets" yaml:"targets"`
+}
+
+func loadConfig(path string) (*config, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	c := &config{Interval: "1m", Backoff: "2s", Retries: 3}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, c)
+	default:
+		err = json.Unmarshal(data, c)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	if c.URL == "" {
+		c.URL = os.Getenv("YAPI_BASE_URL")
+	}
+	if c.Token == "" {
+		c.Token = os.Getenv("YAPI_TOKEN")
+	}
+	return c, nil
+}
+
+func main() {
+	configPath := flag.String("config", "yapi-webhook.yaml", "config file")
+	flag.Parse()
+
+	conf, err := loadConfig(*configPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	interval, err := time.ParseDuration(conf.Interval)
+	if err != nil {
+		log.Fatalf("interval: %v", err)
+	}
+	backoff, err := time.ParseDuration(conf.Backoff)
+	if err != nil {
+		log.Fatalf("backoff: %v", err)
+	}
+	dispatcher, err := webhook.New(conf.Targets, &webhook.Options{Retries: conf.Retries, Backoff: backoff, DeadLetter: conf.DeadLetter})
+	if err != nil {
+		log.Fatal(err)
+	}
+	client, err := yapi.NewClient(conf.URL, conf.Token)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	opts := &watch.Options{Interval: interval, OnError: func(err error) { log.Printf("poll: %v", err) }}
+	if conf.Checkpoint != "" {
+		opts.Store = &watch.FileStore{Path: conf.Checkpoint}
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	log.Printf("watching %s every %s, %d targets", conf.URL, interval, len(conf.Targets))
+	watch.New(client, opts).Run(ctx, func(e watch.Event) {
+		if err := dispatcher.Dispatch(e); err != nil {
+			log.Print(err)
+		}
+	})
+}
@@ -0,0 +1,535 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+
+	yapi "github.com/micrease/go-yapi"
+	"github.com/micrease/go-yapi/diff"
+	"github.com/micrease/go-yapi/docgen"
+	"github.com/micrease/go-yapi/internal/jsonyaml"
+	"github.com/micrease/go-yapi/snapshot"
+)
+
+// setup parses the flags of a command, checks the number of positional
+// arguments and connects to YApi.
+func (c *cli) setup(fs *flag.FlagSet, args []string, nargs int) ([]string, *yapi.Client, error) {
+	if err := c.parse(fs, args); err != nil {
+		return nil, nil, err
+	}
+	if fs.NArg() != nargs {
+		return nil, nil, errUsage
+	}
+	client, err := c.client()
+	return fs.Args(), client, err
+}
+
+func projectOf(client *yapi.Client) (*yapi.ProjectData, error) {
+	project, err := client.Project.Get()
+	if err != nil {
+		return nil, err
+	}
+	if err := yapi.CheckErrCode(project.ErrCode, project.ErrMsg); err != nil {
+		return nil, err
+	}
+	return &project.Data, nil
+}
+
+func projectGet(c *cli, args []string) error {
+	_, client, err := c.setup(c.flags("project get"), args, 0)
+	if err != nil {
+		return err
+	}
+	project, err := projectOf(client)
+	if err != nil {
+		return err
+	}
+	var envs []string
+	for _, env := range project.Env {
+		envs = append(envs, env.Name+"="+env.Domain)
+	}
+	t := &table{}
+	t.add("id", project.ID)
+	t.add("name", project.Name)
+	t.add("basepath", project.Basepath)
+	t.add("group_id", project.GroupID)
+	t.add("env", strings.Join(envs, " "))
+	return c.print(project, t)
+}
+
+func catList(c *cli, args []string) error {
+	_, client, err := c.setup(c.flags("cat list"), args, 0)
+	if err != nil {
+		return err
+	}
+	project, err := projectOf(client)
+	if err != nil {
+		return err
+	}
+	menu, err := client.CatMenu.Get(project.ID)
+	if err != nil {
+		return err
+	}
+	if err := yapi.CheckErrCode(menu.ErrCode, menu.ErrMsg); err != nil {
+		return err
+	}
+	t := &table{header: []string{"ID", "NAME", "DESC"}}
+	for _, cat := range menu.Data {
+		t.add(cat.ID, cat.Name, cat.Desc)
+	}
+	return c.print(menu.Data, t)
+}
+
+func catAdd(c *cli, args []string) error {
+	fs := c.flags("cat add")
+	desc := fs.String("desc", "", "category description")
+	args, client, err := c.setup(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	project, err := projectOf(client)
+	if err != nil {
+		return err
+	}
+	param := new(yapi.ModifyMenuParam)
+	param.ProjectID = project.ID
+	param.Name = args[0]
+	param.Desc = *desc
+	resp, err := client.CatMenu.AddOrUpdate(param)
+	if err != nil {
+		return err
+	}
+	if err := yapi.CheckErrCode(resp.ErrCode, resp.ErrMsg); err != nil {
+		return err
+	}
+	var cat yapi.CatData
+	raw, _ := json.Marshal(resp.Data)
+	json.Unmarshal(raw, &cat)
+	t := &table{}
+	t.add("id", cat.ID)
+	t.add("name", cat.Name)
+	return c.print(cat, t)
+}
+
+func catRm(c *cli, args []string) error {
+	args, client, err := c.setup(c.flags("cat rm"), args, 1)
+	if err != nil {
+		return err
+	}
+	id, err := strconv.Atoi(args[0])
+	if err != nil {
+		return errUsage
+	}
+	resp, err := client.CatMenu.Delete(id)
+	if err != nil {
+		return err
+	}
+	return yapi.CheckErrCode(resp.ErrCode, resp.ErrMsg)
+}
+
+func ifaceList(c *cli, args []string) error {
+	fs := c.flags("iface list")
+	catID := fs.Int("cat", 0, "category ID")
+	_, client, err := c.setup(fs, args, 0)
+	if err != nil {
+		return err
+	}
+	catIDs := []int{*catID}
+	if *catID == 0 {
+		project, err := projectOf(client)
+		if err != nil {
+			return err
+		}
+		menu, err := client.CatMenu.Get(project.ID)
+		if err != nil {
+			return err
+		}
+		if err := yapi.CheckErrCode(menu.ErrCode, menu.ErrMsg); err != nil {
+			return err
+		}
+		catIDs = catIDs[:0]
+		for _, cat := range menu.Data {
+			catIDs = append(catIDs, cat.ID)
+		}
+	}
+	all := []yapi.InterfaceData{}
+	t := &table{header: []string{"ID", "CATID", "METHOD", "PATH", "TITLE", "STATUS"}}
+	for _, id := range catIDs {
+		list, err := client.Interface.GetCatAll(id)
+		if err != nil {
+			return err
+		}
+		for _, d := range list {
+			t.add(d.ID, d.CatID, d.Method, d.Path, d.Title, d.Status)
+		}
+		all = append(all, list...)
+	}
+	return c.print(all, t)
+}
+
+func ifaceGet(c *cli, args []string) error {
+	args, client, err := c.setup(c.flags("iface get"), args, 1)
+	if err != nil {
+		return err
+	}
+	id, err := strconv.Atoi(args[0])
+	if err != nil {
+		return errUsage
+	}
+	resp, err := client.Interface.Get(id)
+	if err != nil {
+		return err
+	}
+	if err := yapi.CheckErrCode(resp.ErrCode, resp.ErrMsg); err != nil {
+		return err
+	}
+	d := resp.Data
+	t := &table{}
+	t.add("id", d.ID)
+	t.add("catid", d.CatID)
+	t.add("method", d.Method)
+	t.add("path", d.Path)
+	t.add("title", d.Title)
+	t.add("status", d.Status)
+	t.add("tag", strings.Join(d.Tag, ","))
+	t.add("req_body_type", d.ReqBodyType)
+	t.add("res_body_type", d.ResBodyType)
+	return c.print(d, t)
+}
+
+func ifaceSave(c *cli, args []string) error {
+	fs := c.flags("iface save")
+	catID := fs.Int("cat", 0, "category ID, overrides the catid of the file")
+	args, client, err := c.setup(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	data, err := readInput(args[0])
+	if err != nil {
+		return err
+	}
+	var d yapi.InterfaceData
+	// JSON is valid YAML, so both are read the same way
+	if err := jsonyaml.Unmarshal(data, &d); err != nil {
+		return fmt.Errorf("%s: %v", args[0], err)
+	}
+	if *catID != 0 {
+		d.CatID = *catID
+	}
+	if d.CatID == 0 {
+		return fmt.Errorf("%s: no catid, see -cat", args[0])
+	}
+	if d.ProjectID == 0 {
+		project, err := projectOf(client)
+		if err != nil {
+			return err
+		}
+		d.ProjectID = project.ID
+	}
+	resp, err := client.Interface.AddOrUpdate(&d)
+	if err != nil {
+		return err
+	}
+	return yapi.CheckErrCode(resp.ErrCode, resp.ErrMsg)
+}
+
+func ifaceRm(c *cli, args []string) error {
+	args, client, err := c.setup(c.flags("iface rm"), args, 1)
+	if err != nil {
+		return err
+	}
+	id, err := strconv.Atoi(args[0])
+	if err != nil {
+		return errUsage
+	}
+	resp, err := client.Interface.Delete(id)
+	if err != nil {
+		return err
+	}
+	return yapi.CheckErrCode(resp.ErrCode, resp.ErrMsg)
+}
+
+func importData(c *cli, args []string) error {
+	fs := c.flags("import")
+	typ := fs.String("type", "swagger", "data format: swagger or json")
+	merge := fs.String("merge", "merge", "conflict mode: normal, good or merge")
+	args, client, err := c.setup(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	data, err := readInput(args[0])
+	if err != nil {
+		return err
+	}
+	resp, err := client.Interface.Import(*typ, *merge, string(data))
+	if err != nil {
+		return err
+	}
+	if err := yapi.CheckErrCode(resp.ErrCode, resp.ErrMsg); err != nil {
+		return err
+	}
+	fmt.Fprintln(c.stdout, resp.ErrMsg)
+	return nil
+}
+
+func exportData(c *cli, args []string) error {
+	fs := c.flags("export")
+	file := fs.String("file", "", "write to FILE instead of stdout")
+	_, client, err := c.setup(fs, args, 0)
+	if err != nil {
+		return err
+	}
+	s, err := snapshot.Dump(client)
+	if err != nil {
+		return err
+	}
+	return c.writeFile(*file, func() error {
+		return c.print(s.Export(), nil)
+	})
+}
+
+// writeFile runs write with the output redirected to file, unless file is
+// empty, and returns the error of closing the file.
+func (c *cli) writeFile(file string, write func() error) error {
+	if file == "" {
+		return write()
+	}
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	stdout := c.stdout
+	c.stdout = f
+	err = write()
+	c.stdout = stdout
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+func dump(c *cli, args []string) error {
+	fs := c.flags("dump")
+	format := fs.String("format", "json", "file format: json or yaml")
+	args, client, err := c.setup(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	s, err := snapshot.Dump(client)
+	if err != nil {
+		return err
+	}
+	if err := s.Write(args[0], snapshot.Format(*format)); err != nil {
+		return err
+	}
+	fmt.Fprintf(c.stdout, "wrote %d categories, %d interfaces to %s\n", len(s.Categories), len(s.Interfaces()), args[0])
+	return nil
+}
+
+func apply(c *cli, args []string) error {
+	fs := c.flags("apply")
+	prune := fs.Bool("prune", false, "delete remote interfaces missing from the snapshot")
+	yes := fs.Bool("yes", false, "execute a plan that deletes interfaces")
+	dryRun := fs.Bool("dry-run", false, "only print the plan")
+	args, client, err := c.setup(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	plan, err := snapshot.PlanApply(client, args[0], &snapshot.ApplyOptions{Prune: *prune})
+	if err != nil {
+		return err
+	}
+	if err := plan.WriteText(c.stdout); err != nil {
+		return err
+	}
+	if *dryRun || plan.Empty() {
+		return nil
+	}
+	if plan.Deletes > 0 && !*yes {
+		return fmt.Errorf("the plan deletes %d interfaces, run again with -yes to apply it", plan.Deletes)
+	}
+	return plan.Execute(client)
+}
+
+func diffCmd(c *cli, args []string) error {
+	fs := c.flags("diff")
+	exitCode := fs.Bool("exit-code", false, "exit with status 1 when there are differences")
+	if err := c.parse(fs, args); err != nil {
+		return err
+	}
+	var old, new []yapi.InterfaceData
+	var err error
+	switch fs.NArg() {
+	case 1:
+		client, err := c.client()
+		if err != nil {
+			return err
+		}
+		s, err := snapshot.Dump(client)
+		if err != nil {
+			return err
+		}
+		old = s.Interfaces()
+	case 2:
+		if old, err = snapshot.LoadInterfaces(fs.Arg(0)); err != nil {
+			return err
+		}
+	default:
+		return errUsage
+	}
+	if new, err = snapshot.LoadInterfaces(fs.Arg(fs.NArg() - 1)); err != nil {
+		return err
+	}
+
+	report := diff.Compare(old, new)
+	switch c.output {
+	case "table":
+		err = report.WriteText(c.stdout)
+	default:
+		err = c.print(report, nil)
+	}
+	if err == nil && *exitCode && !report.Empty() {
+		return exitError(1)
+	}
+	return err
+}
+
+func markdown(c *cli, args []string) error {
+	fs := c.flags("markdown")
+	file := fs.String("file", "", "write to FILE instead of stdout")
+	var templates stringList
+	fs.Var(&templates, "template", "template file overriding the default templates, may be repeated")
+	if err := c.parse(fs, args); err != nil {
+		return err
+	}
+	s, err := c.snapshot(fs.Args())
+	if err != nil {
+		return err
+	}
+	opts := &docgen.MarkdownOptions{}
+	if opts.Templates, err = readFiles(templates); err != nil {
+		return err
+	}
+	m, err := docgen.NewMarkdown(opts)
+	if err != nil {
+		return err
+	}
+	return c.writeFile(*file, func() error {
+		return m.Render(c.stdout, docgen.NewDocument(s))
+	})
+}
+
+func htmlCmd(c *cli, args []string) error {
+	fs := c.flags("html")
+	multiPage := fs.Bool("multi-page", false, "write a page per interface")
+	var templates stringList
+	fs.Var(&templates, "template", "template file overriding the default templates, may be repeated")
+	if err := c.parse(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return errUsage
+	}
+	s, err := c.snapshot(fs.Args()[1:])
+	if err != nil {
+		return err
+	}
+	opts := &docgen.HTMLOptions{MultiPage: *multiPage}
+	if opts.Templates, err = readFiles(templates); err != nil {
+		return err
+	}
+	h, err := docgen.NewHTML(opts)
+	if err != nil {
+		return err
+	}
+	if err := h.Write(fs.Arg(0), docgen.NewDocument(s)); err != nil {
+		return err
+	}
+	fmt.Fprintf(c.stdout, "wrote %d interfaces to %s\n", len(s.Interfaces()), fs.Arg(0))
+	return nil
+}
+
+func search(c *cli, args []string) error {
+	fs := c.flags("search")
+	limit := fs.Int("limit", 20, "largest number of results, 0 for all")
+	if err := c.parse(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return errUsage
+	}
+	ix := yapi.NewSearchIndex()
+	dirs := fs.Args()[1:]
+	if len(dirs) == 0 {
+		s, err := c.snapshot(nil)
+		if err != nil {
+			return err
+		}
+		s.Index(ix)
+	}
+	for _, dir := range dirs {
+		s, err := snapshot.Load(dir)
+		if err != nil {
+			return err
+		}
+		s.Index(ix)
+	}
+	hits := ix.Search(fs.Arg(0), *limit)
+	t := &table{header: []string{"SCORE", "PROJECT", "CATEGORY", "METHOD", "PATH", "TITLE"}}
+	for _, h := range hits {
+		t.add(h.Score, h.ProjectName, h.CatName, h.Method, h.Path, h.Title)
+	}
+	return c.print(hits, t)
+}
+
+// snapshot loads the snapshot directory given as the only argument, or dumps
+// the project without arguments.
+func (c *cli) snapshot(args []string) (*snapshot.Snapshot, error) {
+	switch len(args) {
+	case 0:
+		client, err := c.client()
+		if err != nil {
+			return nil, err
+		}
+		return snapshot.Dump(client)
+	case 1:
+		return snapshot.Load(args[0])
+	}
+	return nil, errUsage
+}
+
+func readFiles(paths []string) ([]string, error) {
+	var contents []string
+	for _, path := range paths {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		contents = append(contents, string(data))
+	}
+	return contents, nil
+}
+
+// stringList is a repeatable string flag.
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(v string) error {
+	*l = append(*l, v)
+	return nil
+}
+
+func readInput(path string) ([]byte, error) {
+	if path == "-" {
+		return ioutil.ReadAll(os.Stdin)
+	}
+	return ioutil.ReadFile(path)
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	yapi "github.com/micrease/go-yapi"
+)
+
+// cli holds the global flags and the resolved settings of a run.
+type cli struct {
+	stdout, stderr io.Writer
+
+	configPath string
+	profile    string
+	project    string
+	baseURL    string
+	token      string
+	output     string
+
+	resolved *yapi.Profile
+}
+
+// flags returns the flag set of a command with the global flags defined.
+func (c *cli) flags(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet("yapi "+name, flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	fs.StringVar(&c.configPath, "config", "", "config file (default $YAPI_CONFIG or ~/.yapi.yaml)")
+	fs.StringVar(&c.profile, "profile", "", "config profile (default $YAPI_PROFILE or the default profile)")
+	fs.StringVar(&c.project, "project", "", "use the token of this project of the profile")
+	fs.StringVar(&c.baseURL, "url", "", "YApi base URL, overrides the profile")
+	fs.StringVar(&c.token, "token", "", "project token, overrides the profile")
+	fs.StringVar(&c.output, "o", "", "output format: table, json or yaml (default $YAPI_OUTPUT or table)")
+	return fs
+}
+
+// parse parses the flags and resolves the profile from the config file and
+// the environment.
+func (c *cli) parse(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	c.output = first(c.output, os.Getenv("YAPI_OUTPUT"), "table")
+	switch c.output {
+	case "table", "json", "yaml":
+	default:
+		return fmt.Errorf("unknown output format %q", c.output)
+	}
+
+	var conf *yapi.Config
+	var err error
+	if c.configPath != "" {
+		conf, err = yapi.LoadConfig(c.configPath)
+	} else {
+		conf, err = yapi.LoadDefaultConfig()
+	}
+	if err != nil {
+		return err
+	}
+	if c.resolved, err = conf.GetProfile(c.profile); err != nil {
+		return err
+	}
+	c.resolved.BaseURL = first(c.baseURL, c.resolved.BaseURL)
+	if c.token != "" {
+		c.resolved.Token, c.project = c.token, ""
+	}
+	return nil
+}
+
+// client returns a client for the resolved profile.
+func (c *cli) client() (*yapi.Client, error) {
+	if c.resolved.BaseURL == "" || (c.resolved.Token == "" && c.project == "") {
+		return nil, fmt.Errorf("the base URL and token are required, see -url and -token or the config file")
+	}
+	return yapi.NewClientFromProfile(c.resolved, c.project)
+}
+
+func first(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
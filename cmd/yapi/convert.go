@@ -0,0 +1,212 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	yapi "github.com/micrease/go-yapi"
+	"github.com/micrease/go-yapi/har"
+	"github.com/micrease/go-yapi/postman"
+)
+
+func postmanImport(c *cli, args []string) error {
+	fs := c.flags("postman import")
+	save := fs.Bool("save", false, "save the interfaces one by one instead of using import_data")
+	merge := fs.String("merge", "merge", "import_data conflict mode: normal, good or merge")
+	basepath := fs.String("basepath", "", "prefix removed from the request paths (default the project base path)")
+	category := fs.String("category", "", "category of the requests outside folders (default the collection name)")
+	args, client, err := c.setup(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	data, err := readInput(args[0])
+	if err != nil {
+		return err
+	}
+	collection := new(postman.Collection)
+	if err := json.Unmarshal(data, collection); err != nil {
+		return err
+	}
+	if *basepath == "" {
+		project, err := projectOf(client)
+		if err != nil {
+			return err
+		}
+		*basepath = project.Basepath
+	}
+	export := postman.Import(collection, &postman.ImportOptions{Basepath: *basepath, Category: *category})
+	if err := postman.Push(client, export, &postman.PushOptions{Save: *save, Merge: *merge}); err != nil {
+		return err
+	}
+	fmt.Fprintf(c.stdout, "imported %d categories, %d interfaces\n", len(export), len(export.Interfaces()))
+	return nil
+}
+
+func postmanExport(c *cli, args []string) error {
+	fs := c.flags("postman export")
+	file := fs.String("file", "", "write to FILE instead of stdout")
+	envDir := fs.String("env-dir", "", "also write the project environments to DIR")
+	if err := c.parse(fs, args); err != nil {
+		return err
+	}
+	s, err := c.snapshot(fs.Args())
+	if err != nil {
+		return err
+	}
+	if *envDir != "" {
+		if err := os.MkdirAll(*envDir, 0755); err != nil {
+			return err
+		}
+		for _, env := range postman.Environments(s.Project) {
+			data, err := json.MarshalIndent(env, "", "  ")
+			if err != nil {
+				return err
+			}
+			name := filepath.Join(*envDir, fileName(env.Name)+".postman_environment.json")
+			if err := ioutil.WriteFile(name, append(data, '\n'), 0644); err != nil {
+				return err
+			}
+		}
+	}
+	c.output = "json"
+	return c.writeFile(*file, func() error {
+		return c.print(postman.Export(s), nil)
+	})
+}
+
+func harImport(c *cli, args []string) error {
+	fs := c.flags("har import")
+	catID := fs.Int("cat", 0, "category of the interfaces")
+	var hosts stringList
+	fs.Var(&hosts, "host", "only import the requests sent to this host, may be repeated")
+	basepath := fs.String("basepath", "", "prefix removed from the request paths (default the project base path)")
+	dryRun := fs.Bool("dry-run", false, "print the interfaces instead of saving them")
+	args, client, err := c.setup(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	if *catID == 0 && !*dryRun {
+		return fmt.Errorf("-cat is required")
+	}
+	data, err := readInput(args[0])
+	if err != nil {
+		return err
+	}
+	recording, err := har.Read(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	project, err := projectOf(client)
+	if err != nil {
+		return err
+	}
+	list := har.Interfaces(recording, &har.Options{
+		Hosts:     hosts,
+		Basepath:  first(*basepath, project.Basepath),
+		ProjectID: project.ID,
+		CatID:     *catID,
+	})
+	if *dryRun {
+		t := &table{header: []string{"METHOD", "PATH", "QUERY", "REQUEST", "RESPONSE"}}
+		for _, d := range list {
+			t.add(d.Method, d.Path, len(d.ReqQuery), d.ReqBodyType, d.ResBodyType)
+		}
+		return c.print(list, t)
+	}
+	for i := range list {
+		resp, err := client.Interface.AddOrUpdate(&list[i])
+		if err == nil {
+			err = yapi.CheckErrCode(resp.ErrCode, resp.ErrMsg)
+		}
+		if err != nil {
+			return fmt.Errorf("save %s %s: %v", list[i].Method, list[i].Path, err)
+		}
+	}
+	fmt.Fprintf(c.stdout, "saved %d interfaces\n", len(list))
+	return nil
+}
+
+// inferFlags defines the schema inference flags.
+func inferFlags(fs *flag.FlagSet) *yapi.InferOptions {
+	opts := yapi.DefaultInferOptions
+	fs.IntVar(&opts.EnumMax, "enum-max", opts.EnumMax, "largest number of distinct values listed as an enum, 0 for no enums")
+	fs.IntVar(&opts.EnumMinSamples, "enum-min", opts.EnumMinSamples, "number of values needed for an enum")
+	fs.BoolVar(&opts.NoFormats, "no-formats", false, "do not detect string formats")
+	return &opts
+}
+
+func schemaInfer(c *cli, args []string) error {
+	fs := c.flags("schema infer")
+	opts := inferFlags(fs)
+	if err := c.parse(fs, args); err != nil {
+		return err
+	}
+	files := fs.Args()
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+	in := yapi.NewSchemaInferrer(opts)
+	for _, path := range files {
+		data, err := readInput(path)
+		if err != nil {
+			return err
+		}
+		if err := in.AddJSON(data); err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+	}
+	s := in.Schema()
+	s.Schema = yapi.DraftSchema
+	_, err := fmt.Fprintln(c.stdout, s.String())
+	return err
+}
+
+func schemaUpgrade(c *cli, args []string) error {
+	fs := c.flags("schema upgrade")
+	opts := &yapi.UpgradeOptions{Infer: inferFlags(fs)}
+	fs.BoolVar(&opts.Request, "request", false, "also convert json request bodies")
+	fs.BoolVar(&opts.DryRun, "dry-run", false, "only list the interfaces to convert")
+	_, client, err := c.setup(fs, args, 0)
+	if err != nil {
+		return err
+	}
+	project, err := projectOf(client)
+	if err != nil {
+		return err
+	}
+	res, err := client.Interface.UpgradeToSchema(project.ID, opts)
+	if res != nil {
+		t := &table{header: []string{"ID", "METHOD", "PATH", "RESULT"}}
+		for _, d := range res.Upgraded {
+			t.add(d.ID, d.Method, d.Path, "upgraded")
+		}
+		for _, skip := range res.Skipped {
+			t.add(skip.ID, skip.Method, skip.Path, "skipped: "+skip.Reason)
+		}
+		if perr := c.print(res, t); err == nil {
+			err = perr
+		}
+	}
+	return err
+}
+
+// fileName makes a name safe to use as a file name.
+func fileName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '\\', ':':
+			return '-'
+		}
+		return r
+	}, name)
+	if name == "" || name == "." || name == ".." {
+		return "environment"
+	}
+	return name
+}
@@ -0,0 +1,136 @@
+// Command yapi is a command-line client for the YApi open API.
+//
+//	yapi project get
+//	yapi cat list | add NAME | rm ID
+//	yapi iface list | get ID | save FILE | rm ID
+//	yapi import FILE
+//	yapi export
+//	yapi dump DIR
+//	yapi apply DIR
+//	yapi diff [OLD] NEW
+//	yapi markdown [DIR]
+//	yapi html OUT [DIR]
+//	yapi search QUERY [DIR...]
+//	yapi postman import FILE | export [DIR]
+//	yapi har import FILE
+//	yapi schema infer [FILE...] | upgrade
+//
+// The base URL and project token come from a profile of the config file,
+// see yapi.Config, overridden by the YAPI_* environment variables and the
+// -url and -token flags. Command flags must precede the positional arguments.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+type command struct {
+	name  string
+	args  string
+	short string
+	run   func(c *cli, args []string) error
+}
+
+var commands = []command{
+	{"project get", "", "show the project of the token", projectGet},
+	{"cat list", "", "list the categories", catList},
+	{"cat add", "[-desc DESC] NAME", "add a category", catAdd},
+	{"cat rm", "ID", "remove a category and its interfaces", catRm},
+	{"iface list", "[-cat ID]", "list the interfaces, of one category with -cat", ifaceList},
+	{"iface get", "ID", "show an interface", ifaceGet},
+	{"iface save", "[-cat ID] FILE", "create or update an interface from a JSON or YAML file, - for stdin", ifaceSave},
+	{"iface rm", "ID", "remove an interface", ifaceRm},
+	{"import", "[-type swagger|json] [-merge normal|good|merge] FILE", "import a swagger or YApi json export file", importData},
+	{"export", "[-file FILE]", "export the project in the YApi json export format", exportData},
+	{"dump", "[-format json|yaml] DIR", "write a snapshot of the project to DIR", dump},
+	{"apply", "[-prune [-yes]] [-dry-run] DIR", "push the snapshot in DIR to the project", apply},
+	{"diff", "[-exit-code] [OLD] NEW", "compare the project, or OLD, with NEW; each a snapshot directory or export file", diffCmd},
+	{"markdown", "[-template FILE] [-file FILE] [DIR]", "render the project, or the snapshot in DIR, as Markdown", markdown},
+	{"search", "[-limit N] QUERY [DIR...]", "search the interfaces of the project, or of the snapshots in DIR...", search},
+	{"html", "[-multi-page] [-template FILE] OUT [DIR]", "write a static HTML site of the project, or the snapshot in DIR, to OUT", htmlCmd},
+	{"postman import", "[-save] [-merge normal|good|merge] [-basepath PATH] [-category NAME] FILE", "import a Postman v2.1 collection", postmanImport},
+	{"postman export", "[-file FILE] [-env-dir DIR] [DIR]", "export the project, or the snapshot in DIR, as a Postman v2.1 collection", postmanExport},
+	{"har import", "[-cat ID] [-host HOST] [-basepath PATH] [-dry-run] FILE", "create or update interfaces from a HAR recording", harImport},
+	{"schema infer", "[-enum-max N] [-enum-min N] [-no-formats] [FILE...]", "infer a JSON Schema from example JSON documents, stdin without FILE", schemaInfer},
+	{"schema upgrade", "[-request] [-dry-run] [-enum-max N] [-enum-min N] [-no-formats]", "convert the JSON example bodies of the project to JSON Schemas", schemaUpgrade},
+}
+
+// errUsage is returned by commands called with wrong arguments.
+var errUsage = errors.New("usage")
+
+// exitError makes run exit with a status other than 1 without printing.
+type exitError int
+
+func (e exitError) Error() string {
+	return fmt.Sprintf("exit status %d", int(e))
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	c := &cli{stdout: stdout, stderr: stderr}
+	if len(args) == 0 || args[0] == "-h" || args[0] == "-help" || args[0] == "help" {
+		usage(stderr)
+		return 2
+	}
+	cmd, rest := findCommand(args)
+	if cmd == nil {
+		fmt.Fprintf(stderr, "yapi: unknown command %q\n", strings.Join(args, " "))
+		usage(stderr)
+		return 2
+	}
+	err := cmd.run(c, rest)
+	var exit exitError
+	switch {
+	case err == nil:
+		return 0
+	case errors.As(err, &exit):
+		return int(exit)
+	case err == flag.ErrHelp:
+		return 2
+	case err == errUsage:
+		fmt.Fprintf(stderr, "usage: yapi %s %s\n", cmd.name, cmd.args)
+		return 2
+	}
+	fmt.Fprintf(stderr, "yapi %s: %v\n", cmd.name, err)
+	return 1
+}
+
+// findCommand matches the longest command name at the start of args.
+func findCommand(args []string) (*command, []string) {
+	var found *command
+	var n int
+	for i := range commands {
+		words := strings.Fields(commands[i].name)
+		if len(words) > n && len(args) >= len(words) && strings.Join(args[:len(words)], " ") == commands[i].name {
+			found, n = &commands[i], len(words)
+		}
+	}
+	if found == nil {
+		return nil, nil
+	}
+	return found, args[n:]
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "usage: yapi <command> [flags] [args]")
+	fmt.Fprintln(w, "\ncommands:")
+	names := make([]string, len(commands))
+	for i, cmd := range commands {
+		names[i] = cmd.name
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		cmd, _ := findCommand(strings.Fields(name))
+		fmt.Fprintf(w, "  %-15s %s\n", cmd.name, cmd.short)
+	}
+	fmt.Fprintln(w, "\nevery command accepts -url, -token, -config and -o table|json|yaml")
+}
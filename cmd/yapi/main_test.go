@@ -0,0 +1,292 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	yapi "github.com/micrease/go-yapi"
+	"github.com/micrease/go-yapi/snapshot"
+	"github.com/micrease/go-yapi/yapitest"
+)
+
+func fakeYApi(t *testing.T, saved *yapi.InterfaceData) *httptest.Server {
+	reply := func(w http.ResponseWriter, data interface{}) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"errcode": 0, "errmsg": "成功！", "data": data})
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/project/get", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("token") != "t0ken" {
+			json.NewEncoder(w).Encode(map[string]interface{}{"errcode": 40011, "errmsg": "请登录..."})
+			return
+		}
+		reply(w, yapi.ProjectData{ID: 11, Name: "demo", Basepath: "/api"})
+	})
+	mux.HandleFunc("/api/interface/getCatMenu", func(w http.ResponseWriter, r *http.Request) {
+		reply(w, yapi.CatMenuData{{ID: 1, Name: "users", Desc: "user apis"}})
+	})
+	mux.HandleFunc("/api/interface/save", func(w http.ResponseWriter, r *http.Request) {
+		data, _ := ioutil.ReadAll(r.Body)
+		json.Unmarshal(data, saved)
+		reply(w, []interface{}{})
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func runCLI(args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := run(args, &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestRun_Config(t *testing.T) {
+	var saved yapi.InterfaceData
+	server := fakeYApi(t, &saved)
+	dir, err := ioutil.TempDir("", "yapi-cli")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	configPath := filepath.Join(dir, "config.yaml")
+	ioutil.WriteFile(configPath, []byte("profiles:\n  test:\n    base_url: "+server.URL+"\n    token: wrong\n    tokens:\n      demo: t0ken\n"), 0644)
+	os.Setenv("YAPI_CONFIG", configPath)
+	defer os.Unsetenv("YAPI_CONFIG")
+	os.Setenv("YAPI_OUTPUT", "json")
+	defer os.Unsetenv("YAPI_OUTPUT")
+
+	// the config file token is rejected by the server
+	if code, _, stderr := runCLI("project", "get"); code != 1 || !strings.Contains(stderr, "errcode 40011") {
+		t.Errorf("project get with the config token = %d, %q", code, stderr)
+	}
+	if code, stdout, _ := runCLI("project", "get", "-project", "demo"); code != 0 || !strings.Contains(stdout, `"name": "demo"`) {
+		t.Errorf("project get -project demo = %d, %q", code, stdout)
+	}
+	// the environment overrides the config file and the flag overrides both
+	os.Setenv("YAPI_TOKEN", "t0ken")
+	defer os.Unsetenv("YAPI_TOKEN")
+	code, stdout, stderr := runCLI("cat", "list")
+	if code != 0 || !strings.Contains(stdout, `"name": "users"`) {
+		t.Errorf("cat list = %d, %q, %q", code, stdout, stderr)
+	}
+	if code, _, _ := runCLI("cat", "list", "-token", "flag"); code != 1 {
+		t.Errorf("cat list -token flag = %d, want the flag token to be used", code)
+	}
+
+	code, stdout, _ = runCLI("project", "get", "-o", "table")
+	if code != 0 || !strings.Contains(stdout, "basepath  /api") {
+		t.Errorf("project get -o table = %d\n%s", code, stdout)
+	}
+	code, stdout, _ = runCLI("cat", "list", "-o", "yaml")
+	if code != 0 || !strings.Contains(stdout, "- _id: 1\n  uid: 0\n  name: users\n") {
+		t.Errorf("cat list -o yaml = %d\n%s", code, stdout)
+	}
+
+	ifacePath := filepath.Join(dir, "iface.yaml")
+	ioutil.WriteFile(ifacePath, []byte("method: GET\npath: /users\ntitle: list users\n"), 0644)
+	if code, _, stderr := runCLI("iface", "save", "-cat", "1", ifacePath); code != 0 {
+		t.Fatalf("iface save = %d, %s", code, stderr)
+	}
+	if saved.Path != "/users" || saved.CatID != 1 || saved.ProjectID != 11 {
+		t.Errorf("saved interface = %+v", saved)
+	}
+}
+
+func TestRun_Diff(t *testing.T) {
+	dir, err := ioutil.TempDir("", "yapi-cli")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	oldPath, newPath := filepath.Join(dir, "old.json"), filepath.Join(dir, "new.json")
+	ioutil.WriteFile(oldPath, []byte(`[{"name":"users","list":[{"_id":1,"method":"GET","path":"/users","title":"list"}]}]`), 0644)
+	ioutil.WriteFile(newPath, []byte(`[{"name":"users","list":[{"_id":1,"method":"GET","path":"/users","title":"list users"}]}]`), 0644)
+
+	code, stdout, _ := runCLI("diff", "-exit-code", oldPath, newPath)
+	if code != 1 || !strings.Contains(stdout, `~ title: "list" -> "list users"`) {
+		t.Errorf("diff = %d\n%s", code, stdout)
+	}
+	if code, _, _ := runCLI("diff", "-exit-code", oldPath, oldPath); code != 0 {
+		t.Errorf("diff of identical files = %d", code)
+	}
+}
+
+func TestRun_Usage(t *testing.T) {
+	if code, _, stderr := runCLI("cat", "fly"); code != 2 || !strings.Contains(stderr, `unknown command "cat fly"`) {
+		t.Errorf("unknown command = %d, %q", code, stderr)
+	}
+	if code, _, stderr := runCLI("iface", "get", "-url", "http://localhost", "-token", "x"); code != 2 || !strings.Contains(stderr, "usage: yapi iface get ID") {
+		t.Errorf("missing argument = %d, %q", code, stderr)
+	}
+}
+
+func TestFindCommand(t *testing.T) {
+	defer func(saved []command) { commands = saved }(commands)
+	commands = []command{{name: "cat"}, {name: "cat list"}}
+	if cmd, rest := findCommand([]string{"cat", "list", "-o", "json"}); cmd == nil || cmd.name != "cat list" || len(rest) != 2 {
+		t.Errorf("findCommand = %+v, %v; want the longest name", cmd, rest)
+	}
+	if cmd, rest := findCommand([]string{"cat", "rm"}); cmd == nil || cmd.name != "cat" || len(rest) != 1 {
+		t.Errorf("findCommand = %+v, %v", cmd, rest)
+	}
+}
+
+func TestRun_ApplyPrune(t *testing.T) {
+	server := yapitest.NewServer()
+	defer server.Close()
+	token := server.AddProject(yapi.ProjectData{Name: "demo"})
+	client := server.Client(token)
+	project, _ := client.Project.Get()
+	catID := server.AddCategory(project.Data.ID, yapi.CatData{Name: "users"})
+	var d yapi.InterfaceData
+	d.ProjectID, d.CatID, d.Method, d.Path, d.Title = project.Data.ID, catID, "GET", "/users", "list users"
+	server.AddInterface(d)
+
+	dir, err := ioutil.TempDir("", "yapi-cli")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	s := &snapshot.Snapshot{Project: project.Data, Categories: []snapshot.Category{{CatData: yapi.CatData{Name: "users"}}}}
+	if err := s.Write(dir, snapshot.FormatJSON); err != nil {
+		t.Fatal(err)
+	}
+
+	flags := []string{"apply", "-url", server.URL, "-token", token, "-prune"}
+	code, stdout, stderr := runCLI(append(flags, dir)...)
+	if code != 1 || !strings.Contains(stdout, "1 to delete") || !strings.Contains(stderr, "-yes") {
+		t.Errorf("apply -prune = %d, %q, %q", code, stdout, stderr)
+	}
+	if n := len(server.Interfaces(project.Data.ID)); n != 1 {
+		t.Fatalf("apply -prune without -yes left %d interfaces", n)
+	}
+	if code, _, stderr := runCLI(append(flags, "-yes", dir)...); code != 0 {
+		t.Errorf("apply -prune -yes = %d, %q", code, stderr)
+	}
+	if n := len(server.Interfaces(project.Data.ID)); n != 0 {
+		t.Errorf("apply -prune -yes left %d interfaces", n)
+	}
+}
+
+func TestRun_Docs(t *testing.T) {
+	dir, err := ioutil.TempDir("", "yapi-cli")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	var iface yapi.InterfaceData
+	iface.Method, iface.Path, iface.Title = "GET", "/users", "list users"
+	s := &snapshot.Snapshot{
+		Project:    yapi.ProjectData{Name: "demo"},
+		Categories: []snapshot.Category{{CatData: yapi.CatData{Name: "users"}, Interfaces: []yapi.InterfaceData{iface}}},
+	}
+	if err := s.Write(filepath.Join(dir, "snap"), snapshot.FormatJSON); err != nil {
+		t.Fatal(err)
+	}
+	tmplPath := filepath.Join(dir, "toc.tmpl")
+	ioutil.WriteFile(tmplPath, []byte(`{{define "toc"}}{{range .Interfaces}}- {{.Title}}{{end}}{{end}}`), 0644)
+
+	code, stdout, stderr := runCLI("markdown", filepath.Join(dir, "snap"))
+	if code != 0 || !strings.Contains(stdout, "| [list users](#get-users) | GET | `/users` |") {
+		t.Errorf("markdown = %d, %s\n%s", code, stderr, stdout)
+	}
+	code, stdout, stderr = runCLI("markdown", "-template", tmplPath, filepath.Join(dir, "snap"))
+	if code != 0 || !strings.Contains(stdout, "\n- list users\n") {
+		t.Errorf("markdown -template = %d, %s\n%s", code, stderr, stdout)
+	}
+	code, stdout, stderr = runCLI("html", "-multi-page", filepath.Join(dir, "site"), filepath.Join(dir, "snap"))
+	if code != 0 || stdout != "wrote 1 interfaces to "+filepath.Join(dir, "site")+"\n" {
+		t.Errorf("html = %d, %s\n%s", code, stderr, stdout)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "site", "get-users.html")); err != nil {
+		t.Error(err)
+	}
+	code, stdout, stderr = runCLI("search", "users", filepath.Join(dir, "snap"))
+	if code != 0 || !strings.Contains(stdout, "demo") || !strings.Contains(stdout, "/users") {
+		t.Errorf("search = %d, %s\n%s", code, stderr, stdout)
+	}
+}
+
+func TestRun_PostmanExport(t *testing.T) {
+	dir, err := ioutil.TempDir("", "yapi-cli")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	var iface yapi.InterfaceData
+	iface.Method, iface.Path, iface.Title = "GET", "/users", "list users"
+	s := &snapshot.Snapshot{
+		Project:    yapi.ProjectData{Name: "demo", Basepath: "/api", Env: []yapi.ProjectEnv{{Name: "dev/local", Domain: "http://localhost"}}},
+		Categories: []snapshot.Category{{CatData: yapi.CatData{Name: "users"}, Interfaces: []yapi.InterfaceData{iface}}},
+	}
+	if err := s.Write(filepath.Join(dir, "snap"), snapshot.FormatJSON); err != nil {
+		t.Fatal(err)
+	}
+
+	code, stdout, stderr := runCLI("postman", "export", "-o", "yaml", "-env-dir", filepath.Join(dir, "env"), filepath.Join(dir, "snap"))
+	if code != 0 || !strings.Contains(stdout, `"raw": "{{baseUrl}}/api/users"`) {
+		t.Errorf("postman export = %d, %s\n%s", code, stderr, stdout)
+	}
+	env, err := ioutil.ReadFile(filepath.Join(dir, "env", "dev-local.postman_environment.json"))
+	if err != nil || !strings.Contains(string(env), `"value": "http://localhost"`) {
+		t.Errorf("environment file = %s, %v", env, err)
+	}
+}
+
+func TestRun_HarImport(t *testing.T) {
+	var saved yapi.InterfaceData
+	server := fakeYApi(t, &saved)
+	dir, err := ioutil.TempDir("", "yapi-cli")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	harPath := filepath.Join(dir, "shop.har")
+	ioutil.WriteFile(harPath, []byte(`{"log": {"entries": [{"request": {"method": "GET", "url": "http://shop/api/users/42"},
+		"response": {"status": 200, "content": {"mimeType": "application/json", "text": "{\"id\": 42}"}}}]}}`), 0644)
+
+	flags := []string{"-url", server.URL, "-token", "t0ken"}
+	if code, _, stderr := runCLI(append(append([]string{"har", "import"}, flags...), harPath)...); code != 1 || !strings.Contains(stderr, "-cat is required") {
+		t.Errorf("har import without -cat = %d, %q", code, stderr)
+	}
+	code, stdout, stderr := runCLI(append(append([]string{"har", "import"}, flags...), "-cat", "3", harPath)...)
+	if code != 0 || stdout != "saved 1 interfaces\n" {
+		t.Fatalf("har import = %d, %q, %q", code, stdout, stderr)
+	}
+	if saved.Path != "/users/{id}" || saved.CatID != 3 || saved.ProjectID != 11 || !saved.ResBodyIsJsonSchema {
+		t.Errorf("saved interface = %+v", saved)
+	}
+}
+
+func TestRun_SchemaInfer(t *testing.T) {
+	dir, err := ioutil.TempDir("", "yapi-cli")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	a, b := filepath.Join(dir, "a.json"), filepath.Join(dir, "b.json")
+	ioutil.WriteFile(a, []byte(`{"id": 1, "at": "2024-01-02"}`), 0644)
+	ioutil.WriteFile(b, []byte(`{"id": 2}`), 0644)
+
+	code, stdout, stderr := runCLI("schema", "infer", a, b)
+	if code != 0 {
+		t.Fatalf("schema infer = %d, %s", code, stderr)
+	}
+	s, err := yapi.ParseSchema(stdout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Schema != yapi.DraftSchema || s.Properties["at"].Format != "date" || strings.Join(s.Required, ",") != "id" {
+		t.Errorf("schema infer =\n%s", stdout)
+	}
+	if code, _, _ := runCLI("schema", "infer", "-no-formats", a); code != 0 {
+		t.Errorf("schema infer -no-formats = %d", code)
+	}
+}
@@ -1,5 +1,10 @@
 package yapi
 
+import (
+	"encoding/json"
+	"fmt"
+)
+
 /**
 接口通用返回
 */
@@ -17,9 +22,43 @@ type ModifyResult struct {
 type ModifyResp struct {
 	CommonResp
 	Data   interface{} `json:"data" structs:"data"`
-	string string      `json:"string"`
+	string string
 }
 
 func (m *ModifyResp) ToString() string {
 	return m.string
 }
+
+// CreatedID returns the _id of the document in the data of an add api
+// response, e.g. the category returned by CatMenuService.AddOrUpdate.
+func CreatedID(data interface{}) (int, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return 0, err
+	}
+	var doc struct {
+		ID int `json:"_id"`
+	}
+	if err := json.Unmarshal(raw, &doc); err != nil || doc.ID == 0 {
+		return 0, fmt.Errorf("no _id in response data %s", raw)
+	}
+	return doc.ID, nil
+}
+
+// ResponseError reports a YApi response whose errcode is not 0.
+type ResponseError struct {
+	ErrCode int
+	ErrMsg  string
+}
+
+func (e *ResponseError) Error() string {
+	return fmt.Sprintf("yapi errcode %d: %s", e.ErrCode, e.ErrMsg)
+}
+
+// CheckErrCode returns a *ResponseError if errCode is not 0.
+func CheckErrCode(errCode int, errMsg string) error {
+	if errCode == 0 {
+		return nil
+	}
+	return &ResponseError{ErrCode: errCode, ErrMsg: errMsg}
+}
@@ -0,0 +1,300 @@
+package yapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Auth modes of a Profile.
+const (
+	// AuthModeToken only sends the project token, the YApi open API default.
+	AuthModeToken = "token"
+	// AuthModeBasic also sends HTTP basic credentials, for instances behind
+	// an authenticating proxy.
+	AuthModeBasic = "basic"
+)
+
+// Profile holds the settings to connect to a YApi instance.
+type Profile struct {
+	// Name is the key of the profile in the config file.
+	Name    string `json:"-" yaml:"-" toml:"-"`
+	BaseURL string `json:"base_url" yaml:"base_url" toml:"base_url"`
+	// Token is the default project token.
+	Token string `json:"token" yaml:"token" toml:"token"`
+	// Tokens holds the tokens of further projects, by a name of your choice.
+	Tokens   map[string]string `json:"tokens" yaml:"tokens" toml:"tokens"`
+	AuthMode string            `json:"auth_mode" yaml:"auth_mode" toml:"auth_mode"`
+	Username string            `json:"username" yaml:"username" toml:"username"`
+	Password string            `json:"password" yaml:"password" toml:"password"`
+	// Timeout is a duration such as "30s"; requests do not time out when empty.
+	Timeout string `json:"timeout" yaml:"timeout" toml:"timeout"`
+	// Proxy is the URL of an HTTP proxy; the HTTP_PROXY and HTTPS_PROXY
+	// environment variables are used when empty.
+	Proxy string `json:"proxy" yaml:"proxy" toml:"proxy"`
+}
+
+// Config is the content of a config file. The top-level profile settings
+// apply to every profile that leaves them empty:
+//
+//	timeout: 30s
+//	default_profile: prod
+//	profiles:
+//	  prod:
+//	    base_url: http://yapi.example.com
+//	    token: ...
+//	    tokens:
+//	      orders: ...
+//	  staging:
+//	    base_url: http://yapi-staging.example.com
+type Config struct {
+	Profile        `yaml:",inline"`
+	DefaultProfile string              `json:"default_profile" yaml:"default_profile" toml:"default_profile"`
+	Profiles       map[string]*Profile `json:"profiles" yaml:"profiles" toml:"profiles"`
+}
+
+// ConfigFileNames are the names LoadDefaultConfig looks for in the home directory.
+var ConfigFileNames = []string{".yapi.yaml", ".yapi.yml", ".yapi.toml", ".yapi.json"}
+
+// LoadConfig reads a YAML, TOML or JSON config file, chosen by its extension.
+func LoadConfig(path string) (*Config, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	c := new(Config)
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, c)
+	case ".toml":
+		err = toml.Unmarshal(data, c)
+	case ".json":
+		err = json.Unmarshal(data, c)
+	default:
+		return nil, fmt.Errorf("yapi: unsupported config file type %s", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("yapi: %s: %v", path, err)
+	}
+	return c, nil
+}
+
+// LoadDefaultConfig reads the file named by $YAPI_CONFIG, or the first of
+// ConfigFileNames found in the home directory. It returns an empty config
+// when there is no config file, so profiles can come from the environment alone.
+func LoadDefaultConfig() (*Config, error) {
+	if path := os.Getenv("YAPI_CONFIG"); path != "" {
+		return LoadConfig(path)
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return new(Config), nil
+	}
+	for _, name := range ConfigFileNames {
+		path := filepath.Join(home, name)
+		if _, err := os.Stat(path); err == nil {
+			return LoadConfig(path)
+		}
+	}
+	return new(Config), nil
+}
+
+// GetProfile returns the named profile merged with the top-level settings
+// and the YAPI_* environment variables, which take precedence:
+// YAPI_BASE_URL, YAPI_TOKEN, YAPI_AUTH_MODE, YAPI_USERNAME, YAPI_PASSWORD,
+// YAPI_TIMEOUT, YAPI_PROXY and YAPI_TOKEN_<NAME> for Tokens[<name>].
+//
+// An empty name selects $YAPI_PROFILE, then the default profile of the
+// config. Without profiles the top-level settings form the profile.
+func (c *Config) GetProfile(name string) (*Profile, error) {
+	if name == "" {
+		name = os.Getenv("YAPI_PROFILE")
+	}
+	if name == "" {
+		name = c.DefaultProfile
+	}
+	if name == "" && len(c.Profiles) == 1 {
+		for only := range c.Profiles {
+			name = only
+		}
+	}
+
+	p := c.Profile
+	p.Tokens = copyTokens(c.Tokens)
+	if name != "" {
+		named, ok := c.Profiles[name]
+		if !ok {
+			return nil, fmt.Errorf("yapi: no profile %q in config, have %s", name, strings.Join(c.profileNames(), ", "))
+		}
+		p.merge(named)
+		p.Name = name
+	}
+	p.merge(profileFromEnv())
+	if p.AuthMode == "" {
+		p.AuthMode = AuthModeToken
+	}
+	return &p, p.validate()
+}
+
+func (c *Config) profileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// merge sets the fields that are not empty in o.
+func (p *Profile) merge(o *Profile) {
+	set := func(dst *string, v string) {
+		if v != "" {
+			*dst = v
+		}
+	}
+	set(&p.BaseURL, o.BaseURL)
+	set(&p.Token, o.Token)
+	set(&p.AuthMode, o.AuthMode)
+	set(&p.Username, o.Username)
+	set(&p.Password, o.Password)
+	set(&p.Timeout, o.Timeout)
+	set(&p.Proxy, o.Proxy)
+	for name, token := range o.Tokens {
+		if p.Tokens == nil {
+			p.Tokens = map[string]string{}
+		}
+		p.Tokens[name] = token
+	}
+}
+
+func copyTokens(tokens map[string]string) map[string]string {
+	if tokens == nil {
+		return nil
+	}
+	out := make(map[string]string, len(tokens))
+	for k, v := range tokens {
+		out[k] = v
+	}
+	return out
+}
+
+func profileFromEnv() *Profile {
+	p := &Profile{
+		BaseURL:  os.Getenv("YAPI_BASE_URL"),
+		Token:    os.Getenv("YAPI_TOKEN"),
+		AuthMode: os.Getenv("YAPI_AUTH_MODE"),
+		Username: os.Getenv("YAPI_USERNAME"),
+		Password: os.Getenv("YAPI_PASSWORD"),
+		Timeout:  os.Getenv("YAPI_TIMEOUT"),
+		Proxy:    os.Getenv("YAPI_PROXY"),
+	}
+	for _, kv := range os.Environ() {
+		if !strings.HasPrefix(kv, "YAPI_TOKEN_") {
+			continue
+		}
+		i := strings.Index(kv, "=")
+		if p.Tokens == nil {
+			p.Tokens = map[string]string{}
+		}
+		p.Tokens[strings.ToLower(kv[len("YAPI_TOKEN_"):i])] = kv[i+1:]
+	}
+	return p
+}
+
+func (p *Profile) validate() error {
+	switch p.AuthMode {
+	case AuthModeToken:
+	case AuthModeBasic:
+		if p.Username == "" {
+			return fmt.Errorf("yapi: profile %q uses basic auth without a username", p.Name)
+		}
+	default:
+		return fmt.Errorf("yapi: profile %q has unknown auth mode %q", p.Name, p.AuthMode)
+	}
+	if p.Timeout != "" {
+		if _, err := time.ParseDuration(p.Timeout); err != nil {
+			return fmt.Errorf("yapi: profile %q: timeout: %v", p.Name, err)
+		}
+	}
+	if p.Proxy != "" {
+		if _, err := url.Parse(p.Proxy); err != nil {
+			return fmt.Errorf("yapi: profile %q: proxy: %v", p.Name, err)
+		}
+	}
+	return nil
+}
+
+// ProjectToken returns the token of a project of Tokens, matched case
+// insensitively, or the default token when project is empty.
+func (p *Profile) ProjectToken(project string) (string, error) {
+	if project == "" {
+		if p.Token == "" {
+			return "", fmt.Errorf("yapi: profile %q has no token", p.Name)
+		}
+		return p.Token, nil
+	}
+	for name, token := range p.Tokens {
+		if strings.EqualFold(name, project) {
+			return token, nil
+		}
+	}
+	return "", fmt.Errorf("yapi: profile %q has no token for project %q", p.Name, project)
+}
+
+// HTTPClient returns an http.Client with the timeout and proxy settings of
+// the profile. The basic auth credentials are not part of it: the client of
+// NewClientFromProfile sends them to the profile BaseURL only.
+func (p *Profile) HTTPClient() (*http.Client, error) {
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if p.Proxy != "" {
+		proxy, _ := url.Parse(p.Proxy)
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+	hc := &http.Client{Transport: transport}
+	if p.Timeout != "" {
+		hc.Timeout, _ = time.ParseDuration(p.Timeout)
+	}
+	return hc, nil
+}
+
+// NewClientFromProfile returns a client for a project of the profile, see
+// ProjectToken; an empty project uses the default token.
+func NewClientFromProfile(p *Profile, project string) (*Client, error) {
+	if p.BaseURL == "" {
+		return nil, fmt.Errorf("yapi: profile %q has no base_url", p.Name)
+	}
+	token, err := p.ProjectToken(project)
+	if err != nil {
+		return nil, err
+	}
+	hc, err := p.HTTPClient()
+	if err != nil {
+		return nil, err
+	}
+	c, err := NewClient(p.BaseURL, token)
+	if err != nil {
+		return nil, err
+	}
+	c.SetHTTPClient(hc)
+	if p.AuthMode == AuthModeBasic {
+		c.Authentication.authType = authTypeBasic
+		c.Authentication.username = p.Username
+		c.Authentication.password = p.Password
+	} else {
+		c.Authentication.authType = authTypeToken
+	}
+	return c, nil
+}
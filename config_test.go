@@ -0,0 +1,189 @@
+package yapi
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const testYAMLConfig = `
+timeout: 5s
+default_profile: prod
+profiles:
+  prod:
+    base_url: http://yapi.example.com
+    token: prod-token
+    tokens:
+      Orders: orders-token
+  staging:
+    base_url: http://staging.example.com
+    token: staging-token
+    timeout: 1m
+`
+
+const testTOMLConfig = `
+timeout = "5s"
+default_profile = "prod"
+
+[profiles.prod]
+base_url = "http://yapi.example.com"
+token = "prod-token"
+tokens = { Orders = "orders-token" }
+
+[profiles.staging]
+base_url = "http://staging.example.com"
+token = "staging-token"
+timeout = "1m"
+`
+
+const testJSONConfig = `{
+  "timeout": "5s",
+  "default_profile": "prod",
+  "profiles": {
+    "prod": {"base_url": "http://yapi.example.com", "token": "prod-token", "tokens": {"Orders": "orders-token"}},
+    "staging": {"base_url": "http://staging.example.com", "token": "staging-token", "timeout": "1m"}
+  }
+}`
+
+func writeConfig(t *testing.T, name, content string) string {
+	dir, err := ioutil.TempDir("", "yapi-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	path := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func setenv(t *testing.T, key, value string) {
+	old, ok := os.LookupEnv(key)
+	os.Setenv(key, value)
+	t.Cleanup(func() {
+		if ok {
+			os.Setenv(key, old)
+		} else {
+			os.Unsetenv(key)
+		}
+	})
+}
+
+func TestLoadConfig(t *testing.T) {
+	for name, content := range map[string]string{"config.yaml": testYAMLConfig, "config.toml": testTOMLConfig, "config.json": testJSONConfig} {
+		c, err := LoadConfig(writeConfig(t, name, content))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		prod, err := c.GetProfile("")
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if prod.Name != "prod" || prod.BaseURL != "http://yapi.example.com" || prod.Timeout != "5s" || prod.AuthMode != AuthModeToken {
+			t.Errorf("%s: default profile = %+v", name, prod)
+		}
+		if token, err := prod.ProjectToken("orders"); err != nil || token != "orders-token" {
+			t.Errorf("%s: orders token = %q, %v", name, token, err)
+		}
+		staging, err := c.GetProfile("staging")
+		if err != nil || staging.Token != "staging-token" || staging.Timeout != "1m" {
+			t.Errorf("%s: staging profile = %+v, %v", name, staging, err)
+		}
+		if _, err := c.GetProfile("dev"); err == nil {
+			t.Errorf("%s: unknown profile accepted", name)
+		}
+	}
+}
+
+func TestConfig_Env(t *testing.T) {
+	c, err := LoadConfig(writeConfig(t, "config.yaml", testYAMLConfig))
+	if err != nil {
+		t.Fatal(err)
+	}
+	setenv(t, "YAPI_PROFILE", "staging")
+	setenv(t, "YAPI_TOKEN", "env-token")
+	setenv(t, "YAPI_TOKEN_CARTS", "carts-token")
+	p, err := c.GetProfile("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Name != "staging" || p.Token != "env-token" || p.Tokens["carts"] != "carts-token" {
+		t.Errorf("profile = %+v", p)
+	}
+
+	setenv(t, "YAPI_AUTH_MODE", "oauth")
+	if _, err := c.GetProfile(""); err == nil {
+		t.Error("unknown auth mode accepted")
+	}
+
+	// without a config file the environment alone forms the profile
+	os.Unsetenv("YAPI_AUTH_MODE")
+	os.Unsetenv("YAPI_PROFILE")
+	setenv(t, "YAPI_BASE_URL", "http://env.example.com")
+	if p, err := new(Config).GetProfile(""); err != nil || p.BaseURL != "http://env.example.com" || p.Token != "env-token" {
+		t.Errorf("environment profile = %+v, %v", p, err)
+	}
+}
+
+func TestNewClientFromProfile(t *testing.T) {
+	var user, pass, token string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, _ = r.BasicAuth()
+		token = r.URL.Query().Get("token")
+		if r.URL.Query().Get("slow") != "" {
+			time.Sleep(200 * time.Millisecond)
+		}
+		w.Write([]byte(`{"errcode":0,"errmsg":"成功！","data":{"_id":11,"name":"demo"}}`))
+	}))
+	defer server.Close()
+
+	p := &Profile{
+		Name:     "test",
+		BaseURL:  server.URL,
+		Token:    "default-token",
+		Tokens:   map[string]string{"orders": "orders-token"},
+		AuthMode: AuthModeBasic,
+		Username: "ci",
+		Password: "secret",
+		Timeout:  "50ms",
+	}
+	c, err := NewClientFromProfile(p, "orders")
+	if err != nil {
+		t.Fatal(err)
+	}
+	project, err := c.Project.Get()
+	if err != nil || project.Data.ID != 11 {
+		t.Fatalf("Project.Get = %+v, %v", project, err)
+	}
+	if user != "ci" || pass != "secret" || token != "orders-token" {
+		t.Errorf("request auth = %q:%q token %q", user, pass, token)
+	}
+
+	if _, err := c.Get("api/project/get?slow=1", nil); err == nil {
+		t.Error("request did not time out")
+	}
+	if _, err := NewClientFromProfile(p, "carts"); err == nil {
+		t.Error("client created for a project without token")
+	}
+
+	// the credentials of the profile stay with the profile host
+	var otherUser string
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		otherUser, _, _ = r.BasicAuth()
+		w.Write([]byte(`{}`))
+	}))
+	defer other.Close()
+	var d InterfaceData
+	d.Method, d.Path = "GET", "/orders"
+	if _, err := c.Mock.Call(&d, 11, &MockCallOptions{BaseURL: other.URL}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if otherUser != "" {
+		t.Errorf("mock of another host got the profile user %q", otherUser)
+	}
+}
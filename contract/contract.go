@@ -0,0 +1,181 @@
+// Package contract verifies that a running service behaves as documented in
+// YApi: it sends a sample request for every interface and validates the
+// response against the documented response body JSON Schema.
+package contract
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	yapi "github.com/micrease/go-yapi"
+)
+
+// Verifier sends sample requests to a service and checks the responses.
+type Verifier struct {
+	// BaseURL of the service, the interface path is appended to it.
+	BaseURL string
+
+	// Header is added to every request, e.g. for authentication.
+	Header http.Header
+
+	// HTTPClient used for the requests. Defaults to a client with a 30s timeout.
+	HTTPClient *http.Client
+}
+
+// NewVerifier returns a Verifier for the service at baseURL.
+func NewVerifier(baseURL string) *Verifier {
+	return &Verifier{
+		BaseURL:    baseURL,
+		Header:     make(http.Header),
+		HTTPClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// NewEnvVerifier returns a Verifier for a YApi project environment: requests
+// go to env.Domain followed by the project basepath and carry the env headers.
+func NewEnvVerifier(env yapi.ProjectEnv, basepath string) *Verifier {
+	v := NewVerifier(strings.TrimRight(env.Domain, "/") + basepath)
+	for _, h := range env.Header {
+		v.Header.Set(h.Name, h.Value)
+	}
+	return v
+}
+
+// Result is the outcome of verifying one interface.
+type Result struct {
+	ID         int                    `json:"id"`
+	Method     string                 `json:"method"`
+	Path       string                 `json:"path"`
+	Title      string                 `json:"title"`
+	Passed     bool                   `json:"passed"`
+	StatusCode int                    `json:"status_code,omitempty"`
+	Error      string                 `json:"error,omitempty"`
+	Note       string                 `json:"note,omitempty"`
+	Mismatches []yapi.ValidationError `json:"mismatches,omitempty"`
+}
+
+// Report collects the results of a verification run.
+type Report struct {
+	Passed  int      `json:"passed"`
+	Failed  int      `json:"failed"`
+	Results []Result `json:"results"`
+}
+
+// OK reports whether every interface passed.
+func (r *Report) OK() bool {
+	return r.Failed == 0
+}
+
+// WriteJSON writes the report as indented JSON.
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// WriteText writes a human readable report, one line per interface followed by its mismatches.
+func (r *Report) WriteText(w io.Writer) error {
+	for _, res := range r.Results {
+		status := "PASS"
+		if !res.Passed {
+			status = "FAIL"
+		}
+		line := fmt.Sprintf("%s %s %s", status, res.Method, res.Path)
+		if res.Title != "" {
+			line += " (" + res.Title + ")"
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+		if res.Error != "" {
+			fmt.Fprintf(w, "    error: %s\n", res.Error)
+		}
+		if res.Note != "" {
+			fmt.Fprintf(w, "    note: %s\n", res.Note)
+		}
+		for _, m := range res.Mismatches {
+			fmt.Fprintf(w, "    %s\n", m.Error())
+		}
+	}
+	_, err := fmt.Fprintf(w, "%d passed, %d failed\n", r.Passed, r.Failed)
+	return err
+}
+
+// Verify checks every interface in order and returns the report.
+func (v *Verifier) Verify(interfaces []yapi.InterfaceData) *Report {
+	report := &Report{}
+	for i := range interfaces {
+		res := v.VerifyOne(&interfaces[i])
+		if res.Passed {
+			report.Passed++
+		} else {
+			report.Failed++
+		}
+		report.Results = append(report.Results, res)
+	}
+	return report
+}
+
+// VerifyOne sends the sample request of d and validates the response.
+func (v *Verifier) VerifyOne(d *yapi.InterfaceData) Result {
+	res := Result{ID: d.ID, Method: strings.ToUpper(d.Method), Path: d.Path, Title: d.Title}
+
+	req, err := d.SampleRequest(v.BaseURL)
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	for name, values := range v.Header {
+		req.Header[name] = values
+	}
+	client := v.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+
+	res.StatusCode = resp.StatusCode
+	res.Mismatches = Check(d, resp.StatusCode, body)
+	res.Passed = len(res.Mismatches) == 0
+	if res.Passed && d.ResBodyType == "json" && !d.ResBodyIsJsonSchema {
+		res.Note = "response body is not documented as json schema, only checked for valid json"
+	}
+	return res
+}
+
+// Check validates a response status and body against the documentation of d.
+func Check(d *yapi.InterfaceData, statusCode int, body []byte) []yapi.ValidationError {
+	if statusCode < 200 || statusCode > 299 {
+		return []yapi.ValidationError{{Message: fmt.Sprintf("unexpected status code %d", statusCode)}}
+	}
+	if d.ResBodyType != "" && d.ResBodyType != "json" {
+		return nil
+	}
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return []yapi.ValidationError{{Message: "response body is not valid json: " + err.Error()}}
+	}
+	if !d.ResBodyIsJsonSchema {
+		return nil
+	}
+	schema, err := d.ResBodySchema()
+	if err != nil {
+		return []yapi.ValidationError{{Message: "documented response schema is invalid: " + err.Error()}}
+	}
+	return schema.Validate(value)
+}
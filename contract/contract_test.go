@@ -0,0 +1,91 @@
+package contract
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	yapi "github.com/micrease/go-yapi"
+)
+
+const userSchema = `{"type":"object","properties":{
+	"id":{"type":"integer"},
+	"name":{"type":"string"},
+	"tags":{"type":"array","items":{"type":"string"}}
+},"required":["id","name"]}`
+
+func TestVerifier_Verify(t *testing.T) {
+	var gotQuery, gotHeader, gotBody string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/users/7", func(w http.ResponseWriter, r *http.Request) {
+		gotQuery = r.URL.Query().Get("verbose")
+		gotHeader = r.Header.Get("X-Token")
+		fmt.Fprint(w, `{"id":7,"name":"bob","tags":["a"]}`)
+	})
+	mux.HandleFunc("/v1/users", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		gotBody = string(body)
+		fmt.Fprint(w, `{"id":"8","tags":["a",1]}`)
+	})
+	mux.HandleFunc("/v1/broken", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "boom", http.StatusInternalServerError)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	var get, post, broken yapi.InterfaceData
+	get.Method, get.Path = "GET", "/users/{id}"
+	get.ReqParams = []yapi.ReqKVItemSimple{{Name: "id", Example: "7"}}
+	get.ReqQuery = []yapi.ReqKVItemDetail{{ReqKVItemSimple: yapi.ReqKVItemSimple{Name: "verbose", Example: "1"}}}
+	get.ResBodyType, get.ResBodyIsJsonSchema, get.ResBody = "json", true, userSchema
+
+	post.Method, post.Path = "POST", "/users"
+	post.ReqBodyType, post.ReqBodyIsJsonSchema = "json", true
+	post.ReqBodyOther = `{"type":"object","properties":{"name":{"type":"string","default":"bob"}}}`
+	post.ResBodyType, post.ResBodyIsJsonSchema, post.ResBody = "json", true, userSchema
+
+	broken.Method, broken.Path = "GET", "/broken"
+
+	env := yapi.ProjectEnv{Domain: server.URL + "/", Header: []yapi.EnvHeader{{Name: "X-Token", Value: "secret"}}}
+	report := NewEnvVerifier(env, "/v1").Verify([]yapi.InterfaceData{get, post, broken})
+
+	if report.Passed != 1 || report.Failed != 2 || report.OK() {
+		t.Fatalf("report = %d passed, %d failed, want 1 and 2", report.Passed, report.Failed)
+	}
+	if gotQuery != "1" || gotHeader != "secret" {
+		t.Errorf("GET request query = %q, header = %q, want 1 and secret", gotQuery, gotHeader)
+	}
+	if gotBody != `{"name":"bob"}` {
+		t.Errorf("POST request body = %s, want the schema example", gotBody)
+	}
+
+	pointers := map[string]bool{}
+	for _, m := range report.Results[1].Mismatches {
+		pointers[m.Pointer] = true
+	}
+	for _, want := range []string{"/id", "/name", "/tags/1"} {
+		if !pointers[want] {
+			t.Errorf("missing mismatch at %s, got %+v", want, report.Results[1].Mismatches)
+		}
+	}
+	if report.Results[2].StatusCode != http.StatusInternalServerError {
+		t.Errorf("broken status = %d, want 500", report.Results[2].StatusCode)
+	}
+
+	var text bytes.Buffer
+	report.WriteText(&text)
+	if !strings.Contains(text.String(), "FAIL POST /users") || !strings.Contains(text.String(), "1 passed, 2 failed") {
+		t.Errorf("unexpected text report:\n%s", text.String())
+	}
+	var decoded Report
+	var buf bytes.Buffer
+	report.WriteJSON(&buf)
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil || len(decoded.Results) != 3 {
+		t.Errorf("JSON report does not round trip: %v", err)
+	}
+}
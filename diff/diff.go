@@ -0,0 +1,406 @@
+// Package diff compares two versions of YApi interfaces field by field:
+// method, path and status changes, added, removed and changed params, headers
+// and form fields, and property level changes of JSON Schema bodies.
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+
+	yapi "github.com/micrease/go-yapi"
+)
+
+// Kind is the kind of a change.
+type Kind string
+
+const (
+	Added   Kind = "added"
+	Removed Kind = "removed"
+	Changed Kind = "changed"
+)
+
+// Location is the part of an interface a change belongs to.
+type Location string
+
+const (
+	LocInterface Location = "interface"
+	LocParams    Location = "req_params"
+	LocQuery     Location = "req_query"
+	LocHeaders   Location = "req_headers"
+	LocForm      Location = "req_body_form"
+	LocReqBody   Location = "req_body"
+	LocResBody   Location = "res_body"
+)
+
+// Change is a single difference between two versions of an interface.
+//
+// Name is the interface field for LocInterface, the param name for params,
+// headers and form fields, and the property path (e.g. "data.list[].id",
+// empty for the root) for bodies. Attribute names the changed attribute of a
+// param or schema node, such as "type" or "required".
+type Change struct {
+	Location  Location    `json:"location"`
+	Kind      Kind        `json:"kind"`
+	Name      string      `json:"name"`
+	Attribute string      `json:"attribute,omitempty"`
+	Old       interface{} `json:"old,omitempty"`
+	New       interface{} `json:"new,omitempty"`
+
+	// Required tells for an added or removed param or property whether it is, or was, required.
+	Required bool `json:"required,omitempty"`
+}
+
+// String returns a one line description of the change.
+func (c Change) String() string {
+	sign := map[Kind]string{Added: "+", Removed: "-", Changed: "~"}[c.Kind]
+	where := string(c.Location)
+	if c.Name != "" || c.Location == LocReqBody || c.Location == LocResBody {
+		where += " " + displayName(c.Name)
+	}
+	if c.Location == LocInterface {
+		where = c.Name
+	}
+	switch c.Kind {
+	case Added, Removed:
+		if c.Required {
+			return fmt.Sprintf("%s %s (required)", sign, where)
+		}
+		return fmt.Sprintf("%s %s", sign, where)
+	}
+	if c.Attribute != "" {
+		where += " " + c.Attribute
+	}
+	return fmt.Sprintf("%s %s: %s -> %s", sign, where, format(c.Old), format(c.New))
+}
+
+func displayName(name string) string {
+	if name == "" {
+		return "(root)"
+	}
+	return name
+}
+
+func format(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	s := strings.Join(strings.Fields(string(data)), " ")
+	if r := []rune(s); len(r) > 80 {
+		return string(r[:77]) + "..."
+	}
+	return s
+}
+
+// Ref identifies an interface.
+type Ref struct {
+	ID     int    `json:"id,omitempty"`
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	Title  string `json:"title,omitempty"`
+}
+
+func refOf(d *yapi.InterfaceData) Ref {
+	return Ref{ID: d.ID, Method: strings.ToUpper(d.Method), Path: d.Path, Title: d.Title}
+}
+
+func (r Ref) String() string {
+	s := r.Method + " " + r.Path
+	if r.Title != "" {
+		s += " (" + r.Title + ")"
+	}
+	return s
+}
+
+// InterfaceDiff lists the changes between two versions of an interface.
+type InterfaceDiff struct {
+	Old     Ref      `json:"old"`
+	New     Ref      `json:"new"`
+	Changes []Change `json:"changes"`
+}
+
+// Empty reports whether both versions are equivalent.
+func (d *InterfaceDiff) Empty() bool {
+	return len(d.Changes) == 0
+}
+
+// WriteText writes the diff in a human readable form.
+func (d *InterfaceDiff) WriteText(w io.Writer) error {
+	if _, err := fmt.Fprintf(w, "~ %s\n", d.New); err != nil {
+		return err
+	}
+	for _, c := range d.Changes {
+		if _, err := fmt.Fprintf(w, "    %s\n", c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Interfaces compares two versions of an interface.
+func Interfaces(old, new *yapi.InterfaceData) *InterfaceDiff {
+	d := &InterfaceDiff{Old: refOf(old), New: refOf(new), Changes: []Change{}}
+	add := func(c Change) { d.Changes = append(d.Changes, c) }
+
+	basic := []struct {
+		name     string
+		old, new interface{}
+	}{
+		{"method", strings.ToUpper(old.Method), strings.ToUpper(new.Method)},
+		{"path", old.Path, new.Path},
+		{"status", old.Status, new.Status},
+		{"title", old.Title, new.Title},
+		{"tag", nonNil(old.Tag), nonNil(new.Tag)},
+		{"req_body_type", old.ReqBodyType, new.ReqBodyType},
+		{"res_body_type", old.ResBodyType, new.ResBodyType},
+	}
+	for _, b := range basic {
+		if !reflect.DeepEqual(b.old, b.new) {
+			add(Change{Location: LocInterface, Kind: Changed, Name: b.name, Old: b.old, New: b.new})
+		}
+	}
+
+	d.Changes = append(d.Changes, simpleParams(LocParams, old.ReqParams, new.ReqParams)...)
+	d.Changes = append(d.Changes, detailParams(LocHeaders, old.ReqHeaders, new.ReqHeaders)...)
+	d.Changes = append(d.Changes, detailParams(LocQuery, old.ReqQuery, new.ReqQuery)...)
+	d.Changes = append(d.Changes, detailParams(LocForm, old.ReqBodyForm, new.ReqBodyForm)...)
+	d.Changes = append(d.Changes, body(LocReqBody, old.ReqBodyIsJsonSchema, old.ReqBodyOther, new.ReqBodyIsJsonSchema, new.ReqBodyOther)...)
+	d.Changes = append(d.Changes, body(LocResBody, old.ResBodyIsJsonSchema, old.ResBody, new.ResBodyIsJsonSchema, new.ResBody)...)
+	return d
+}
+
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
+
+type param struct {
+	yapi.ReqKVItemSimple
+	typ      string
+	required bool
+}
+
+func simpleParams(loc Location, old, new []yapi.ReqKVItemSimple) []Change {
+	convert := func(items []yapi.ReqKVItemSimple) []param {
+		out := make([]param, len(items))
+		for i, item := range items {
+			// path params are always required
+			out[i] = param{ReqKVItemSimple: item, required: true}
+		}
+		return out
+	}
+	return params(loc, convert(old), convert(new))
+}
+
+func detailParams(loc Location, old, new []yapi.ReqKVItemDetail) []Change {
+	convert := func(items []yapi.ReqKVItemDetail) []param {
+		out := make([]param, len(items))
+		for i, item := range items {
+			out[i] = param{ReqKVItemSimple: item.ReqKVItemSimple, typ: item.Type, required: item.Required == "1"}
+		}
+		return out
+	}
+	return params(loc, convert(old), convert(new))
+}
+
+// params matches params by name. Header names are compared case-insensitively.
+func params(loc Location, old, new []param) []Change {
+	key := func(name string) string {
+		if loc == LocHeaders {
+			return strings.ToLower(name)
+		}
+		return name
+	}
+	oldByName := make(map[string]param, len(old))
+	for _, p := range old {
+		oldByName[key(p.Name)] = p
+	}
+	newByName := make(map[string]bool, len(new))
+
+	var changes []Change
+	for _, n := range new {
+		newByName[key(n.Name)] = true
+		o, ok := oldByName[key(n.Name)]
+		if !ok {
+			changes = append(changes, Change{Location: loc, Kind: Added, Name: n.Name, Required: n.required})
+			continue
+		}
+		attrs := []struct {
+			name     string
+			old, new interface{}
+		}{
+			{"required", o.required, n.required},
+			{"type", o.typ, n.typ},
+			{"value", o.Value, n.Value},
+			{"example", o.Example, n.Example},
+			{"desc", o.Desc, n.Desc},
+		}
+		for _, a := range attrs {
+			if a.old != a.new {
+				changes = append(changes, Change{Location: loc, Kind: Changed, Name: n.Name, Attribute: a.name, Old: a.old, New: a.new})
+			}
+		}
+	}
+	for _, o := range old {
+		if !newByName[key(o.Name)] {
+			changes = append(changes, Change{Location: loc, Kind: Removed, Name: o.Name, Required: o.required})
+		}
+	}
+	return changes
+}
+
+// body compares two bodies. JSON Schema bodies are compared property by
+// property, other bodies as a whole, ignoring JSON formatting.
+func body(loc Location, oldIsSchema bool, old string, newIsSchema bool, new string) []Change {
+	if oldIsSchema && newIsSchema {
+		oldSchema, oldErr := yapi.ParseSchema(old)
+		newSchema, newErr := yapi.ParseSchema(new)
+		if oldErr == nil && newErr == nil {
+			return Schemas(loc, oldSchema, newSchema)
+		}
+	}
+	var changes []Change
+	if oldIsSchema != newIsSchema {
+		changes = append(changes, Change{Location: loc, Kind: Changed, Attribute: "json_schema", Old: oldIsSchema, New: newIsSchema})
+	}
+	if !sameJSON(old, new) {
+		changes = append(changes, Change{Location: loc, Kind: Changed, Attribute: "body", Old: old, New: new})
+	}
+	return changes
+}
+
+func sameJSON(a, b string) bool {
+	if strings.TrimSpace(a) == strings.TrimSpace(b) {
+		return true
+	}
+	var va, vb interface{}
+	if json.Unmarshal([]byte(a), &va) != nil || json.Unmarshal([]byte(b), &vb) != nil {
+		return false
+	}
+	return reflect.DeepEqual(va, vb)
+}
+
+// Schemas compares two JSON Schemas property by property. Either may be nil.
+func Schemas(loc Location, old, new *yapi.Schema) []Change {
+	var changes []Change
+	compareSchema(loc, "", old, new, &changes)
+	return changes
+}
+
+func compareSchema(loc Location, name string, old, new *yapi.Schema, changes *[]Change) {
+	if old == nil && new == nil {
+		return
+	}
+	if old == nil || new == nil {
+		kind := Added
+		if new == nil {
+			kind = Removed
+		}
+		*changes = append(*changes, Change{Location: loc, Kind: kind, Name: name})
+		return
+	}
+	changed := func(attr string, o, n interface{}) {
+		*changes = append(*changes, Change{Location: loc, Kind: Changed, Name: name, Attribute: attr, Old: o, New: n})
+	}
+
+	if o, n := strings.Join(old.Types(), ","), strings.Join(new.Types(), ","); o != n {
+		changed("type", o, n)
+	}
+	attrs := []struct {
+		name     string
+		old, new interface{}
+	}{
+		{"title", old.Title, new.Title},
+		{"description", old.Description, new.Description},
+		{"format", old.Format, new.Format},
+		{"pattern", old.Pattern, new.Pattern},
+		{"default", old.Default, new.Default},
+		{"enum", old.Enum, new.Enum},
+		{"minimum", old.Minimum, new.Minimum},
+		{"maximum", old.Maximum, new.Maximum},
+		{"minLength", old.MinLength, new.MinLength},
+		{"maxLength", old.MaxLength, new.MaxLength},
+		{"minItems", old.MinItems, new.MinItems},
+		{"maxItems", old.MaxItems, new.MaxItems},
+		{"mock", old.Mock, new.Mock},
+	}
+	for _, a := range attrs {
+		if !reflect.DeepEqual(a.old, a.new) {
+			changed(a.name, deref(a.old), deref(a.new))
+		}
+	}
+	extraKeys := map[string]bool{}
+	for k := range old.Extra {
+		extraKeys[k] = true
+	}
+	for k := range new.Extra {
+		extraKeys[k] = true
+	}
+	var extras []string
+	for k := range extraKeys {
+		if k != "type" {
+			extras = append(extras, k)
+		}
+	}
+	sort.Strings(extras)
+	for _, k := range extras {
+		if !sameJSON(string(old.Extra[k]), string(new.Extra[k])) {
+			changed(k, rawValue(old.Extra[k]), rawValue(new.Extra[k]))
+		}
+	}
+
+	for _, prop := range new.PropertyNames() {
+		child := join(name, prop)
+		o, ok := old.Properties[prop]
+		if !ok {
+			*changes = append(*changes, Change{Location: loc, Kind: Added, Name: child, Required: new.IsRequired(prop)})
+			continue
+		}
+		if old.IsRequired(prop) != new.IsRequired(prop) {
+			*changes = append(*changes, Change{Location: loc, Kind: Changed, Name: child, Attribute: "required", Old: old.IsRequired(prop), New: new.IsRequired(prop)})
+		}
+		compareSchema(loc, child, o, new.Properties[prop], changes)
+	}
+	for _, prop := range old.PropertyNames() {
+		if _, ok := new.Properties[prop]; !ok {
+			*changes = append(*changes, Change{Location: loc, Kind: Removed, Name: join(name, prop), Required: old.IsRequired(prop)})
+		}
+	}
+	if old.Items != nil || new.Items != nil {
+		compareSchema(loc, name+"[]", old.Items, new.Items, changes)
+	}
+}
+
+func join(parent, child string) string {
+	if parent == "" {
+		return child
+	}
+	return parent + "." + child
+}
+
+// deref turns typed nil pointers into nil and dereferences the others, for readable output.
+func deref(v interface{}) interface{} {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil
+		}
+		return rv.Elem().Interface()
+	}
+	return v
+}
+
+func rawValue(raw json.RawMessage) interface{} {
+	if raw == nil {
+		return nil
+	}
+	var v interface{}
+	json.Unmarshal(raw, &v)
+	return v
+}
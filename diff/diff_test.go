@@ -0,0 +1,133 @@
+package diff
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	yapi "github.com/micrease/go-yapi"
+)
+
+func query(name, required, desc string) yapi.ReqKVItemDetail {
+	return yapi.ReqKVItemDetail{ReqKVItemSimple: yapi.ReqKVItemSimple{Name: name, Desc: desc}, Required: required}
+}
+
+func baseInterface() yapi.InterfaceData {
+	var d yapi.InterfaceData
+	d.ID, d.ProjectID, d.Method, d.Path, d.Title, d.Status = 1, 11, "GET", "/users/{id}", "get user", "done"
+	d.ReqParams = []yapi.ReqKVItemSimple{{Name: "id"}}
+	d.ReqQuery = []yapi.ReqKVItemDetail{query("fields", "0", "fields"), query("lang", "0", "")}
+	d.ReqHeaders = []yapi.ReqKVItemDetail{query("X-Token", "1", "")}
+	d.ResBodyType, d.ResBodyIsJsonSchema = "json", true
+	d.ResBody = `{"type":"object","properties":{
+		"id":{"type":"integer"},
+		"name":{"type":"string"},
+		"roles":{"type":"array","items":{"type":"object","properties":{"code":{"type":"string"}}}}
+	},"required":["id"]}`
+	return d
+}
+
+func describe(changes []Change) []string {
+	out := make([]string, len(changes))
+	for i, c := range changes {
+		out[i] = c.String()
+	}
+	return out
+}
+
+func TestInterfaces(t *testing.T) {
+	old := baseInterface()
+	new := baseInterface()
+	new.Method = "post"
+	new.Status = "undone"
+	new.ReqQuery = []yapi.ReqKVItemDetail{query("fields", "1", "field list"), query("page", "1", "")}
+	new.ReqHeaders = []yapi.ReqKVItemDetail{query("x-token", "1", "")}
+	new.ResBody = `{"type":"object","properties":{
+		"id":{"type":"string"},
+		"roles":{"type":"array","items":{"type":"object","properties":{"code":{"type":"string"},"level":{"type":"integer"}},"required":["level"]}},
+		"email":{"type":"string","format":"email"}
+	},"required":["id","email"]}`
+
+	got := describe(Interfaces(&old, &new).Changes)
+	// params are reported in the order of the new version, followed by removals
+	want := []string{
+		`~ method: "GET" -> "POST"`,
+		`~ status: "done" -> "undone"`,
+		`~ req_query fields required: false -> true`,
+		`~ req_query fields desc: "fields" -> "field list"`,
+		`+ req_query page (required)`,
+		`- req_query lang`,
+		`~ res_body id type: "integer" -> "string"`,
+		`+ res_body roles[].level (required)`,
+		`+ res_body email (required)`,
+		`- res_body name`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("changes =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	if d := Interfaces(&old, &old); !d.Empty() {
+		t.Errorf("comparing an interface with itself returned %v", describe(d.Changes))
+	}
+}
+
+func TestInterfaces_RawBody(t *testing.T) {
+	old := baseInterface()
+	old.ResBodyIsJsonSchema, old.ResBody = false, `{"code": 0}`
+	new := old
+	new.ResBody = "{\n  \"code\":0\n}"
+	if d := Interfaces(&old, &new); !d.Empty() {
+		t.Errorf("reformatted raw body reported as change: %v", describe(d.Changes))
+	}
+	new.ResBody = `{"code":1}`
+	if got := describe(Interfaces(&old, &new).Changes); len(got) != 1 || !strings.HasPrefix(got[0], "~ res_body (root) body:") {
+		t.Errorf("raw body change = %v", got)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	a, b, c := baseInterface(), baseInterface(), baseInterface()
+	b.ID, b.Path = 2, "/orders"
+	c.ID, c.Path = 3, "/carts"
+	moved := a
+	moved.Path = "/people/{id}"
+	added := c
+	added.ID, added.Path = 4, "/items"
+
+	report := Compare([]yapi.InterfaceData{a, b, c}, []yapi.InterfaceData{moved, b, added})
+	if len(report.Added) != 1 || report.Added[0].Path != "/items" {
+		t.Errorf("added = %+v, want /items", report.Added)
+	}
+	if len(report.Removed) != 1 || report.Removed[0].Path != "/carts" {
+		t.Errorf("removed = %+v, want /carts", report.Removed)
+	}
+	if len(report.Changed) != 1 || report.Changed[0].Old.Path != "/users/{id}" || report.Changed[0].New.Path != "/people/{id}" {
+		t.Errorf("changed = %+v, want the moved interface", report.Changed)
+	}
+
+	// the same ID in another project is another interface
+	staging := moved
+	staging.ProjectID = 12
+	report2 := Compare([]yapi.InterfaceData{a}, []yapi.InterfaceData{staging})
+	if len(report2.Changed) != 0 || len(report2.Added) != 1 || len(report2.Removed) != 1 {
+		t.Errorf("report across projects = %+v, want an addition and a removal", report2)
+	}
+	unknown := moved
+	unknown.ProjectID = 0
+	if r := Compare([]yapi.InterfaceData{a}, []yapi.InterfaceData{unknown}); len(r.Changed) != 0 {
+		t.Errorf("followed the ID of an interface without project: %+v", r)
+	}
+
+	var text, js bytes.Buffer
+	report.WriteText(&text)
+	if !strings.Contains(text.String(), `~ path: "/users/{id}" -> "/people/{id}"`) || !strings.Contains(text.String(), "1 added, 1 removed, 1 changed") {
+		t.Errorf("unexpected text report:\n%s", text.String())
+	}
+	report.WriteJSON(&js)
+	var decoded Report
+	if err := json.Unmarshal(js.Bytes(), &decoded); err != nil || len(decoded.Changed) != 1 || decoded.Changed[0].Changes[0].Kind != Changed {
+		t.Errorf("JSON report does not round trip: %v\n%s", err, js.String())
+	}
+}
@@ -0,0 +1,108 @@
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	yapi "github.com/micrease/go-yapi"
+)
+
+// Report is the difference between two sets of interfaces, such as a remote
+// project and a local snapshot, or two snapshots.
+type Report struct {
+	Added   []Ref           `json:"added"`
+	Removed []Ref           `json:"removed"`
+	Changed []InterfaceDiff `json:"changed"`
+}
+
+// Key identifies an interface by method and path.
+func Key(d *yapi.InterfaceData) string {
+	method := strings.ToUpper(d.Method)
+	if method == "" {
+		method = "GET"
+	}
+	return method + " " + d.Path
+}
+
+// Compare matches the interfaces of old and new by method and path, falling
+// back to the interface ID so that a path change shows up as a change rather
+// than as a removal and an addition. IDs are only followed between
+// interfaces of the same project, as other projects and YApi instances
+// reuse them.
+func Compare(old, new []yapi.InterfaceData) *Report {
+	r := &Report{Added: []Ref{}, Removed: []Ref{}, Changed: []InterfaceDiff{}}
+	byKey := make(map[string]int, len(old))
+	byID := make(map[int]int, len(old))
+	for i := range old {
+		byKey[Key(&old[i])] = i
+		if old[i].ID != 0 {
+			byID[old[i].ID] = i
+		}
+	}
+	newKeys := make(map[string]bool, len(new))
+	for i := range new {
+		newKeys[Key(&new[i])] = true
+	}
+
+	matched := make([]bool, len(old))
+	for i := range new {
+		n := &new[i]
+		j, ok := byKey[Key(n)]
+		if !ok && n.ID != 0 {
+			// only follow the ID if the old path is gone from the new version
+			if k, found := byID[n.ID]; found && !newKeys[Key(&old[k])] && sameProject(&old[k], n) {
+				j, ok = k, true
+			}
+		}
+		if !ok || matched[j] {
+			r.Added = append(r.Added, refOf(n))
+			continue
+		}
+		matched[j] = true
+		if d := Interfaces(&old[j], n); !d.Empty() {
+			r.Changed = append(r.Changed, *d)
+		}
+	}
+	for i := range old {
+		if !matched[i] {
+			r.Removed = append(r.Removed, refOf(&old[i]))
+		}
+	}
+	return r
+}
+
+// sameProject reports whether a and b are known to belong to one project.
+func sameProject(a, b *yapi.InterfaceData) bool {
+	return a.ProjectID != 0 && a.ProjectID == b.ProjectID
+}
+
+// Empty reports whether both sets are equivalent.
+func (r *Report) Empty() bool {
+	return len(r.Added) == 0 && len(r.Removed) == 0 && len(r.Changed) == 0
+}
+
+// WriteText writes the report in a human readable form.
+func (r *Report) WriteText(w io.Writer) error {
+	for _, ref := range r.Added {
+		fmt.Fprintf(w, "+ %s\n", ref)
+	}
+	for _, ref := range r.Removed {
+		fmt.Fprintf(w, "- %s\n", ref)
+	}
+	for i := range r.Changed {
+		if err := r.Changed[i].WriteText(w); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(w, "%d added, %d removed, %d changed\n", len(r.Added), len(r.Removed), len(r.Changed))
+	return err
+}
+
+// WriteJSON writes the report as indented JSON.
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
@@ -0,0 +1,195 @@
+package docgen
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+//go:embed templates/html.tmpl templates/style.css templates/app.js
+var htmlFiles embed.FS
+
+// HTMLOptions customizes an HTML site generator.
+type HTMLOptions struct {
+	// MultiPage writes one page per interface next to index.html instead of a
+	// single page holding every interface.
+	MultiPage bool
+	// Templates are html/template sources parsed after the defaults. Each
+	// {{define "name"}} block replaces the default template of that name:
+	// "page", "sidebar", "index", "category", "interface", "headers", "kv",
+	// "body", "tree" and "node".
+	Templates []string
+	// Funcs are added to the template functions: upper, lower, required
+	// (see TemplateFuncs), href linking to an interface and categoryHref
+	// linking to a category.
+	Funcs template.FuncMap
+}
+
+// HTML generates a static documentation site that needs neither YApi nor a
+// web server: the pages, style sheet, script and search index are plain files.
+type HTML struct {
+	tmpl      *template.Template
+	multiPage bool
+}
+
+// Page is the view passed to the "page" template.
+type Page struct {
+	*Document
+	// Interface is the interface of a multi-page site page, nil for the index.
+	Interface *Interface
+	MultiPage bool
+}
+
+// SearchEntry is an interface in the search index.
+type SearchEntry struct {
+	Anchor   string `json:"anchor"`
+	Title    string `json:"title"`
+	Method   string `json:"method"`
+	Path     string `json:"path"`
+	Category string `json:"category"`
+	// Text holds the parameter and field names and descriptions.
+	Text string `json:"text"`
+}
+
+// SearchIndex lists the interfaces of the document for the site search.
+func SearchIndex(doc *Document) []SearchEntry {
+	var index []SearchEntry
+	for _, c := range doc.Categories {
+		for _, i := range c.Interfaces {
+			var words []string
+			for _, p := range i.ReqParams {
+				words = append(words, p.Name, p.Desc)
+			}
+			for _, p := range i.ReqQuery {
+				words = append(words, p.Name, p.Desc)
+			}
+			for _, p := range i.ReqBodyForm {
+				words = append(words, p.Name, p.Desc)
+			}
+			for _, list := range [][]SchemaRow{bodyRows(i.Request), bodyRows(i.Response)} {
+				for _, r := range list {
+					words = append(words, r.Name, r.Description)
+				}
+			}
+			index = append(index, SearchEntry{
+				Anchor:   i.Anchor,
+				Title:    i.Title,
+				Method:   strings.ToUpper(i.Method),
+				Path:     i.Path,
+				Category: c.Name,
+				Text:     strings.Join(strings.Fields(strings.Join(words, " ")), " "),
+			})
+		}
+	}
+	return index
+}
+
+func bodyRows(b *Body) []SchemaRow {
+	if b == nil {
+		return nil
+	}
+	return b.Rows
+}
+
+// NewHTML returns an HTML site generator. opts may be nil.
+func NewHTML(opts *HTMLOptions) (*HTML, error) {
+	if opts == nil {
+		opts = &HTMLOptions{}
+	}
+	funcs := template.FuncMap{
+		"upper":    strings.ToUpper,
+		"lower":    strings.ToLower,
+		"required": required,
+		"href": func(i *Interface) string {
+			if opts.MultiPage {
+				return i.Anchor + ".html"
+			}
+			return "#" + i.Anchor
+		},
+		"categoryHref": func(c *Category) string {
+			if opts.MultiPage {
+				return "index.html#" + c.Anchor
+			}
+			return "#" + c.Anchor
+		},
+	}
+	for name, fn := range opts.Funcs {
+		funcs[name] = fn
+	}
+	src, err := htmlFiles.ReadFile("templates/html.tmpl")
+	if err != nil {
+		return nil, err
+	}
+	tmpl, err := template.New("html").Funcs(funcs).Parse(string(src))
+	if err != nil {
+		return nil, err
+	}
+	for i, src := range opts.Templates {
+		if tmpl, err = tmpl.New(fmt.Sprintf("custom%d", i)).Parse(src); err != nil {
+			return nil, err
+		}
+	}
+	return &HTML{tmpl: tmpl, multiPage: opts.MultiPage}, nil
+}
+
+// RenderPage writes a single page: the index, or the page of i on a
+// multi-page site. i is ignored on a single page site.
+func (h *HTML) RenderPage(w io.Writer, doc *Document, i *Interface) error {
+	p := &Page{Document: doc, MultiPage: h.multiPage}
+	if h.multiPage {
+		p.Interface = i
+	}
+	return h.tmpl.ExecuteTemplate(w, "page", p)
+}
+
+// Write writes the site to dir, creating it if needed: index.html, a page
+// per interface on a multi-page site, style.css, app.js and the search index
+// search.js.
+func (h *HTML) Write(dir string, doc *Document) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	if err := h.writePage(filepath.Join(dir, "index.html"), doc, nil); err != nil {
+		return err
+	}
+	if h.multiPage {
+		for _, c := range doc.Categories {
+			for _, i := range c.Interfaces {
+				if err := h.writePage(filepath.Join(dir, i.Anchor+".html"), doc, i); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	for _, name := range []string{"style.css", "app.js"} {
+		data, err := htmlFiles.ReadFile("templates/" + name)
+		if err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+			return err
+		}
+	}
+	// A script rather than a JSON file, as browsers refuse to fetch files
+	// from pages opened with file://.
+	index, err := json.Marshal(SearchIndex(doc))
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(dir, "search.js"), []byte("window.searchIndex = "+string(index)+";\n"), 0644)
+}
+
+func (h *HTML) writePage(path string, doc *Document, i *Interface) error {
+	var buf bytes.Buffer
+	if err := h.RenderPage(&buf, doc, i); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, buf.Bytes(), 0644)
+}
@@ -0,0 +1,105 @@
+package docgen
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	yapi "github.com/micrease/go-yapi"
+)
+
+func testHTMLDocument() *Document {
+	s := testSnapshot()
+	s.Project.Env = []yapi.ProjectEnv{{Name: "local", Domain: "http://localhost:8080"}, {Name: "prod", Domain: "https://api.example.com"}}
+	s.Categories[0].Desc = "<b>user</b> apis"
+	return NewDocument(s)
+}
+
+func TestHTML_SinglePage(t *testing.T) {
+	h, err := NewHTML(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := h.RenderPage(&buf, testHTMLDocument(), nil); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		`<body data-basepath="/api" data-multipage="false">`,
+		`<option value="http://localhost:8080">local</option>`,
+		`<summary><a href="#cat-users">Users</a></summary>`,
+		`<li><a href="#get-users-id" data-anchor="get-users-id"><span class="method get">GET</span> get user</a></li>`,
+		`<p>&lt;b&gt;user&lt;/b&gt; apis</p>`,
+		`<section class="interface" id="get-users-id">`,
+		`<code class="url" data-path="/users/{id}">/users/{id}</code> <span class="status">done</span> <span class="tag">v1</span>`,
+		`<tr><td>X-Token</td><td></td><td>yes</td><td></td><td>a|b</td></tr>`,
+		`<details open><summary><span class="name">roles</span> <span class="type">array&lt;object&gt;</span></summary><ul class="tree">`,
+		`<li><span class="name">code</span> <span class="type">string</span> <span class="required">required</span> <span class="notes">enum: &#34;a&#34;, &#34;b&#34;</span></li>`,
+		`<section class="interface" id="post-users-2">`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("page misses %q", want)
+		}
+	}
+	if t.Failed() {
+		t.Log(out)
+	}
+}
+
+func TestHTML_Write(t *testing.T) {
+	dir, err := ioutil.TempDir("", "docgen")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	h, err := NewHTML(&HTMLOptions{MultiPage: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Write(dir, testHTMLDocument()); err != nil {
+		t.Fatal(err)
+	}
+	read := func(name string) string {
+		data, err := ioutil.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+
+	index := read("index.html")
+	if !strings.Contains(index, `<td><a href="get-users-id.html">get user</a></td>`) || strings.Contains(index, `<section class="interface"`) {
+		t.Errorf("index.html\n%s", index)
+	}
+	page := read("get-users-id.html")
+	if !strings.Contains(page, "<title>get user · demo</title>") || !strings.Contains(page, `<a href="index.html#cat-users">Users</a>`) || !strings.Contains(page, `id="get-users-id"`) || strings.Contains(page, `id="post-users"`) {
+		t.Errorf("get-users-id.html\n%s", page)
+	}
+	read("post-users.html")
+	read("post-users-2.html")
+	read("style.css")
+	read("app.js")
+
+	search := read("search.js")
+	if !strings.HasPrefix(search, "window.searchIndex = [") || !strings.Contains(search, `{"anchor":"get-users-id","title":"get user","method":"GET","path":"/users/{id}","category":"Users","text":"id user id fields comma separated id ID roles code"}`) {
+		t.Errorf("search.js = %s", search)
+	}
+}
+
+func TestHTML_Templates(t *testing.T) {
+	h, err := NewHTML(&HTMLOptions{Templates: []string{`{{define "node"}}<i>{{.Path}}</i>{{end}}`}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := h.RenderPage(&buf, testHTMLDocument(), nil); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "<li><i>roles[].code</i></li>") {
+		t.Errorf("custom node template not used\n%s", buf.String())
+	}
+}
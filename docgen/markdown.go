@@ -0,0 +1,107 @@
+package docgen
+
+import (
+	_ "embed"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+)
+
+//go:embed templates/markdown.tmpl
+var markdownTemplates string
+
+// MarkdownOptions customizes a Markdown renderer.
+type MarkdownOptions struct {
+	// Templates are text/template sources parsed after the defaults. Each
+	// {{define "name"}} block replaces the default template of that name:
+	// "document", "category", "toc", "interface", "params", "query",
+	// "headers", "form", "body" and "schema".
+	Templates []string
+	// Funcs are added to the template functions; see TemplateFuncs.
+	Funcs template.FuncMap
+}
+
+// Markdown renders documents as Markdown.
+type Markdown struct {
+	tmpl *template.Template
+}
+
+// TemplateFuncs returns the functions available to Markdown templates:
+// cell escapes text for a table cell, code wraps text in an inline code span,
+// upper upper-cases text, required renders a required flag ("1", "0" or a
+// bool), indent prefixes a schema row name by its depth and fence returns a
+// code fence.
+func TemplateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"cell":     cell,
+		"code":     code,
+		"upper":    strings.ToUpper,
+		"required": required,
+		"indent":   indent,
+		"fence":    func() string { return "```" },
+	}
+}
+
+// NewMarkdown returns a Markdown renderer. opts may be nil.
+func NewMarkdown(opts *MarkdownOptions) (*Markdown, error) {
+	if opts == nil {
+		opts = &MarkdownOptions{}
+	}
+	funcs := TemplateFuncs()
+	for name, fn := range opts.Funcs {
+		funcs[name] = fn
+	}
+	tmpl, err := template.New("markdown").Funcs(funcs).Parse(markdownTemplates)
+	if err != nil {
+		return nil, err
+	}
+	for i, src := range opts.Templates {
+		if tmpl, err = tmpl.New(fmt.Sprintf("custom%d", i)).Parse(src); err != nil {
+			return nil, err
+		}
+	}
+	return &Markdown{tmpl: tmpl}, nil
+}
+
+// Render writes the document.
+func (m *Markdown) Render(w io.Writer, doc *Document) error {
+	return m.tmpl.ExecuteTemplate(w, "document", doc)
+}
+
+// RenderInterface writes the section of a single interface.
+func (m *Markdown) RenderInterface(w io.Writer, i *Interface) error {
+	return m.tmpl.ExecuteTemplate(w, "interface", i)
+}
+
+var cellReplacer = strings.NewReplacer("|", `\|`, "<", "&lt;", ">", "&gt;", "\r\n", "<br>", "\n", "<br>")
+
+func cell(v interface{}) string {
+	return cellReplacer.Replace(strings.TrimSpace(fmt.Sprint(v)))
+}
+
+func code(v interface{}) string {
+	s := fmt.Sprint(v)
+	if s == "" {
+		return ""
+	}
+	if strings.Contains(s, "`") {
+		return "`` " + s + " ``"
+	}
+	return "`" + s + "`"
+}
+
+func required(v interface{}) string {
+	switch v {
+	case true, "1":
+		return "yes"
+	}
+	return "no"
+}
+
+func indent(depth int, name string) string {
+	if depth == 0 {
+		return name
+	}
+	return strings.Repeat("&emsp;", depth) + "└ " + name
+}
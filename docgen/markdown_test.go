@@ -0,0 +1,118 @@
+package docgen
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	yapi "github.com/micrease/go-yapi"
+	"github.com/micrease/go-yapi/snapshot"
+)
+
+func testSnapshot() *snapshot.Snapshot {
+	var get yapi.InterfaceData
+	get.ID, get.Method, get.Path, get.Title, get.Status = 1, "GET", "/users/{id}", "get user", "done"
+	get.Tag = []string{"v1", "public"}
+	get.ReqParams = []yapi.ReqKVItemSimple{{Name: "id", Example: "42", Desc: "user id"}}
+	get.ReqHeaders = []yapi.ReqKVItemDetail{{ReqKVItemSimple: yapi.ReqKVItemSimple{Name: "X-Token", Desc: "a|b"}, Required: "1"}}
+	get.ReqQuery = []yapi.ReqKVItemDetail{{ReqKVItemSimple: yapi.ReqKVItemSimple{Name: "fields", Desc: "comma\nseparated"}, Required: "0"}}
+	get.ResBodyType, get.ResBodyIsJsonSchema = "json", true
+	get.ResBody = `{"type":"object","properties":{"id":{"type":"integer","description":"ID"},"roles":{"type":"array","items":{"type":"object","properties":{"code":{"type":"string","enum":["a","b"]}},"required":["code"]}}},"required":["id"]}`
+
+	var post yapi.InterfaceData
+	post.ID, post.Method, post.Path, post.Title = 2, "POST", "/users", "create user"
+	post.ReqBodyType, post.ReqBodyOther = "json", `{"name":"x"}`
+	post.ResBodyType, post.ResBody = "raw", "ok"
+
+	var form yapi.InterfaceData
+	form.ID, form.Method, form.Path, form.Title = 3, "POST", "/users", "create user from a form"
+	form.ReqBodyType = "form"
+	form.ReqBodyForm = []yapi.ReqKVItemDetail{{ReqKVItemSimple: yapi.ReqKVItemSimple{Name: "name"}, Type: "text", Required: "1"}}
+
+	return &snapshot.Snapshot{
+		Project: yapi.ProjectData{Name: "demo", Basepath: "/api"},
+		Categories: []snapshot.Category{
+			{CatData: yapi.CatData{Name: "Users", Desc: "user apis"}, Interfaces: []yapi.InterfaceData{get, post, form}},
+			{CatData: yapi.CatData{Name: "Empty"}},
+		},
+	}
+}
+
+func TestMarkdown_Render(t *testing.T) {
+	m, err := NewMarkdown(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := m.Render(&buf, NewDocument(testSnapshot())); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"# demo\n\nBase path: `/api`\n\n- [Users](#cat-users)\n- [Empty](#cat-empty)\n",
+		"## <a id=\"cat-users\"></a>Users\n\nuser apis\n\n| Interface | Method | Path |\n",
+		"| [get user](#get-users-id) | GET | `/users/{id}` |\n",
+		"| [create user from a form](#post-users-2) | POST | `/users` |\n",
+		"`GET /users/{id}` · status: done · tags: v1, public\n",
+		"| id | 42 | user id |\n",
+		"| X-Token |  | yes |  | a\\|b |\n",
+		"| fields |  | no |  | comma<br>separated |\n",
+		"| roles | array&lt;object&gt; | no |  |  |\n",
+		"| &emsp;└ code | string | yes |  | enum: \"a\", \"b\" |\n",
+		"Example:\n\n```json\n{\n  \"id\": 0,\n",
+		"#### Request body\n\n```json\n{\n  \"name\": \"x\"\n}\n```\n",
+		"#### Response\n\n```\nok\n```\n",
+		"#### Form\n\n| Name | Type | Required | Example | Description |\n| --- | --- | --- | --- | --- |\n| name | text | yes |  |  |\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output misses %q\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "\n\n\n") || !strings.HasSuffix(out, "## <a id=\"cat-empty\"></a>Empty\n") {
+		t.Errorf("unexpected spacing\n%s", out)
+	}
+}
+
+func TestMarkdown_Templates(t *testing.T) {
+	m, err := NewMarkdown(&MarkdownOptions{
+		Templates: []string{`{{define "interface"}}* {{shout .Title}} {{code .Path}}{{end}}`},
+		Funcs:     map[string]interface{}{"shout": strings.ToUpper},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := NewDocument(testSnapshot())
+	var buf bytes.Buffer
+	if err := m.Render(&buf, doc); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "\n\n* GET USER `/users/{id}`\n\n* CREATE USER `/users`\n") || strings.Contains(buf.String(), "#### ") {
+		t.Errorf("custom interface template not used\n%s", buf.String())
+	}
+
+	buf.Reset()
+	if err := m.RenderInterface(&buf, doc.Categories[0].Interfaces[1]); err != nil || buf.String() != "* CREATE USER `/users`" {
+		t.Errorf("RenderInterface = %q, %v", buf.String(), err)
+	}
+
+	if _, err := NewMarkdown(&MarkdownOptions{Templates: []string{`{{define "toc"}}{{.Nope`}}); err == nil {
+		t.Error("NewMarkdown accepted a broken template")
+	}
+}
+
+func TestSchemaRows(t *testing.T) {
+	s, err := yapi.ParseSchema(`{"type":"array","items":{"type":"object","properties":{"id":{"type":"integer","minimum":1}}}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows := SchemaRows(s)
+	if len(rows) != 2 {
+		t.Fatalf("rows = %+v", rows)
+	}
+	if r := rows[0]; r.Name != "(root)" || r.Type != "array<object>" || r.Depth != 0 {
+		t.Errorf("root row = %+v", r)
+	}
+	if r := rows[1]; r.Name != "id" || r.Path != "[].id" || r.Depth != 1 || r.Notes != "minimum: 1" {
+		t.Errorf("item row = %+v", r)
+	}
+}
@@ -0,0 +1,289 @@
+// Package docgen renders the interfaces of a YApi project as documentation.
+package docgen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	yapi "github.com/micrease/go-yapi"
+	"github.com/micrease/go-yapi/snapshot"
+)
+
+// Document is the view of a project passed to the templates.
+type Document struct {
+	Project    yapi.ProjectData
+	Categories []*Category
+}
+
+// Category is a category and its interfaces.
+type Category struct {
+	yapi.CatData
+	Anchor     string
+	Interfaces []*Interface
+}
+
+// Interface is an interface with its bodies prepared for rendering.
+type Interface struct {
+	yapi.InterfaceData
+	// Anchor is unique within the document, e.g. "get-users-id".
+	Anchor   string
+	Category string
+	Request  *Body
+	Response *Body
+}
+
+// Body is a request or response body. Rows is set for JSON Schema bodies,
+// Example for JSON bodies and Raw for any other text.
+type Body struct {
+	Type    string
+	Schema  *yapi.Schema
+	Rows    []SchemaRow
+	Example string
+	Raw     string
+}
+
+// Empty reports whether the body has nothing to show.
+func (b *Body) Empty() bool {
+	return b == nil || (len(b.Rows) == 0 && b.Example == "" && b.Raw == "")
+}
+
+// SchemaRow is a property of a schema body, flattened in document order.
+type SchemaRow struct {
+	// Name is the property name, Path its dotted path from the root with
+	// "[]" for array items, e.g. "roles[].code".
+	Name        string
+	Path        string
+	Depth       int
+	Type        string
+	Required    bool
+	Description string
+	// Notes lists the format, enum, default and range keywords.
+	Notes string
+}
+
+// SchemaNode is a schema row with the rows nested below it.
+type SchemaNode struct {
+	SchemaRow
+	Children []*SchemaNode
+}
+
+// Tree returns the schema rows of the body as a tree.
+func (b *Body) Tree() []*SchemaNode {
+	if b == nil {
+		return nil
+	}
+	return SchemaTree(b.Rows)
+}
+
+// SchemaTree nests flattened rows by their depth.
+func SchemaTree(rows []SchemaRow) []*SchemaNode {
+	var roots []*SchemaNode
+	var stack []*SchemaNode
+	for _, r := range rows {
+		n := &SchemaNode{SchemaRow: r}
+		if r.Depth < len(stack) {
+			stack = stack[:r.Depth]
+		}
+		if len(stack) == 0 {
+			roots = append(roots, n)
+		} else {
+			parent := stack[len(stack)-1]
+			parent.Children = append(parent.Children, n)
+		}
+		stack = append(stack, n)
+	}
+	return roots
+}
+
+// NewDocument prepares a snapshot for rendering.
+func NewDocument(s *snapshot.Snapshot) *Document {
+	doc := &Document{Project: s.Project}
+	anchors := map[string]bool{}
+	for _, cat := range s.Categories {
+		c := &Category{CatData: cat.CatData, Anchor: uniqueAnchor("cat-"+anchor(cat.Name), anchors)}
+		for i := range cat.Interfaces {
+			c.Interfaces = append(c.Interfaces, NewInterface(&cat.Interfaces[i], cat.Name, anchors))
+		}
+		doc.Categories = append(doc.Categories, c)
+	}
+	return doc
+}
+
+// NewInterface prepares an interface for rendering. anchors collects the
+// anchors in use and may be nil.
+func NewInterface(d *yapi.InterfaceData, category string, anchors map[string]bool) *Interface {
+	if anchors == nil {
+		anchors = map[string]bool{}
+	}
+	i := &Interface{InterfaceData: *d, Category: category}
+	i.Anchor = uniqueAnchor(anchor(d.Method+" "+d.Path), anchors)
+	if d.ReqBodyType != "form" {
+		i.Request = newBody(d.ReqBodyType, d.ReqBodyIsJsonSchema, d.ReqBodyOther)
+	}
+	i.Response = newBody(d.ResBodyType, d.ResBodyIsJsonSchema, d.ResBody)
+	return i
+}
+
+func newBody(typ string, isSchema bool, text string) *Body {
+	b := &Body{Type: typ}
+	if strings.TrimSpace(text) == "" {
+		return b
+	}
+	if isSchema {
+		if s, err := yapi.ParseSchema(text); err == nil && s != nil {
+			b.Schema = s
+			b.Rows = SchemaRows(s)
+			b.Example = indentJSON(s.Example())
+			return b
+		}
+	}
+	var v interface{}
+	if json.Unmarshal([]byte(text), &v) == nil {
+		b.Example = indentJSON(v)
+		return b
+	}
+	b.Raw = text
+	return b
+}
+
+func indentJSON(v interface{}) string {
+	buf := new(bytes.Buffer)
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return ""
+	}
+	return strings.TrimRight(buf.String(), "\n")
+}
+
+// SchemaRows flattens the properties of a schema. A root that is not an
+// object is listed as a "(root)" row.
+func SchemaRows(s *yapi.Schema) []SchemaRow {
+	var rows []SchemaRow
+	if isObject(s) {
+		properties(s, "", 0, &rows)
+		return rows
+	}
+	rows = append(rows, row("(root)", "", 0, s, false))
+	children(s, "", 1, &rows)
+	return rows
+}
+
+func properties(s *yapi.Schema, prefix string, depth int, rows *[]SchemaRow) {
+	for _, name := range s.PropertyNames() {
+		prop := s.Properties[name]
+		path := name
+		if prefix != "" {
+			path = prefix + "." + name
+		}
+		*rows = append(*rows, row(name, path, depth, prop, s.IsRequired(name)))
+		children(prop, path, depth+1, rows)
+	}
+}
+
+// children lists the properties of an object, or of the items of an array.
+func children(s *yapi.Schema, path string, depth int, rows *[]SchemaRow) {
+	switch {
+	case s == nil:
+	case isObject(s):
+		properties(s, path, depth, rows)
+	case s.Items != nil:
+		children(s.Items, path+"[]", depth, rows)
+	}
+}
+
+func isObject(s *yapi.Schema) bool {
+	return s != nil && (len(s.Properties) > 0 || s.Type == "object")
+}
+
+func row(name, path string, depth int, s *yapi.Schema, required bool) SchemaRow {
+	r := SchemaRow{Name: name, Path: path, Depth: depth, Type: typeName(s), Required: required, Description: s.Description}
+	if r.Description == "" {
+		r.Description = s.Title
+	}
+	var notes []string
+	if s.Format != "" {
+		notes = append(notes, "format: "+s.Format)
+	}
+	if len(s.Enum) > 0 {
+		var values []string
+		for _, v := range s.Enum {
+			values = append(values, compactJSON(v))
+		}
+		notes = append(notes, "enum: "+strings.Join(values, ", "))
+	}
+	if s.Default != nil {
+		notes = append(notes, "default: "+compactJSON(s.Default))
+	}
+	if s.Pattern != "" {
+		notes = append(notes, "pattern: "+s.Pattern)
+	}
+	if s.Minimum != nil {
+		notes = append(notes, fmt.Sprintf("minimum: %v", *s.Minimum))
+	}
+	if s.Maximum != nil {
+		notes = append(notes, fmt.Sprintf("maximum: %v", *s.Maximum))
+	}
+	if s.MinLength != nil {
+		notes = append(notes, fmt.Sprintf("minLength: %d", *s.MinLength))
+	}
+	if s.MaxLength != nil {
+		notes = append(notes, fmt.Sprintf("maxLength: %d", *s.MaxLength))
+	}
+	if s.MinItems != nil {
+		notes = append(notes, fmt.Sprintf("minItems: %d", *s.MinItems))
+	}
+	if s.MaxItems != nil {
+		notes = append(notes, fmt.Sprintf("maxItems: %d", *s.MaxItems))
+	}
+	r.Notes = strings.Join(notes, "; ")
+	return r
+}
+
+// typeName describes the type of a node, e.g. "string|null" or "array<object>".
+func typeName(s *yapi.Schema) string {
+	if s == nil {
+		return ""
+	}
+	t := strings.Join(s.Types(), "|")
+	if t == "" && isObject(s) {
+		t = "object"
+	}
+	if t == "array" && s.Items != nil {
+		if item := typeName(s.Items); item != "" {
+			t += "<" + item + ">"
+		}
+	}
+	return t
+}
+
+func compactJSON(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+var nonAnchor = regexp.MustCompile(`[^\p{L}\p{N}]+`)
+
+// anchor turns text into a lower case, dash separated identifier.
+func anchor(text string) string {
+	return strings.Trim(nonAnchor.ReplaceAllString(strings.ToLower(text), "-"), "-")
+}
+
+func uniqueAnchor(a string, used map[string]bool) string {
+	if a == "" {
+		a = "section"
+	}
+	unique := a
+	for n := 2; used[unique]; n++ {
+		unique = fmt.Sprintf("%s-%d", a, n)
+	}
+	used[unique] = true
+	return unique
+}
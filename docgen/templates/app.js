@@ -0,0 +1,76 @@
+(function () {
+  var body = document.body;
+  var multiPage = body.getAttribute("data-multipage") === "true";
+  var basepath = body.getAttribute("data-basepath") || "";
+
+  function href(entry) {
+    return multiPage ? entry.anchor + ".html" : "#" + entry.anchor;
+  }
+
+  // Environment selector: prefix the interface paths with the chosen domain.
+  var env = document.getElementById("env");
+  function showURLs() {
+    var domain = env ? env.value.replace(/\/+$/, "") : "";
+    var urls = document.querySelectorAll(".url");
+    for (var i = 0; i < urls.length; i++) {
+      var path = urls[i].getAttribute("data-path");
+      urls[i].textContent = domain ? domain + basepath + path : path;
+    }
+  }
+  if (env) {
+    try {
+      var saved = localStorage.getItem("yapi-env");
+      for (var i = 0; i < env.options.length; i++) {
+        if (env.options[i].value === saved) env.value = saved;
+      }
+    } catch (e) {}
+    env.addEventListener("change", function () {
+      try { localStorage.setItem("yapi-env", env.value); } catch (e) {}
+      showURLs();
+    });
+    showURLs();
+  }
+
+  // Mark the current interface in the sidebar.
+  var current = multiPage ? location.pathname.replace(/^.*\//, "").replace(/\.html$/, "") : "";
+  var links = document.querySelectorAll(".sidebar li a");
+  for (var j = 0; j < links.length; j++) {
+    if (links[j].getAttribute("data-anchor") === current) links[j].className = "current";
+  }
+
+  // Search: every word of the query must match the title, method, path,
+  // category or field names of an interface.
+  var search = document.getElementById("search");
+  var results = document.getElementById("results");
+  var content = document.getElementById("content");
+  var index = window.searchIndex || [];
+  search.addEventListener("input", function () {
+    var words = search.value.toLowerCase().split(/\s+/).filter(Boolean);
+    results.innerHTML = "";
+    results.hidden = !words.length;
+    content.hidden = !!words.length;
+    if (!words.length) return;
+    index.forEach(function (entry) {
+      var text = [entry.title, entry.method, entry.path, entry.category, entry.text].join(" ").toLowerCase();
+      for (var k = 0; k < words.length; k++) {
+        if (text.indexOf(words[k]) < 0) return;
+      }
+      var li = document.createElement("li");
+      var a = document.createElement("a");
+      a.href = href(entry);
+      a.textContent = entry.method + " " + entry.path + " " + entry.title;
+      a.addEventListener("click", function () {
+        search.value = "";
+        results.hidden = true;
+        content.hidden = false;
+      });
+      var category = document.createElement("span");
+      category.className = "category";
+      category.textContent = entry.category;
+      li.appendChild(a);
+      li.appendChild(category);
+      results.appendChild(li);
+    });
+    if (!results.firstChild) results.textContent = "No interfaces found.";
+  });
+})();
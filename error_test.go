@@ -1,4 +1,4 @@
-package yapi
+package yapi_test
 
 import (
 	"errors"
@@ -6,8 +6,21 @@ import (
 	"net/http"
 	"strings"
 	"testing"
+
+	yapi "github.com/micrease/go-yapi"
 )
 
+// rawResponse sends a GET request for path to the test server and returns
+// the response unread, as NewServerError expects it.
+func rawResponse(t *testing.T, path string) *http.Response {
+	req, _ := testClient.NewRequest("GET", path, nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp
+}
+
 func TestError_NewServerError(t *testing.T) {
 	setup()
 	defer teardown()
@@ -17,11 +30,10 @@ func TestError_NewServerError(t *testing.T) {
 		fmt.Fprint(w, `{"errorMessages":["Issue does not exist or you do not have permission to see it."],"errors":{}}`)
 	})
 
-	req, _ := testClient.NewRequest("GET", "/", nil)
-	resp, _ := testClient.Do(req, nil)
+	resp := rawResponse(t, "/")
 
-	err := NewServerError(resp, errors.New("Original http error"))
-	if err, ok := err.(*Error); !ok {
+	err := yapi.NewServerError(resp, errors.New("Original http error"))
+	if err, ok := err.(*yapi.Error); !ok {
 		t.Errorf("Expected Server Error. Got %s", err.Error())
 	}
 
@@ -31,7 +43,7 @@ func TestError_NewServerError(t *testing.T) {
 }
 
 func TestError_NoResponse(t *testing.T) {
-	err := NewServerError(nil, errors.New("Original http error"))
+	err := yapi.NewServerError(nil, errors.New("Original http error"))
 
 	msg := err.Error()
 	if !strings.Contains(msg, "Original http error") {
@@ -51,10 +63,9 @@ func TestError_NoJSON(t *testing.T) {
 		fmt.Fprint(w, `Original message body`)
 	})
 
-	req, _ := testClient.NewRequest("GET", "/", nil)
-	resp, _ := testClient.Do(req, nil)
+	resp := rawResponse(t, "/")
 
-	err := NewServerError(resp, errors.New("Original http error"))
+	err := yapi.NewServerError(resp, errors.New("Original http error"))
 	msg := err.Error()
 
 	if !strings.Contains(msg, "200 OK: Original message body: Original http error") {
@@ -71,10 +82,9 @@ func TestError_Unauthorized_NilError(t *testing.T) {
 		fmt.Fprint(w, `User is not authorized`)
 	})
 
-	req, _ := testClient.NewRequest("GET", "/", nil)
-	resp, _ := testClient.Do(req, nil)
+	resp := rawResponse(t, "/")
 
-	err := NewServerError(resp, nil)
+	err := yapi.NewServerError(resp, nil)
 	msg := err.Error()
 	if !strings.Contains(msg, "401 Unauthorized:User is not authorized") {
 		t.Errorf("Expected Unauthorized HTTP status: Got\n%s\n", msg)
@@ -90,10 +100,9 @@ func TestError_BadJSON(t *testing.T) {
 		fmt.Fprint(w, `<html>Not JSON</html>`)
 	})
 
-	req, _ := testClient.NewRequest("GET", "/", nil)
-	resp, _ := testClient.Do(req, nil)
+	resp := rawResponse(t, "/")
 
-	err := NewServerError(resp, errors.New("Original http error"))
+	err := yapi.NewServerError(resp, errors.New("Original http error"))
 	msg := err.Error()
 
 	if !strings.Contains(msg, "Could not parse JSON") {
@@ -108,7 +117,7 @@ func TestError_NilOriginalMessage(t *testing.T) {
 		}
 	}()
 
-	msgErr := &Error{
+	msgErr := &yapi.Error{
 		HTTPError:     nil,
 		ErrorMessages: []string{"Issue does not exist"},
 		Errors: map[string]string{
@@ -127,7 +136,7 @@ func TestError_NilOriginalMessageLongError(t *testing.T) {
 		}
 	}()
 
-	msgErr := &Error{
+	msgErr := &yapi.Error{
 		HTTPError:     nil,
 		ErrorMessages: []string{"Issue does not exist"},
 		Errors: map[string]string{
@@ -140,7 +149,7 @@ func TestError_NilOriginalMessageLongError(t *testing.T) {
 }
 
 func TestError_ShortMessage(t *testing.T) {
-	msgErr := &Error{
+	msgErr := &yapi.Error{
 		HTTPError:     errors.New("Original http error"),
 		ErrorMessages: []string{"Issue does not exist"},
 		Errors: map[string]string{
@@ -149,7 +158,7 @@ func TestError_ShortMessage(t *testing.T) {
 		},
 	}
 
-	mapErr := &Error{
+	mapErr := &yapi.Error{
 		HTTPError:     errors.New("Original http error"),
 		ErrorMessages: nil,
 		Errors: map[string]string{
@@ -158,7 +167,7 @@ func TestError_ShortMessage(t *testing.T) {
 		},
 	}
 
-	noErr := &Error{
+	noErr := &yapi.Error{
 		HTTPError:     errors.New("Original http error"),
 		ErrorMessages: nil,
 		Errors:        nil,
@@ -181,7 +190,7 @@ func TestError_ShortMessage(t *testing.T) {
 }
 
 func TestError_LongMessage(t *testing.T) {
-	longError := &Error{
+	longError := &yapi.Error{
 		HTTPError:     errors.New("Original http error"),
 		ErrorMessages: []string{"Issue does not exist."},
 		Errors: map[string]string{
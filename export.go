@@ -0,0 +1,51 @@
+package yapi
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+)
+
+// ExportCat is a category as written by the YApi "json" data export.
+type ExportCat struct {
+	Index   int             `json:"index" structs:"index"`
+	Name    string          `json:"name" structs:"name"`
+	Desc    string          `json:"desc" structs:"desc"`
+	AddTime int             `json:"add_time" structs:"add_time"`
+	UpTime  int             `json:"up_time" structs:"up_time"`
+	List    []InterfaceData `json:"list" structs:"list"`
+}
+
+// ExportData is the content of a YApi "json" data export file.
+type ExportData []ExportCat
+
+// Interfaces returns every interface of the export in category order.
+func (e ExportData) Interfaces() []InterfaceData {
+	var all []InterfaceData
+	for _, cat := range e {
+		all = append(all, cat.List...)
+	}
+	return all
+}
+
+// ReadExport decodes a YApi "json" data export.
+func ReadExport(r io.Reader) (ExportData, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var export ExportData
+	err = json.Unmarshal(data, &export)
+	return export, err
+}
+
+// ReadExportFile decodes the YApi "json" data export stored at path.
+func ReadExportFile(path string) (ExportData, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadExport(f)
+}
@@ -3,10 +3,12 @@ module github.com/micrease/go-yapi
 go 1.16
 
 require (
+	github.com/BurntSushi/toml v1.2.1
 	github.com/google/go-querystring v1.0.0
 	github.com/jinzhu/copier v0.0.0-20190924061706-b57f9002281a
 	github.com/pkg/errors v0.8.1
 	golang.org/x/crypto v0.0.0-20190820162420-60c769a6c586
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require golang.org/x/sys v0.0.0-20220503163025-988cb79eb6c6 // indirect
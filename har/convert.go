@@ -0,0 +1,388 @@
+package har
+
+import (
+	"encoding/json"
+	"net/url"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	yapi "github.com/micrease/go-yapi"
+)
+
+// Options controls the conversion of a recording.
+type Options struct {
+	// Hosts keeps the entries sent to these hosts only, all when empty.
+	Hosts []string
+	// Basepath is removed from the start of the paths, as YApi stores it on
+	// the project.
+	Basepath string
+	// ProjectID and CatID are set on every interface.
+	ProjectID int
+	CatID     int
+	// IgnoreHeaders are the request headers left out, matched case
+	// insensitively; a trailing "*" matches a prefix. Nil uses
+	// DefaultIgnoreHeaders.
+	IgnoreHeaders []string
+	// IncludeStatic keeps the entries of scripts, style sheets, images and
+	// fonts, which are skipped by default.
+	IncludeStatic bool
+}
+
+// DefaultIgnoreHeaders are the browser and transport headers that say
+// nothing about an interface.
+var DefaultIgnoreHeaders = []string{
+	"Accept-Encoding", "Accept-Language", "Cache-Control", "Connection", "Content-Length",
+	"Cookie", "DNT", "Host", "If-*", "Origin", "Pragma", "Referer", "Sec-*", "TE",
+	"Upgrade-Insecure-Requests", "User-Agent", "X-Requested-With",
+}
+
+// SecretHeaders are the request headers carrying credentials, matched like
+// IgnoreHeaders. Unless ignored they are kept without their recorded value,
+// so the documentation shows they are needed without leaking them.
+var SecretHeaders = []string{
+	"Authorization", "Proxy-Authorization", "X-Api-Key", "Api-Key", "X-Auth-*",
+	"X-Access-Token", "X-Csrf-Token", "X-Xsrf-Token", "X-Amz-Security-Token",
+}
+
+var staticExtensions = map[string]bool{
+	".css": true, ".js": true, ".map": true, ".html": true, ".htm": true,
+	".png": true, ".jpg": true, ".jpeg": true, ".gif": true, ".svg": true, ".ico": true, ".webp": true,
+	".woff": true, ".woff2": true, ".ttf": true, ".eot": true,
+}
+
+// Interfaces converts the entries of a recording into interfaces, one per
+// method and normalized path in order of first appearance, ready to be sent
+// with InterfaceService.AddOrUpdate. See NormalizePath for the path
+// parameters. Parameters, headers and form fields present in every request
+// of an interface are required. JSON bodies get a schema inferred from all
+// their samples; responses are taken from the 2xx entries when there are
+// any.
+func Interfaces(h *HAR, opts *Options) []yapi.InterfaceData {
+	if opts == nil {
+		opts = &Options{}
+	}
+	ignore := opts.IgnoreHeaders
+	if ignore == nil {
+		ignore = DefaultIgnoreHeaders
+	}
+	var groups []*group
+	byKey := map[string]*group{}
+	for i := range h.Log.Entries {
+		e := &h.Log.Entries[i]
+		u, err := url.Parse(e.Request.URL)
+		if err != nil || !keepHost(u.Hostname(), opts.Hosts) {
+			continue
+		}
+		if !opts.IncludeStatic && staticExtensions[strings.ToLower(path.Ext(u.Path))] {
+			continue
+		}
+		p, params := NormalizePath(trimBasepath(u.Path, opts.Basepath))
+		method := strings.ToUpper(e.Request.Method)
+		key := method + " " + p
+		g, ok := byKey[key]
+		if !ok {
+			g = &group{method: method, path: p, params: params}
+			byKey[key] = g
+			groups = append(groups, g)
+		}
+		g.entries = append(g.entries, e)
+		g.urls = append(g.urls, u)
+	}
+
+	list := make([]yapi.InterfaceData, 0, len(groups))
+	for _, g := range groups {
+		d := g.interfaceData(ignore)
+		d.ProjectID, d.CatID = opts.ProjectID, opts.CatID
+		list = append(list, d)
+	}
+	return list
+}
+
+var (
+	numericSegment = regexp.MustCompile(`^\d+$`)
+	uuidSegment    = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	hexSegment     = regexp.MustCompile(`^[0-9a-fA-F]{24,}$`)
+)
+
+// NormalizePath replaces the numeric, UUID and long hexadecimal (object ID
+// or hash) segments of a path with the parameters {id}, {id2}... and returns
+// them with the replaced values as examples.
+func NormalizePath(p string) (string, []yapi.ReqKVItemSimple) {
+	var params []yapi.ReqKVItemSimple
+	segments := strings.Split(p, "/")
+	for i, seg := range segments {
+		if !numericSegment.MatchString(seg) && !uuidSegment.MatchString(seg) && !hexSegment.MatchString(seg) {
+			continue
+		}
+		name := "id"
+		if len(params) > 0 {
+			name += strconv.Itoa(len(params) + 1)
+		}
+		params = append(params, yapi.ReqKVItemSimple{Name: name, Example: seg})
+		segments[i] = "{" + name + "}"
+	}
+	p = strings.Join(segments, "/")
+	if p == "" {
+		p = "/"
+	}
+	return p, params
+}
+
+func trimBasepath(p, basepath string) string {
+	base := strings.TrimRight(basepath, "/")
+	if base == "" || (p != base && !strings.HasPrefix(p, base+"/")) {
+		return p
+	}
+	return "/" + strings.TrimLeft(p[len(base):], "/")
+}
+
+func keepHost(host string, hosts []string) bool {
+	if len(hosts) == 0 {
+		return true
+	}
+	for _, h := range hosts {
+		if strings.EqualFold(h, host) {
+			return true
+		}
+	}
+	return false
+}
+
+func ignored(name string, patterns []string) bool {
+	if strings.HasPrefix(name, ":") {
+		return true
+	}
+	for _, p := range patterns {
+		if strings.HasSuffix(p, "*") {
+			prefix := strings.TrimSuffix(p, "*")
+			if len(name) >= len(prefix) && strings.EqualFold(name[:len(prefix)], prefix) {
+				return true
+			}
+		} else if strings.EqualFold(name, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// group holds the entries of one interface.
+type group struct {
+	method  string
+	path    string
+	params  []yapi.ReqKVItemSimple
+	entries []*Entry
+	urls    []*url.URL
+}
+
+func (g *group) interfaceData(ignoreHeaders []string) yapi.InterfaceData {
+	var d yapi.InterfaceData
+	d.Method, d.Path = g.method, g.path
+	d.Title = g.method + " " + g.path
+	d.Status = "undone"
+	d.ReqParams = g.params
+
+	query, headers := newFields(), newFields()
+	for i, e := range g.entries {
+		query.sample()
+		for _, q := range queryPairs(&e.Request, g.urls[i]) {
+			query.add(q.Name, q.Value, "")
+		}
+		headers.sample()
+		for _, h := range e.Request.Headers {
+			if ignored(h.Name, ignoreHeaders) {
+				continue
+			}
+			value := h.Value
+			if ignored(h.Name, SecretHeaders) {
+				value = ""
+			}
+			headers.add(h.Name, value, "")
+		}
+	}
+	d.ReqQuery = query.items(false)
+	d.ReqHeaders = headers.items(true)
+
+	g.requestBody(&d)
+	g.responseBody(&d)
+	return d
+}
+
+func (g *group) requestBody(d *yapi.InterfaceData) {
+	var jsonSamples [][]byte
+	form := newFields()
+	var raw string
+	for _, e := range g.entries {
+		post := e.Request.PostData
+		if post == nil {
+			continue
+		}
+		switch mime := strings.ToLower(post.MimeType); {
+		case strings.Contains(mime, "json"):
+			if isJSON(post.Text) {
+				jsonSamples = append(jsonSamples, []byte(post.Text))
+			}
+		case strings.HasPrefix(mime, "application/x-www-form-urlencoded"), strings.HasPrefix(mime, "multipart/form-data"):
+			form.sample()
+			params := post.Params
+			if len(params) == 0 {
+				for _, p := range queryPairs(&Request{}, &url.URL{RawQuery: post.Text}) {
+					params = append(params, Param{Name: p.Name, Value: p.Value})
+				}
+			}
+			for _, p := range params {
+				typ := "text"
+				if p.FileName != "" {
+					typ = "file"
+				}
+				form.add(p.Name, p.Value, typ)
+			}
+		default:
+			if raw == "" {
+				raw = post.Text
+			}
+		}
+	}
+	switch {
+	case len(jsonSamples) > 0:
+		if s, err := yapi.InferSchemaJSON(jsonSamples...); err == nil {
+			d.ReqBodyType = "json"
+			d.SetReqBodySchema(s)
+		}
+	case form.samples > 0:
+		d.ReqBodyType = "form"
+		d.ReqBodyForm = form.items(false)
+	case raw != "":
+		d.ReqBodyType = "raw"
+		d.ReqBodyOther = raw
+	}
+}
+
+func (g *group) responseBody(d *yapi.InterfaceData) {
+	entries := g.entries
+	var ok []*Entry
+	for _, e := range entries {
+		if e.Response.Status >= 200 && e.Response.Status < 300 {
+			ok = append(ok, e)
+		}
+	}
+	if len(ok) > 0 {
+		entries = ok
+	}
+	var jsonSamples [][]byte
+	var raw string
+	for _, e := range entries {
+		body := e.Response.Content.Body()
+		if strings.Contains(strings.ToLower(e.Response.Content.MimeType), "json") && isJSON(body) {
+			jsonSamples = append(jsonSamples, []byte(body))
+		} else if raw == "" {
+			raw = body
+		}
+	}
+	switch {
+	case len(jsonSamples) > 0:
+		if s, err := yapi.InferSchemaJSON(jsonSamples...); err == nil {
+			d.ResBodyType = "json"
+			d.SetResBodySchema(s)
+		}
+	case raw != "":
+		d.ResBodyType = "raw"
+		d.ResBody = raw
+	}
+}
+
+// fields collects the query parameters, headers or form fields of the
+// samples of an interface.
+type fields struct {
+	samples int
+	order   []string
+	byName  map[string]*field
+}
+
+type field struct {
+	name   string
+	typ    string
+	value  string
+	count  int
+	last   int
+	varies bool
+}
+
+func newFields() *fields {
+	return &fields{byName: map[string]*field{}}
+}
+
+// sample starts a new sample.
+func (f *fields) sample() {
+	f.samples++
+}
+
+func (f *fields) add(name, value, typ string) {
+	key := strings.ToLower(name)
+	fd, ok := f.byName[key]
+	if !ok {
+		fd = &field{name: name, typ: typ, value: value}
+		f.byName[key] = fd
+		f.order = append(f.order, key)
+	}
+	if fd.value != value {
+		fd.varies = true
+	}
+	if typ == "file" {
+		fd.typ = typ
+	}
+	if fd.last != f.samples {
+		fd.last = f.samples
+		fd.count++
+	}
+}
+
+// items returns the fields in order of first appearance. A field seen in
+// every sample is required. With fixed, a field that always had the same
+// value gets it as its value rather than as its example.
+func (f *fields) items(fixed bool) []yapi.ReqKVItemDetail {
+	var list []yapi.ReqKVItemDetail
+	for _, key := range f.order {
+		fd := f.byName[key]
+		item := yapi.ReqKVItemDetail{Type: fd.typ, Required: "0"}
+		item.Name = fd.name
+		if fd.count >= f.samples {
+			item.Required = "1"
+		}
+		if fixed && !fd.varies {
+			item.Value = fd.value
+		} else if fd.typ != "file" {
+			item.Example = fd.value
+		}
+		list = append(list, item)
+	}
+	return list
+}
+
+// isJSON reports whether s is a JSON object or array.
+func isJSON(s string) bool {
+	s = strings.TrimSpace(s)
+	return s != "" && (s[0] == '{' || s[0] == '[') && json.Valid([]byte(s))
+}
+
+// queryPairs returns the query parameters of a request in order.
+func queryPairs(r *Request, u *url.URL) []NameValue {
+	if len(r.QueryString) > 0 {
+		return r.QueryString
+	}
+	var pairs []NameValue
+	for _, pair := range strings.Split(u.RawQuery, "&") {
+		if pair == "" {
+			continue
+		}
+		kv := strings.SplitN(pair, "=", 2)
+		name, _ := url.QueryUnescape(kv[0])
+		var value string
+		if len(kv) == 2 {
+			value, _ = url.QueryUnescape(kv[1])
+		}
+		pairs = append(pairs, NameValue{Name: name, Value: value})
+	}
+	return pairs
+}
@@ -0,0 +1,109 @@
+// Package har turns HTTP Archive (HAR 1.2) recordings from browsers or
+// proxies into YApi interfaces, to bootstrap the documentation of services
+// that have no specification.
+package har
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+)
+
+// HAR is an HTTP Archive. Only the parts used by the conversion are modeled.
+type HAR struct {
+	Log Log `json:"log"`
+}
+
+// Log holds the recorded entries.
+type Log struct {
+	Version string  `json:"version"`
+	Entries []Entry `json:"entries"`
+}
+
+// Entry is a recorded exchange.
+type Entry struct {
+	StartedDateTime string   `json:"startedDateTime"`
+	Request         Request  `json:"request"`
+	Response        Response `json:"response"`
+}
+
+// Request is a recorded request.
+type Request struct {
+	Method      string      `json:"method"`
+	URL         string      `json:"url"`
+	Headers     []NameValue `json:"headers"`
+	QueryString []NameValue `json:"queryString"`
+	PostData    *PostData   `json:"postData,omitempty"`
+}
+
+// Response is a recorded response.
+type Response struct {
+	Status  int         `json:"status"`
+	Headers []NameValue `json:"headers"`
+	Content Content     `json:"content"`
+}
+
+// NameValue is a header or query parameter.
+type NameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// PostData is a request body. Params is set for form bodies.
+type PostData struct {
+	MimeType string  `json:"mimeType"`
+	Text     string  `json:"text"`
+	Params   []Param `json:"params,omitempty"`
+}
+
+// Param is a form field.
+type Param struct {
+	Name        string `json:"name"`
+	Value       string `json:"value,omitempty"`
+	FileName    string `json:"fileName,omitempty"`
+	ContentType string `json:"contentType,omitempty"`
+}
+
+// Content is a response body, base64 encoded when Encoding is "base64".
+type Content struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+	Encoding string `json:"encoding,omitempty"`
+}
+
+// Body returns the decoded text of the content.
+func (c *Content) Body() string {
+	if c.Encoding != "base64" {
+		return c.Text
+	}
+	data, err := base64.StdEncoding.DecodeString(c.Text)
+	if err != nil {
+		return c.Text
+	}
+	return string(data)
+}
+
+// Read decodes a HAR document.
+func Read(r io.Reader) (*HAR, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	h := new(HAR)
+	if err := json.Unmarshal(data, h); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// ReadFile decodes the HAR document stored at path.
+func ReadFile(path string) (*HAR, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Read(f)
+}
@@ -0,0 +1,142 @@
+package har
+
+import (
+	"encoding/base64"
+	"reflect"
+	"strings"
+	"testing"
+
+	yapi "github.com/micrease/go-yapi"
+)
+
+var testHAR = `{"log": {"version": "1.2", "entries": [
+  {"request": {"method": "GET", "url": "https://shop.example.com/api/users/42?fields=name&page=1",
+    "headers": [{"name": ":authority", "value": "shop.example.com"}, {"name": "User-Agent", "value": "x"}, {"name": "Accept", "value": "application/json"}, {"name": "X-Trace", "value": "a"}],
+    "queryString": [{"name": "fields", "value": "name"}, {"name": "page", "value": "1"}]},
+   "response": {"status": 200, "content": {"mimeType": "application/json", "text": "{\"id\": 42, \"name\": \"ann\", \"email\": null}"}}},
+  {"request": {"method": "get", "url": "https://shop.example.com/api/users/7?fields=name",
+    "headers": [{"name": "accept", "value": "application/json"}, {"name": "X-Trace", "value": "b"}]},
+   "response": {"status": 200, "content": {"mimeType": "application/json; charset=utf-8", "encoding": "base64", "text": "` + base64.StdEncoding.EncodeToString([]byte(`{"id": 7, "name": "bob", "email": "b@example.com", "admin": true}`)) + `"}}},
+  {"request": {"method": "GET", "url": "https://shop.example.com/api/users/0b5e1e7a-6f4c-4f5e-9d2a-3c1d2e4f5a6b?fields=id"},
+   "response": {"status": 404, "content": {"mimeType": "application/json", "text": "{\"error\": \"not found\"}"}}},
+  {"request": {"method": "POST", "url": "https://shop.example.com/api/orders/5f1d7c2e9b1e8a3c4d5e6f70/items",
+    "postData": {"mimeType": "application/json", "text": "{\"sku\": \"a\", \"qty\": 1}"}},
+   "response": {"status": 201, "content": {"mimeType": "text/plain", "text": "created"}}},
+  {"request": {"method": "POST", "url": "https://shop.example.com/api/orders/5f1d7c2e9b1e8a3c4d5e6f71/items",
+    "postData": {"mimeType": "application/json", "text": "{\"sku\": \"b\", \"qty\": 2, \"note\": \"gift\"}"}},
+   "response": {"status": 500, "content": {"mimeType": "text/plain", "text": "boom"}}},
+  {"request": {"method": "POST", "url": "https://shop.example.com/api/login",
+    "postData": {"mimeType": "application/x-www-form-urlencoded", "text": "user=ann&password=secret"}},
+   "response": {"status": 302, "content": {"mimeType": "", "text": ""}}},
+  {"request": {"method": "POST", "url": "https://shop.example.com/api/avatar",
+    "postData": {"mimeType": "multipart/form-data; boundary=x", "params": [{"name": "file", "fileName": "me.png", "contentType": "image/png"}, {"name": "note", "value": "hi"}]}},
+   "response": {"status": 200, "content": {"mimeType": "application/json", "text": "[]"}}},
+  {"request": {"method": "GET", "url": "https://shop.example.com/static/app.js"}, "response": {"status": 200, "content": {}}},
+  {"request": {"method": "GET", "url": "https://cdn.example.com/api/ping"}, "response": {"status": 200, "content": {}}}
+]}}`
+
+func TestInterfaces(t *testing.T) {
+	h, err := Read(strings.NewReader(testHAR))
+	if err != nil {
+		t.Fatal(err)
+	}
+	list := Interfaces(h, &Options{Hosts: []string{"shop.example.com"}, Basepath: "/api", ProjectID: 11, CatID: 3})
+
+	var keys []string
+	for _, d := range list {
+		keys = append(keys, d.Method+" "+d.Path)
+	}
+	want := []string{"GET /users/{id}", "POST /orders/{id}/items", "POST /login", "POST /avatar"}
+	if !reflect.DeepEqual(keys, want) {
+		t.Fatalf("interfaces = %q, want %q", keys, want)
+	}
+
+	get := list[0]
+	if get.ProjectID != 11 || get.CatID != 3 || get.Title != "GET /users/{id}" || get.Status != "undone" {
+		t.Errorf("get = %+v", get)
+	}
+	if want := []yapi.ReqKVItemSimple{{Name: "id", Example: "42"}}; !reflect.DeepEqual(get.ReqParams, want) {
+		t.Errorf("params = %+v", get.ReqParams)
+	}
+	if q := get.ReqQuery; len(q) != 2 || q[0].Name != "fields" || q[0].Required != "1" || q[1].Name != "page" || q[1].Required != "0" || q[1].Example != "1" {
+		t.Errorf("query = %+v", q)
+	}
+	if h := get.ReqHeaders; len(h) != 2 || h[0].Name != "Accept" || h[0].Value != "application/json" || h[1].Value != "" || h[1].Example != "a" {
+		t.Errorf("headers = %+v", h)
+	}
+	res, err := get.ResBodySchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(res.PropertyNames(), []string{"id", "name", "email", "admin"}) || !reflect.DeepEqual(res.Required, []string{"id", "name", "email"}) {
+		t.Errorf("response schema = %s", res)
+	}
+	if types := res.Properties["email"].Types(); !reflect.DeepEqual(types, []string{"string", "null"}) {
+		t.Errorf("email types = %q", types)
+	}
+
+	items := list[1]
+	req, err := items.ReqBodySchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if items.ReqBodyType != "json" || !reflect.DeepEqual(req.Required, []string{"sku", "qty"}) || req.Properties["note"] == nil {
+		t.Errorf("request schema = %s", req)
+	}
+	if items.ResBodyType != "raw" || items.ResBody != "created" {
+		t.Errorf("response = %s %q", items.ResBodyType, items.ResBody)
+	}
+
+	login := list[2]
+	if f := login.ReqBodyForm; login.ReqBodyType != "form" || len(f) != 2 || f[0].Name != "user" || f[0].Example != "ann" || f[0].Required != "1" {
+		t.Errorf("login form = %+v", f)
+	}
+	if f := list[3].ReqBodyForm; len(f) != 2 || f[0].Type != "file" || f[1].Type != "text" || f[1].Example != "hi" {
+		t.Errorf("avatar form = %+v", f)
+	}
+}
+
+func TestInterfaces_SecretHeaders(t *testing.T) {
+	h, err := Read(strings.NewReader(`{"log": {"version": "1.2", "entries": [
+  {"request": {"method": "GET", "url": "https://shop.example.com/api/me",
+    "headers": [{"name": "Authorization", "value": "Bearer s3cret"}, {"name": "X-Auth-Token", "value": "t1"}, {"name": "X-Api-Key", "value": "k1"}, {"name": "Accept", "value": "application/json"}]},
+   "response": {"status": 200, "content": {}}},
+  {"request": {"method": "GET", "url": "https://shop.example.com/api/me",
+    "headers": [{"name": "Authorization", "value": "Bearer s3cret"}, {"name": "X-Auth-Token", "value": "t2"}, {"name": "X-Api-Key", "value": "k1"}, {"name": "Accept", "value": "application/json"}]},
+   "response": {"status": 200, "content": {}}}
+]}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	list := Interfaces(h, nil)
+	if len(list) != 1 {
+		t.Fatalf("interfaces = %+v", list)
+	}
+	headers := list[0].ReqHeaders
+	var names []string
+	for _, item := range headers {
+		names = append(names, item.Name)
+		if item.Name != "Accept" && (item.Value != "" || item.Example != "") {
+			t.Errorf("header %s kept %q / %q", item.Name, item.Value, item.Example)
+		}
+		if item.Required != "1" {
+			t.Errorf("header %s is not required", item.Name)
+		}
+	}
+	if want := []string{"Authorization", "X-Auth-Token", "X-Api-Key", "Accept"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("headers = %q, want %q", names, want)
+	}
+	if headers[3].Value != "application/json" {
+		t.Errorf("accept = %+v", headers[3])
+	}
+}
+
+func TestNormalizePath(t *testing.T) {
+	p, params := NormalizePath("/shops/12/orders/0B5E1E7A-6F4C-4F5E-9D2A-3C1D2E4F5A6B/v2")
+	if p != "/shops/{id}/orders/{id2}/v2" || len(params) != 2 || params[1].Example != "0B5E1E7A-6F4C-4F5E-9D2A-3C1D2E4F5A6B" {
+		t.Errorf("NormalizePath = %q, %+v", p, params)
+	}
+	if p, params := NormalizePath(""); p != "/" || params != nil {
+		t.Errorf("NormalizePath of the root = %q, %+v", p, params)
+	}
+}
@@ -2,7 +2,6 @@ package yapi
 
 import (
 	"encoding/json"
-	"html/template"
 )
 
 // InterfaceService .
@@ -36,6 +35,7 @@ type interfaceBase struct {
 	Path      string   `json:"path" structs:"path"`
 	Method    string   `json:"method" structs:"method"`
 	Tag       []string `json:"tag" structs:"tag"`
+	Index     int      `json:"index,omitempty" structs:"index"`
 }
 
 type interfaceReq struct {
@@ -45,13 +45,13 @@ type interfaceReq struct {
 	ReqBodyForm         []ReqKVItemDetail `json:"req_body_form" structs:"req_body_form"`
 	ReqBodyIsJsonSchema bool              `json:"req_body_is_json_schema" structs:"req_body_is_json_schema"`
 	ReqBodyType         string            `json:"req_body_type" structs:"req_body_type"`
-	ReqBodyOther        template.HTML     `json:"req_body_other" structs:"req_body_other"`
+	ReqBodyOther        string            `json:"req_body_other" structs:"req_body_other"`
 }
 
 type interfaceRes struct {
-	ResBodyIsJsonSchema bool          `json:"res_body_is_json_schema" structs:"res_body_is_json_schema"`
-	ResBodyType         string        `json:"res_body_type" structs:"res_body_type"`
-	ResBody             template.HTML `json:"res_body" structs:"res_body"`
+	ResBodyIsJsonSchema bool   `json:"res_body_is_json_schema" structs:"res_body_is_json_schema"`
+	ResBodyType         string `json:"res_body_type" structs:"res_body_type"`
+	ResBody             string `json:"res_body" structs:"res_body"`
 }
 
 type InterfaceData struct {
@@ -60,15 +60,62 @@ type InterfaceData struct {
 	interfaceRes
 }
 
+// ReqBodySchema parses ReqBodyOther. It returns ErrNotJSONSchema when the
+// body is a raw example, and a nil schema when the body is empty.
+func (d *InterfaceData) ReqBodySchema() (*Schema, error) {
+	if !d.ReqBodyIsJsonSchema {
+		return nil, ErrNotJSONSchema
+	}
+	return ParseSchema(d.ReqBodyOther)
+}
+
+// SetReqBodySchema serializes s into ReqBodyOther and switches the request body to json schema mode.
+func (d *InterfaceData) SetReqBodySchema(s *Schema) {
+	d.ReqBodyOther = s.String()
+	d.ReqBodyIsJsonSchema = true
+	if d.ReqBodyType == "" {
+		d.ReqBodyType = "json"
+	}
+}
+
+// ResBodySchema parses ResBody. It returns ErrNotJSONSchema when the
+// body is a raw example, and a nil schema when the body is empty.
+func (d *InterfaceData) ResBodySchema() (*Schema, error) {
+	if !d.ResBodyIsJsonSchema {
+		return nil, ErrNotJSONSchema
+	}
+	return ParseSchema(d.ResBody)
+}
+
+// SetResBodySchema serializes s into ResBody and switches the response body to json schema mode.
+func (d *InterfaceData) SetResBodySchema(s *Schema) {
+	d.ResBody = s.String()
+	d.ResBodyIsJsonSchema = true
+	if d.ResBodyType == "" {
+		d.ResBodyType = "json"
+	}
+}
+
 type AddOrUpdateInterfaceData struct {
 	Token string `json:"token" structs:"token"`
 	InterfaceData
 }
 
+type UpdateInterfaceData struct {
+	Token string `json:"token" structs:"token"`
+	ID    int    `json:"id" structs:"id"`
+	InterfaceData
+}
+
+type DeleteInterfaceReq struct {
+	Token string `json:"token" structs:"token"`
+	ID    int    `json:"id" structs:"id"`
+}
+
 type Interface struct {
 	CommonResp
 	Data   InterfaceData `json:"data" structs:"data"`
-	string string        `json:"string"`
+	string string
 }
 
 func (i *Interface) ToString() string {
@@ -90,7 +137,7 @@ type InterfaceList struct {
 	ErrCode int               `json:"errcode" structs:"errcode"`
 	ErrMsg  string            `json:"errmsg" structs:"errmsg"`
 	Data    InterfaceListData `json:"data" structs:"data"`
-	string  string            `json:"string"`
+	string  string
 }
 
 func (i *InterfaceList) ToString() string {
@@ -100,7 +147,7 @@ func (i *InterfaceList) ToString() string {
 type InterfaceListParam struct {
 	Token string `url:"token,omitempty"`
 	CatID int    `url:"catid,omitempty"`
-	Page  int    `url:"Page"`
+	Page  int    `url:"page"`
 	Limit int    `url:"limit"`
 }
 
@@ -109,7 +156,7 @@ type UploadSwaggerReq struct {
 	Json  string `json:"json" structs:"jsons"`
 	Merge string `json:"merge" structs:"string"`
 	Token string `json:"token" structs:"token"`
-	url   string `json:"url" structs:"url"`
+	url   string
 }
 
 func (s *InterfaceService) GetList(opt *InterfaceListParam) (*InterfaceList, error) {
@@ -128,6 +175,61 @@ func (s *InterfaceService) GetList(opt *InterfaceListParam) (*InterfaceList, err
 	return &result, err
 }
 
+// GetAll returns the full definition of every interface of the project,
+// grouped by category in menu order.
+func (s *InterfaceService) GetAll(projectID int) ([]InterfaceData, error) {
+	catMenu, err := s.client.CatMenu.Get(projectID)
+	if err != nil {
+		return nil, err
+	}
+	if err := CheckErrCode(catMenu.ErrCode, catMenu.ErrMsg); err != nil {
+		return nil, err
+	}
+	return s.GetAllInCats(catMenu.Data)
+}
+
+// GetAllInCats returns the full definition of every interface of cats, in
+// the order of cats, for callers that already have the category menu.
+func (s *InterfaceService) GetAllInCats(cats []CatData) ([]InterfaceData, error) {
+	var all []InterfaceData
+	for _, cat := range cats {
+		list, err := s.GetCatAll(cat.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range list {
+			detail, err := s.Get(item.ID)
+			if err != nil {
+				return nil, err
+			}
+			if err := CheckErrCode(detail.ErrCode, detail.ErrMsg); err != nil {
+				return nil, err
+			}
+			all = append(all, detail.Data)
+		}
+	}
+	return all, nil
+}
+
+// GetCatAll pages through list_cat and returns the summary of every interface in the category.
+func (s *InterfaceService) GetCatAll(catID int) ([]InterfaceData, error) {
+	var all []InterfaceData
+	for page := 1; ; page++ {
+		list, err := s.GetList(&InterfaceListParam{CatID: catID, Page: page, Limit: 100})
+		if err != nil {
+			return nil, err
+		}
+		if err := CheckErrCode(list.ErrCode, list.ErrMsg); err != nil {
+			return nil, err
+		}
+		all = append(all, list.Data.List...)
+		// list_cat reports the number of pages in total
+		if page >= list.Data.Total || len(list.Data.List) == 0 {
+			return all, nil
+		}
+	}
+}
+
 func (s *InterfaceService) Get(id int) (*Interface, error) {
 	apiEndpoint := "api/interface/get"
 	interfaceParam := InterfaceParam{}
@@ -152,6 +254,7 @@ func (s *InterfaceService) AddOrUpdate(data *InterfaceData) (*ModifyResp, error)
 	apiEndpoint := "api/interface/save"
 	addOrUpdateInterfaceData := AddOrUpdateInterfaceData{}
 	addOrUpdateInterfaceData.Token = s.client.Authentication.token
+	addOrUpdateInterfaceData.InterfaceData = *data
 	resp, err := s.client.Post(apiEndpoint, addOrUpdateInterfaceData)
 	if err != nil {
 		return nil, err
@@ -162,19 +265,64 @@ func (s *InterfaceService) AddOrUpdate(data *InterfaceData) (*ModifyResp, error)
 	return &result, err
 }
 
+// Update modifies the interface with the ID of data. Unlike AddOrUpdate, which
+// matches interfaces on method and path, it can change the method and path.
+func (s *InterfaceService) Update(data *InterfaceData) (*ModifyResp, error) {
+	apiEndpoint := "api/interface/up"
+	updateInterfaceData := UpdateInterfaceData{}
+	updateInterfaceData.Token = s.client.Authentication.token
+	updateInterfaceData.ID = data.ID
+	updateInterfaceData.InterfaceData = *data
+	resp, err := s.client.Post(apiEndpoint, updateInterfaceData)
+	if err != nil {
+		return nil, err
+	}
+	result := ModifyResp{}
+	result.string = resp
+	err = json.Unmarshal([]byte(resp), &result)
+	return &result, err
+}
+
+func (s *InterfaceService) Delete(id int) (*ModifyResp, error) {
+	apiEndpoint := "api/interface/del"
+	deleteInterfaceReq := DeleteInterfaceReq{}
+	deleteInterfaceReq.Token = s.client.Authentication.token
+	deleteInterfaceReq.ID = id
+	resp, err := s.client.Post(apiEndpoint, deleteInterfaceReq)
+	if err != nil {
+		return nil, err
+	}
+	result := ModifyResp{}
+	result.string = resp
+	err = json.Unmarshal([]byte(resp), &result)
+	return &result, err
+}
+
+// UpIndex sets the position of interfaces within their category.
+func (s *InterfaceService) UpIndex(items []IndexItem) (*ModifyResp, error) {
+	return s.client.upIndex("api/interface/up_index", items)
+}
+
 func (s *InterfaceService) UploadSwagger(data *string) (*ModifyResp, error) {
+	return s.Import("swagger", "merge", *data)
+}
+
+// Import imports data through api/open/import_data. typ is the data format,
+// "swagger" or "json", and merge the conflict mode, "normal", "good" or "merge".
+func (s *InterfaceService) Import(typ, merge, data string) (*ModifyResp, error) {
 	apiEndpoint := "api/open/import_data"
 	uploadSwaggerReq := new(UploadSwaggerReq)
 	uploadSwaggerReq.Token = s.client.Authentication.token
-	uploadSwaggerReq.Type = "swagger"
-	uploadSwaggerReq.Merge = "merge"
-	uploadSwaggerReq.Json = *data
+	uploadSwaggerReq.Type = typ
+	uploadSwaggerReq.Merge = merge
+	uploadSwaggerReq.Json = data
 
 	resp, err := s.client.Post(apiEndpoint, uploadSwaggerReq)
 	if err != nil {
 		return nil, err
 	}
 	result := ModifyResp{}
+	result.string = resp
 	err = json.Unmarshal([]byte(resp), &result)
 	return &result, err
 }
@@ -0,0 +1,128 @@
+// Package route matches request paths against YApi interface path templates,
+// which mark path params either as {param} or as :param.
+package route
+
+import (
+	"strings"
+)
+
+// Table is a set of method and path templates. The zero value is ready to use.
+type Table struct {
+	entries []entry
+}
+
+type entry struct {
+	method   string
+	segments []string
+	static   int
+	index    int
+}
+
+// Add registers a template. index is returned by Match and lets callers map
+// the match back to their own data.
+func (t *Table) Add(method, path string, index int) {
+	e := entry{method: strings.ToUpper(method), segments: split(path), index: index}
+	for _, seg := range e.segments {
+		if _, ok := paramName(seg); !ok {
+			e.static++
+		}
+	}
+	t.entries = append(t.entries, e)
+}
+
+// Match finds the template for method and path. When several templates
+// match, the one with the most static segments wins, then the first added.
+// pathFound reports whether path matched a template of another method.
+func (t *Table) Match(method, path string) (index int, params map[string]string, pathFound bool) {
+	method = strings.ToUpper(method)
+	segments := split(path)
+	best := -1
+	for i, e := range t.entries {
+		p, ok := e.match(segments)
+		if !ok {
+			continue
+		}
+		pathFound = true
+		if e.method != method {
+			continue
+		}
+		if best < 0 || e.static > t.entries[best].static {
+			best, params = i, p
+		}
+	}
+	if best < 0 {
+		return -1, nil, pathFound
+	}
+	return t.entries[best].index, params, true
+}
+
+func (e entry) match(segments []string) (map[string]string, bool) {
+	if len(segments) != len(e.segments) {
+		return nil, false
+	}
+	var params map[string]string
+	for i, seg := range e.segments {
+		if name, ok := paramName(seg); ok {
+			if segments[i] == "" {
+				return nil, false
+			}
+			if params == nil {
+				params = make(map[string]string)
+			}
+			params[name] = segments[i]
+			continue
+		}
+		if seg != segments[i] {
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+// Params returns the names of the path params of a template in order.
+func Params(path string) []string {
+	var names []string
+	for _, seg := range split(path) {
+		if name, ok := paramName(seg); ok {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// Expand replaces the path params of a template with values. Params without a value are kept as is.
+func Expand(path string, values map[string]string) string {
+	if i := strings.Index(path, "?"); i >= 0 {
+		path = path[:i]
+	}
+	segments := strings.Split(path, "/")
+	for i, seg := range segments {
+		if name, ok := paramName(seg); ok {
+			if v, ok := values[name]; ok {
+				segments[i] = v
+			}
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+func split(path string) []string {
+	if i := strings.Index(path, "?"); i >= 0 {
+		path = path[:i]
+	}
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, "/")
+}
+
+func paramName(seg string) (string, bool) {
+	if len(seg) > 2 && seg[0] == '{' && seg[len(seg)-1] == '}' {
+		return seg[1 : len(seg)-1], true
+	}
+	if len(seg) > 1 && seg[0] == ':' {
+		return seg[1:], true
+	}
+	return "", false
+}
@@ -0,0 +1,199 @@
+package yapi
+
+import (
+	"encoding/json"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Log list types.
+const (
+	LogTypeGroup     = "group"
+	LogTypeProject   = "project"
+	LogTypeInterface = "interface"
+)
+
+// LogService reads the activity logs. Like UserService it needs a user
+// session.
+type LogService struct {
+	client *Client
+}
+
+// LogData is an activity log entry. Content is an HTML fragment written by
+// YApi, see Text.
+type LogData struct {
+	ID       int             `json:"_id" structs:"_id"`
+	Type     string          `json:"type" structs:"type"`
+	TypeID   int             `json:"typeid" structs:"typeid"`
+	UID      int             `json:"uid" structs:"uid"`
+	Username string          `json:"username" structs:"username"`
+	Content  string          `json:"content" structs:"content"`
+	AddTime  int             `json:"add_time" structs:"add_time"`
+	Data     json.RawMessage `json:"data,omitempty" structs:"data"`
+}
+
+var htmlTag = regexp.MustCompile(`<[^>]*>`)
+
+// Text returns Content without its HTML markup.
+func (l *LogData) Text() string {
+	return strings.TrimSpace(html.UnescapeString(htmlTag.ReplaceAllString(l.Content, "")))
+}
+
+// Time returns the time of the entry.
+func (l *LogData) Time() time.Time {
+	return time.Unix(int64(l.AddTime), 0)
+}
+
+type LogListData struct {
+	Total int       `json:"total" structs:"total"`
+	List  []LogData `json:"list" structs:"list"`
+}
+
+type LogList struct {
+	CommonResp
+	Data   LogListData `json:"data" structs:"data"`
+	string string
+}
+
+func (l *LogList) ToString() string {
+	return l.string
+}
+
+// LogListParam selects the logs of a group or project, newest first.
+// SelectValue narrows the logs of a project to one interface ID.
+type LogListParam struct {
+	Token       string `url:"token,omitempty"`
+	Type        string `url:"type"`
+	TypeID      int    `url:"typeid"`
+	SelectValue string `url:"selectValue,omitempty"`
+	Page        int    `url:"page"`
+	Limit       int    `url:"limit"`
+}
+
+// GetList returns a page of logs.
+func (s *LogService) GetList(opt *LogListParam) (*LogList, error) {
+	apiEndpoint := "api/log/list"
+	opt.Token = s.client.Authentication.token
+	url, err := addOptions(apiEndpoint, opt)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.client.Get(url, nil)
+	if err != nil {
+		return nil, err
+	}
+	result := LogList{}
+	result.string = resp
+	err = json.Unmarshal([]byte(resp), &result)
+	return &result, err
+}
+
+// LogQuery selects logs for LogService.Iter.
+type LogQuery struct {
+	// Type is LogTypeGroup, LogTypeProject or LogTypeInterface, and ID the
+	// ID of the group, project or interface.
+	Type string
+	ID   int
+	// ProjectID is the project of the interface with LogTypeInterface.
+	ProjectID int
+	// Since and Until, when set, keep the entries added in [Since, Until).
+	Since time.Time
+	Until time.Time
+	// PageSize is the number of entries fetched at once, 50 by default.
+	PageSize int
+}
+
+// LogIterator walks the logs of a LogQuery page by page, newest first:
+//
+//	it := client.Log.Iter(&yapi.LogQuery{Type: yapi.LogTypeProject, ID: 11})
+//	for it.Next() {
+//		fmt.Println(it.Log().Text())
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type LogIterator struct {
+	s     *LogService
+	query LogQuery
+	param LogListParam
+	page  []LogData
+	index int
+	done  bool
+	err   error
+}
+
+// Iter returns an iterator over the logs of q.
+func (s *LogService) Iter(q *LogQuery) *LogIterator {
+	it := &LogIterator{s: s, query: *q, index: -1}
+	it.param.Type, it.param.TypeID = q.Type, q.ID
+	if q.Type == LogTypeInterface {
+		it.param.Type, it.param.TypeID = LogTypeProject, q.ProjectID
+		it.param.SelectValue = strconv.Itoa(q.ID)
+	}
+	it.param.Limit = q.PageSize
+	if it.param.Limit <= 0 {
+		it.param.Limit = 50
+	}
+	return it
+}
+
+// Next advances to the next entry and reports whether there is one.
+func (it *LogIterator) Next() bool {
+	for {
+		it.index++
+		for it.index >= len(it.page) {
+			if it.done || !it.fetch() {
+				return false
+			}
+		}
+		l := &it.page[it.index]
+		if !it.query.Since.IsZero() && l.Time().Before(it.query.Since) {
+			// the entries are sorted newest first
+			it.done, it.page = true, nil
+			return false
+		}
+		if it.query.Until.IsZero() || l.Time().Before(it.query.Until) {
+			return true
+		}
+	}
+}
+
+func (it *LogIterator) fetch() bool {
+	it.param.Page++
+	list, err := it.s.GetList(&it.param)
+	if err == nil {
+		err = CheckErrCode(list.ErrCode, list.ErrMsg)
+	}
+	if err != nil {
+		it.err, it.done = err, true
+		return false
+	}
+	it.page, it.index = list.Data.List, 0
+	// total is the number of pages
+	if it.param.Page >= list.Data.Total || len(list.Data.List) < it.param.Limit {
+		it.done = true
+	}
+	return len(it.page) > 0
+}
+
+// Log returns the current entry.
+func (it *LogIterator) Log() LogData {
+	return it.page[it.index]
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *LogIterator) Err() error {
+	return it.err
+}
+
+// All returns the remaining entries of the iterator.
+func (it *LogIterator) All() ([]LogData, error) {
+	var all []LogData
+	for it.Next() {
+		all = append(all, it.Log())
+	}
+	return all, it.Err()
+}
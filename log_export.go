@@ -0,0 +1,100 @@
+package yapi
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"time"
+)
+
+// LogWriter writes log entries in an export format.
+type LogWriter interface {
+	Write(l *LogData) error
+	// Close flushes the buffered entries; it does not close the underlying
+	// writer.
+	Close() error
+}
+
+// LogCSVHeader is the first row written by the CSV LogWriter.
+var LogCSVHeader = []string{"id", "time", "type", "typeid", "uid", "username", "text"}
+
+type logCSVWriter struct {
+	w      *csv.Writer
+	header bool
+}
+
+// NewLogCSVWriter returns a LogWriter writing a CSV file with the
+// LogCSVHeader columns, times in RFC 3339 UTC and the content as text.
+func NewLogCSVWriter(w io.Writer) LogWriter {
+	return &logCSVWriter{w: csv.NewWriter(w)}
+}
+
+func (w *logCSVWriter) Write(l *LogData) error {
+	if !w.header {
+		w.header = true
+		if err := w.w.Write(LogCSVHeader); err != nil {
+			return err
+		}
+	}
+	return w.w.Write([]string{
+		strconv.Itoa(l.ID),
+		l.Time().UTC().Format(time.RFC3339),
+		l.Type,
+		strconv.Itoa(l.TypeID),
+		strconv.Itoa(l.UID),
+		l.Username,
+		l.Text(),
+	})
+}
+
+func (w *logCSVWriter) Close() error {
+	if !w.header {
+		w.header = true
+		w.w.Write(LogCSVHeader)
+	}
+	w.w.Flush()
+	return w.w.Error()
+}
+
+type logJSONLWriter struct {
+	enc *json.Encoder
+}
+
+// NewLogJSONLWriter returns a LogWriter writing JSON Lines: each entry as a
+// JSON object on its own line, with its time in RFC 3339 UTC and its text
+// added.
+func NewLogJSONLWriter(w io.Writer) LogWriter {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	return &logJSONLWriter{enc: enc}
+}
+
+func (w *logJSONLWriter) Write(l *LogData) error {
+	return w.enc.Encode(struct {
+		*LogData
+		Time string `json:"time"`
+		Text string `json:"text"`
+	}{l, l.Time().UTC().Format(time.RFC3339), l.Text()})
+}
+
+func (w *logJSONLWriter) Close() error {
+	return nil
+}
+
+// Export writes the remaining entries of the iterator to lw, closes it and
+// returns the number of entries written.
+func (it *LogIterator) Export(lw LogWriter) (int, error) {
+	n := 0
+	for it.Next() {
+		l := it.Log()
+		if err := lw.Write(&l); err != nil {
+			return n, err
+		}
+		n++
+	}
+	if err := it.Err(); err != nil {
+		return n, err
+	}
+	return n, lw.Close()
+}
@@ -0,0 +1,104 @@
+package yapi
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLogService_Iter(t *testing.T) {
+	// 5 entries, newest first, one every hour from 2021-01-01 04:00 UTC
+	start := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC).Unix()
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/log/list" {
+			http.NotFound(w, r)
+			return
+		}
+		q := r.URL.Query()
+		queries = append(queries, q.Get("type")+" "+q.Get("typeid")+" "+q.Get("selectValue")+" "+q.Get("page"))
+		page, _ := strconv.Atoi(q.Get("page"))
+		var list []string
+		for i := (page - 1) * 2; i < page*2 && i < 5; i++ {
+			list = append(list, fmt.Sprintf(`{"_id": %d, "type": "project", "typeid": 11, "uid": 1, "username": "admin", "add_time": %d,
+				"content": "<a href=\"/user/profile/1\">admin</a> 更新了接口 <a>/orders &amp; items</a>"}`, 5-i, start+int64(4-i)*3600))
+		}
+		fmt.Fprintf(w, `{"errcode": 0, "data": {"total": 3, "list": [%s]}}`, strings.Join(list, ","))
+	}))
+	defer server.Close()
+	client, err := NewClient(server.URL, "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	all, err := client.Log.Iter(&LogQuery{Type: LogTypeProject, ID: 11, PageSize: 2}).All()
+	if err != nil || len(all) != 5 || all[0].ID != 5 || all[4].ID != 1 {
+		t.Fatalf("all = %+v, %v", all, err)
+	}
+	if text := all[0].Text(); text != "admin 更新了接口 /orders & items" {
+		t.Errorf("text = %q", text)
+	}
+
+	queries = nil
+	it := client.Log.Iter(&LogQuery{
+		Type:      LogTypeInterface,
+		ID:        7,
+		ProjectID: 11,
+		Since:     time.Unix(start+1*3600, 0),
+		Until:     time.Unix(start+3*3600, 0),
+		PageSize:  2,
+	})
+	var ids []int
+	for it.Next() {
+		ids = append(ids, it.Log().ID)
+	}
+	if it.Err() != nil || fmt.Sprint(ids) != "[3 2]" {
+		t.Errorf("window = %v, %v", ids, it.Err())
+	}
+	if fmt.Sprint(queries) != "[project 11 7 1 project 11 7 2 project 11 7 3]" {
+		t.Errorf("queries = %q", queries)
+	}
+}
+
+func TestLogWriters(t *testing.T) {
+	logs := []LogData{
+		{ID: 2, Type: "project", TypeID: 11, UID: 1, Username: "admin", AddTime: 1609459200, Content: `<a>admin</a> 删除了 "a, b"`},
+		{ID: 1, Type: "group", TypeID: 3, UID: 4, Username: "bob", AddTime: 1609455600, Content: "bob <b>joined</b>"},
+	}
+	var buf bytes.Buffer
+	w := NewLogCSVWriter(&buf)
+	for i := range logs {
+		if err := w.Write(&logs[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	want := "id,time,type,typeid,uid,username,text\n" +
+		"2,2021-01-01T00:00:00Z,project,11,1,admin,\"admin 删除了 \"\"a, b\"\"\"\n" +
+		"1,2020-12-31T23:00:00Z,group,3,4,bob,bob joined\n"
+	if buf.String() != want {
+		t.Errorf("csv =\n%s\nwant\n%s", buf.String(), want)
+	}
+
+	buf.Reset()
+	w = NewLogJSONLWriter(&buf)
+	if err := w.Write(&logs[1]); err != nil {
+		t.Fatal(err)
+	}
+	want = `{"_id":1,"type":"group","typeid":3,"uid":4,"username":"bob","content":"bob <b>joined</b>","add_time":1609455600,"time":"2020-12-31T23:00:00Z","text":"bob joined"}` + "\n"
+	if buf.String() != want {
+		t.Errorf("jsonl = %s, want %s", buf.String(), want)
+	}
+
+	buf.Reset()
+	if err := NewLogCSVWriter(&buf).Close(); err != nil || buf.String() != "id,time,type,typeid,uid,username,text\n" {
+		t.Errorf("empty csv = %q, %v", buf.String(), err)
+	}
+}
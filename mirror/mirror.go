@@ -0,0 +1,268 @@
+// Package mirror copies the categories and interfaces of a project from one
+// YApi instance to another, for example from production to staging.
+package mirror
+
+import (
+	"fmt"
+
+	yapi "github.com/micrease/go-yapi"
+	"github.com/micrease/go-yapi/diff"
+)
+
+// Options controls a mirror run.
+type Options struct {
+	// Since skips source interfaces whose up_time is not after it. Pass the
+	// LastUpTime of the previous run to only re-send changed interfaces;
+	// zero copies everything.
+	Since int
+	// Prune deletes destination interfaces that no longer exist in the source.
+	Prune bool
+}
+
+// Result summarizes a mirror run.
+type Result struct {
+	SourceProjectID   int
+	DestProjectID     int
+	CategoriesCreated int
+	Copied            int
+	Skipped           int
+	Deleted           int
+	// Reordered counts the categories and interfaces whose menu position
+	// was updated to match the source.
+	Reordered int
+	// LastUpTime is the largest up_time of the source interfaces, to be
+	// passed as Options.Since on the next run.
+	LastUpTime int
+	// Categories maps source category IDs to destination category IDs.
+	Categories map[int]int
+}
+
+type remoteInterface struct {
+	id    int
+	catID int
+	index int
+}
+
+// Mirror replicates the project of the src token into the project of the dst
+// token. Categories are matched by name and created in source order when
+// missing; interfaces are matched by method and path and saved in source
+// order with their category, project and interface IDs remapped to the
+// destination. The menu positions of the source categories and interfaces
+// are then copied to the destination where they differ.
+func Mirror(src, dst *yapi.Client, opts *Options) (*Result, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	srcProject, err := projectID(src)
+	if err != nil {
+		return nil, fmt.Errorf("source: %v", err)
+	}
+	dstProject, err := projectID(dst)
+	if err != nil {
+		return nil, fmt.Errorf("destination: %v", err)
+	}
+	res := &Result{SourceProjectID: srcProject, DestProjectID: dstProject, LastUpTime: opts.Since, Categories: map[int]int{}}
+
+	srcCats, err := catMenu(src, srcProject)
+	if err != nil {
+		return nil, fmt.Errorf("source: %v", err)
+	}
+	dstCats, err := catMenu(dst, dstProject)
+	if err != nil {
+		return nil, fmt.Errorf("destination: %v", err)
+	}
+	dstCatByName := make(map[string]int, len(dstCats))
+	dstCatIndex := make(map[int]int, len(dstCats))
+	existing := map[string]remoteInterface{}
+	var existingKeys []string
+	for _, cat := range dstCats {
+		dstCatByName[cat.Name] = cat.ID
+		dstCatIndex[cat.ID] = cat.Index
+		list, err := dst.Interface.GetCatAll(cat.ID)
+		if err != nil {
+			return nil, fmt.Errorf("destination: %v", err)
+		}
+		for i := range list {
+			key := diff.Key(&list[i])
+			existing[key] = remoteInterface{id: list[i].ID, catID: cat.ID, index: list[i].Index}
+			existingKeys = append(existingKeys, key)
+		}
+	}
+
+	seen := map[string]bool{}
+	var catIndex []yapi.IndexItem
+	for _, cat := range srcCats {
+		catID, ok := dstCatByName[cat.Name]
+		if !ok {
+			if catID, err = createCategory(dst, dstProject, cat); err != nil {
+				return nil, err
+			}
+			dstCatByName[cat.Name] = catID
+			res.CategoriesCreated++
+		}
+		res.Categories[cat.ID] = catID
+		if dstCatIndex[catID] != cat.Index {
+			catIndex = append(catIndex, yapi.IndexItem{ID: catID, Index: cat.Index})
+		}
+
+		list, err := src.Interface.GetCatAll(cat.ID)
+		if err != nil {
+			return nil, fmt.Errorf("source: %v", err)
+		}
+		// a created interface needs its ID, read back from the category,
+		// when its source index is not the default one
+		refetch := false
+		for _, item := range list {
+			if _, ok := existing[diff.Key(&item)]; !ok && item.Index != 0 {
+				refetch = true
+			}
+		}
+		for _, item := range list {
+			key := diff.Key(&item)
+			seen[key] = true
+			if item.UpTime > res.LastUpTime {
+				res.LastUpTime = item.UpTime
+			}
+			if remote, ok := existing[key]; ok && remote.catID == catID && item.UpTime <= opts.Since {
+				res.Skipped++
+				continue
+			}
+			detail, err := src.Interface.Get(item.ID)
+			if err != nil {
+				return nil, fmt.Errorf("source: %v", err)
+			}
+			if err := yapi.CheckErrCode(detail.ErrCode, detail.ErrMsg); err != nil {
+				return nil, fmt.Errorf("source: get %s: %v", key, err)
+			}
+			d := remap(detail.Data, dstProject, catID, existing[key].id)
+			resp, err := dst.Interface.AddOrUpdate(&d)
+			if err == nil {
+				err = yapi.CheckErrCode(resp.ErrCode, resp.ErrMsg)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("destination: save %s: %v", key, err)
+			}
+			res.Copied++
+		}
+		if err := copyIndex(dst, catID, list, existing, refetch, res); err != nil {
+			return nil, err
+		}
+	}
+	if len(catIndex) > 0 {
+		resp, err := dst.CatMenu.UpIndex(catIndex)
+		if err == nil {
+			err = yapi.CheckErrCode(resp.ErrCode, resp.ErrMsg)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("destination: update category order: %v", err)
+		}
+		res.Reordered += len(catIndex)
+	}
+
+	if opts.Prune {
+		for _, key := range existingKeys {
+			if seen[key] {
+				continue
+			}
+			remote := existing[key]
+			resp, err := dst.Interface.Delete(remote.id)
+			if err == nil {
+				err = yapi.CheckErrCode(resp.ErrCode, resp.ErrMsg)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("destination: delete %s: %v", key, err)
+			}
+			res.Deleted++
+		}
+	}
+	return res, nil
+}
+
+// copyIndex sets the index of the destination interfaces of catID to the
+// index of the source interfaces in list, where they differ.
+func copyIndex(dst *yapi.Client, catID int, list []yapi.InterfaceData, existing map[string]remoteInterface, refetch bool, res *Result) error {
+	current := existing
+	if refetch {
+		saved, err := dst.Interface.GetCatAll(catID)
+		if err != nil {
+			return fmt.Errorf("destination: %v", err)
+		}
+		current = make(map[string]remoteInterface, len(saved))
+		for i := range saved {
+			current[diff.Key(&saved[i])] = remoteInterface{id: saved[i].ID, catID: catID, index: saved[i].Index}
+		}
+	}
+	var items []yapi.IndexItem
+	for i := range list {
+		remote := current[diff.Key(&list[i])]
+		if remote.index != list[i].Index && remote.id != 0 {
+			items = append(items, yapi.IndexItem{ID: remote.id, Index: list[i].Index})
+		}
+	}
+	if len(items) == 0 {
+		return nil
+	}
+	resp, err := dst.Interface.UpIndex(items)
+	if err == nil {
+		err = yapi.CheckErrCode(resp.ErrCode, resp.ErrMsg)
+	}
+	if err != nil {
+		return fmt.Errorf("destination: update interface order: %v", err)
+	}
+	res.Reordered += len(items)
+	return nil
+}
+
+// remap rewrites the IDs of a source interface for the destination. User IDs
+// and timestamps belong to the source instance and are cleared, and the
+// index is left to copyIndex.
+func remap(d yapi.InterfaceData, projectID, catID, id int) yapi.InterfaceData {
+	d.ID = id
+	d.ProjectID = projectID
+	d.CatID = catID
+	d.UID, d.EditUID = 0, 0
+	d.AddTime, d.UpTime = 0, 0
+	d.Index = 0
+	return d
+}
+
+func projectID(c *yapi.Client) (int, error) {
+	project, err := c.Project.Get()
+	if err != nil {
+		return 0, err
+	}
+	if err := yapi.CheckErrCode(project.ErrCode, project.ErrMsg); err != nil {
+		return 0, err
+	}
+	return project.Data.ID, nil
+}
+
+func catMenu(c *yapi.Client, projectID int) (yapi.CatMenuData, error) {
+	menu, err := c.CatMenu.Get(projectID)
+	if err != nil {
+		return nil, err
+	}
+	if err := yapi.CheckErrCode(menu.ErrCode, menu.ErrMsg); err != nil {
+		return nil, err
+	}
+	return menu.Data, nil
+}
+
+func createCategory(c *yapi.Client, projectID int, cat yapi.CatData) (int, error) {
+	param := new(yapi.ModifyMenuParam)
+	param.ProjectID = projectID
+	param.Name = cat.Name
+	param.Desc = cat.Desc
+	resp, err := c.CatMenu.AddOrUpdate(param)
+	if err == nil {
+		err = yapi.CheckErrCode(resp.ErrCode, resp.ErrMsg)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("destination: create category %q: %v", cat.Name, err)
+	}
+	id, err := yapi.CreatedID(resp.Data)
+	if err != nil {
+		return 0, fmt.Errorf("destination: create category %q: %v", cat.Name, err)
+	}
+	return id, nil
+}
@@ -0,0 +1,242 @@
+package mirror
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	yapi "github.com/micrease/go-yapi"
+)
+
+// project is an in-memory YApi project serving the endpoints Mirror uses.
+type project struct {
+	id         int
+	nextID     int
+	cats       yapi.CatMenuData
+	interfaces []yapi.InterfaceData
+	calls      []string
+}
+
+func (p *project) serve(t *testing.T) *httptest.Server {
+	reply := func(w http.ResponseWriter, data interface{}) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"errcode": 0, "errmsg": "成功！", "data": data})
+	}
+	body := func(r *http.Request, v interface{}) {
+		data, _ := ioutil.ReadAll(r.Body)
+		if err := json.Unmarshal(data, v); err != nil {
+			t.Errorf("%s: %v", r.URL.Path, err)
+		}
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/project/get", func(w http.ResponseWriter, r *http.Request) {
+		reply(w, yapi.ProjectData{ID: p.id})
+	})
+	mux.HandleFunc("/api/interface/getCatMenu", func(w http.ResponseWriter, r *http.Request) {
+		reply(w, p.cats)
+	})
+	mux.HandleFunc("/api/interface/list_cat", func(w http.ResponseWriter, r *http.Request) {
+		catID, _ := strconv.Atoi(r.URL.Query().Get("catid"))
+		list := []yapi.InterfaceData{}
+		for _, d := range p.interfaces {
+			if d.CatID == catID {
+				list = append(list, d)
+			}
+		}
+		reply(w, yapi.InterfaceListData{Count: len(list), Total: 1, List: list})
+	})
+	mux.HandleFunc("/api/interface/get", func(w http.ResponseWriter, r *http.Request) {
+		id, _ := strconv.Atoi(r.URL.Query().Get("id"))
+		p.calls = append(p.calls, "get "+strconv.Itoa(id))
+		for _, d := range p.interfaces {
+			if d.ID == id {
+				reply(w, d)
+			}
+		}
+	})
+	mux.HandleFunc("/api/interface/add_cat", func(w http.ResponseWriter, r *http.Request) {
+		var cat yapi.CatData
+		body(r, &cat)
+		p.nextID++
+		cat.ID = p.nextID
+		p.cats = append(p.cats, cat)
+		p.calls = append(p.calls, "add_cat "+cat.Name)
+		reply(w, cat)
+	})
+	mux.HandleFunc("/api/interface/save", func(w http.ResponseWriter, r *http.Request) {
+		var d yapi.InterfaceData
+		body(r, &d)
+		p.calls = append(p.calls, "save "+d.Method+" "+d.Path+" id="+strconv.Itoa(d.ID)+" catid="+strconv.Itoa(d.CatID)+" project="+strconv.Itoa(d.ProjectID))
+		for i := range p.interfaces {
+			if p.interfaces[i].Method == d.Method && p.interfaces[i].Path == d.Path {
+				d.ID = p.interfaces[i].ID
+				p.interfaces[i] = d
+				reply(w, []interface{}{})
+				return
+			}
+		}
+		p.nextID++
+		d.ID = p.nextID
+		p.interfaces = append(p.interfaces, d)
+		reply(w, []interface{}{})
+	})
+	mux.HandleFunc("/api/interface/del", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID int `json:"id"`
+		}
+		body(r, &req)
+		p.calls = append(p.calls, "del "+strconv.Itoa(req.ID))
+		reply(w, map[string]int{"n": 1})
+	})
+	upIndex := func(name string, apply func(id, index int)) {
+		mux.HandleFunc("/api/interface/"+name, func(w http.ResponseWriter, r *http.Request) {
+			var items []yapi.IndexItem
+			body(r, &items)
+			var call []string
+			for _, item := range items {
+				apply(item.ID, item.Index)
+				call = append(call, strconv.Itoa(item.ID)+":"+strconv.Itoa(item.Index))
+			}
+			p.calls = append(p.calls, name+" "+strings.Join(call, ","))
+			reply(w, "成功！")
+		})
+	}
+	upIndex("up_index", func(id, index int) {
+		for i := range p.interfaces {
+			if p.interfaces[i].ID == id {
+				p.interfaces[i].Index = index
+			}
+		}
+	})
+	upIndex("up_cat_index", func(id, index int) {
+		for i := range p.cats {
+			if p.cats[i].ID == id {
+				p.cats[i].Index = index
+			}
+		}
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func testInterface(id, catID, upTime int, method, path string) yapi.InterfaceData {
+	var d yapi.InterfaceData
+	d.ID, d.CatID, d.ProjectID, d.UpTime, d.UID = id, catID, 1, upTime, 7
+	d.Method, d.Path, d.Title = method, path, path
+	return d
+}
+
+func TestMirror(t *testing.T) {
+	src := &project{
+		id:   1,
+		cats: yapi.CatMenuData{{ID: 10, Name: "users"}, {ID: 11, Name: "orders"}},
+		interfaces: []yapi.InterfaceData{
+			testInterface(100, 10, 1000, "GET", "/users"),
+			testInterface(101, 10, 1001, "POST", "/users"),
+			testInterface(102, 11, 1002, "GET", "/orders"),
+		},
+	}
+	dst := &project{
+		id:         5,
+		nextID:     500,
+		cats:       yapi.CatMenuData{{ID: 50, Name: "users"}},
+		interfaces: []yapi.InterfaceData{testInterface(60, 50, 1, "GET", "/old")},
+	}
+	srcClient, _ := yapi.NewClient(src.serve(t).URL, "src-token")
+	dstClient, _ := yapi.NewClient(dst.serve(t).URL, "dst-token")
+
+	res, err := Mirror(srcClient, dstClient, &Options{Prune: true})
+	if err != nil {
+		t.Fatalf("Mirror returned error: %v", err)
+	}
+	want := []string{
+		"save GET /users id=0 catid=50 project=5",
+		"save POST /users id=0 catid=50 project=5",
+		"add_cat orders",
+		"save GET /orders id=0 catid=503 project=5",
+		"del 60",
+	}
+	if !reflect.DeepEqual(dst.calls, want) {
+		t.Errorf("destination calls =\n%s\nwant\n%s", strings.Join(dst.calls, "\n"), strings.Join(want, "\n"))
+	}
+	if res.Copied != 3 || res.Deleted != 1 || res.CategoriesCreated != 1 || res.LastUpTime != 1002 {
+		t.Errorf("result = %+v", res)
+	}
+	if !reflect.DeepEqual(res.Categories, map[int]int{10: 50, 11: 503}) {
+		t.Errorf("categories = %v", res.Categories)
+	}
+	if d := dst.interfaces[1]; d.UID != 0 || d.UpTime != 0 {
+		t.Errorf("source user and timestamps were copied: %+v", d)
+	}
+
+	// only the interface updated after the checkpoint is fetched and re-sent
+	src.calls, dst.calls = nil, nil
+	src.interfaces[1].UpTime = 2000
+	src.interfaces[1].Title = "create user"
+	res, err = Mirror(srcClient, dstClient, &Options{Since: res.LastUpTime})
+	if err != nil {
+		t.Fatalf("incremental Mirror returned error: %v", err)
+	}
+	if want := []string{"get 101"}; !reflect.DeepEqual(src.calls, want) {
+		t.Errorf("source calls = %v, want %v", src.calls, want)
+	}
+	if want := []string{"save POST /users id=502 catid=50 project=5"}; !reflect.DeepEqual(dst.calls, want) {
+		t.Errorf("destination calls = %v, want %v", dst.calls, want)
+	}
+	if res.Copied != 1 || res.Skipped != 2 || res.LastUpTime != 2000 {
+		t.Errorf("incremental result = %+v", res)
+	}
+}
+
+func TestMirror_Order(t *testing.T) {
+	src := &project{
+		id:   1,
+		cats: yapi.CatMenuData{{ID: 10, Name: "users", Index: 0}, {ID: 11, Name: "orders", Index: 1}},
+		interfaces: []yapi.InterfaceData{
+			testInterface(100, 10, 1000, "GET", "/users"),
+			testInterface(101, 10, 1001, "POST", "/users"),
+			testInterface(102, 10, 1002, "DELETE", "/users"),
+		},
+	}
+	for i := range src.interfaces {
+		src.interfaces[i].Index = i
+	}
+	dst := &project{
+		id:     5,
+		nextID: 500,
+		cats:   yapi.CatMenuData{{ID: 50, Name: "orders", Index: 0}, {ID: 51, Name: "users", Index: 1}},
+		interfaces: []yapi.InterfaceData{
+			testInterface(60, 51, 1, "POST", "/users"),
+			testInterface(61, 51, 1, "GET", "/users"),
+		},
+	}
+	dst.interfaces[0].Index, dst.interfaces[1].Index = 0, 1
+	srcClient, _ := yapi.NewClient(src.serve(t).URL, "src-token")
+	dstClient, _ := yapi.NewClient(dst.serve(t).URL, "dst-token")
+
+	res, err := Mirror(srcClient, dstClient, &Options{Since: 5000})
+	if err != nil {
+		t.Fatalf("Mirror returned error: %v", err)
+	}
+	want := []string{
+		"save DELETE /users id=0 catid=51 project=5",
+		"up_index 61:0,60:1,501:2",
+		"up_cat_index 51:0,50:1",
+	}
+	if !reflect.DeepEqual(dst.calls, want) {
+		t.Errorf("destination calls =\n%s\nwant\n%s", strings.Join(dst.calls, "\n"), strings.Join(want, "\n"))
+	}
+	if res.Reordered != 5 || res.Skipped != 2 {
+		t.Errorf("result = %+v", res)
+	}
+
+	dst.calls = nil
+	if res, err := Mirror(srcClient, dstClient, &Options{Since: 5000}); err != nil || res.Reordered != 0 || len(dst.calls) != 0 {
+		t.Errorf("second Mirror = %+v, %v, calls %v", res, err, dst.calls)
+	}
+}
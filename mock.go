@@ -0,0 +1,258 @@
+package yapi
+
+import (
+	"encoding/json"
+)
+
+// MockService manages the mock scripts and expectation cases of the advanced
+// mock plugin.
+type MockService struct {
+	client *Client
+}
+
+// MockScript is the advanced mock script of an interface. The script runs
+// after the default mock and can change mockJson, resHeader, httpCode and
+// delay.
+type MockScript struct {
+	InterfaceID int    `json:"interface_id" structs:"interface_id"`
+	ProjectID   int    `json:"project_id" structs:"project_id"`
+	Enable      bool   `json:"enable" structs:"enable"`
+	MockScript  string `json:"mock_script" structs:"mock_script"`
+	UID         int    `json:"uid,omitempty" structs:"uid"`
+	UpTime      int    `json:"up_time,omitempty" structs:"up_time"`
+}
+
+type MockScriptResp struct {
+	CommonResp
+	Data   MockScript `json:"data" structs:"data"`
+	string string
+}
+
+func (m *MockScriptResp) ToString() string {
+	return m.string
+}
+
+// MockHeader is a response header returned by an expectation case.
+type MockHeader struct {
+	Name  string `json:"name" structs:"name"`
+	Value string `json:"value" structs:"value"`
+}
+
+// MockCase is an expectation case: when a mock request matches Params, and
+// comes from IP if IPEnable is set, the plugin answers with Code, Headers and
+// ResBody after Delay milliseconds.
+type MockCase struct {
+	ID          int                    `json:"_id,omitempty" structs:"_id"`
+	InterfaceID int                    `json:"interface_id" structs:"interface_id"`
+	ProjectID   int                    `json:"project_id" structs:"project_id"`
+	Name        string                 `json:"name" structs:"name"`
+	IPEnable    bool                   `json:"ip_enable" structs:"ip_enable"`
+	IP          string                 `json:"ip" structs:"ip"`
+	Params      map[string]interface{} `json:"params" structs:"params"`
+	Code        int                    `json:"code" structs:"code"`
+	Delay       int                    `json:"delay" structs:"delay"`
+	Headers     []MockHeader           `json:"headers" structs:"headers"`
+	ResBody     string                 `json:"res_body" structs:"res_body"`
+	CaseEnable  bool                   `json:"case_enable" structs:"case_enable"`
+	UID         int                    `json:"uid,omitempty" structs:"uid"`
+	UpTime      int                    `json:"up_time,omitempty" structs:"up_time"`
+}
+
+// SetResBody sets the response body of the case to v encoded as JSON.
+func (c *MockCase) SetResBody(v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	c.ResBody = string(data)
+	return nil
+}
+
+// DecodeResBody decodes the JSON response body of the case into v.
+func (c *MockCase) DecodeResBody(v interface{}) error {
+	return json.Unmarshal([]byte(c.ResBody), v)
+}
+
+type MockCaseList struct {
+	CommonResp
+	Data   []MockCase `json:"data" structs:"data"`
+	string string
+}
+
+func (m *MockCaseList) ToString() string {
+	return m.string
+}
+
+type MockCaseResp struct {
+	CommonResp
+	Data   MockCase `json:"data" structs:"data"`
+	string string
+}
+
+func (m *MockCaseResp) ToString() string {
+	return m.string
+}
+
+type MockParam struct {
+	Token       string `url:"token"`
+	InterfaceID int    `url:"interface_id"`
+}
+
+type MockScriptReq struct {
+	Token string `json:"token"`
+	MockScript
+}
+
+type MockCaseReq struct {
+	Token string `json:"token"`
+	// ID is sent as "id" to update an existing case, a new case is
+	// created when it is 0.
+	ID int `json:"id,omitempty"`
+	MockCase
+}
+
+type DelMockCaseReq struct {
+	Token string `json:"token"`
+	ID    int    `json:"id"`
+}
+
+// GetScript returns the mock script of an interface.
+func (s *MockService) GetScript(interfaceID int) (*MockScriptResp, error) {
+	apiEndpoint := "api/plugin/advmock/get"
+	url, err := addOptions(apiEndpoint, &MockParam{Token: s.client.Authentication.token, InterfaceID: interfaceID})
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.client.Get(url, nil)
+	if err != nil {
+		return nil, err
+	}
+	result := MockScriptResp{}
+	result.string = resp
+	err = json.Unmarshal([]byte(resp), &result)
+	return &result, err
+}
+
+// SaveScript creates or replaces the mock script of script.InterfaceID.
+func (s *MockService) SaveScript(script *MockScript) (*ModifyResp, error) {
+	apiEndpoint := "api/plugin/advmock/save"
+	mockScriptReq := MockScriptReq{}
+	mockScriptReq.Token = s.client.Authentication.token
+	mockScriptReq.MockScript = *script
+	resp, err := s.client.Post(apiEndpoint, mockScriptReq)
+	if err != nil {
+		return nil, err
+	}
+	result := ModifyResp{}
+	result.string = resp
+	err = json.Unmarshal([]byte(resp), &result)
+	return &result, err
+}
+
+// ListCases returns the expectation cases of an interface.
+func (s *MockService) ListCases(interfaceID int) (*MockCaseList, error) {
+	apiEndpoint := "api/plugin/advmock/case/list"
+	url, err := addOptions(apiEndpoint, &MockParam{Token: s.client.Authentication.token, InterfaceID: interfaceID})
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.client.Get(url, nil)
+	if err != nil {
+		return nil, err
+	}
+	result := MockCaseList{}
+	result.string = resp
+	err = json.Unmarshal([]byte(resp), &result)
+	return &result, err
+}
+
+// SaveCase creates the case, or updates it when its ID is set.
+func (s *MockService) SaveCase(c *MockCase) (*MockCaseResp, error) {
+	apiEndpoint := "api/plugin/advmock/case/save"
+	mockCaseReq := MockCaseReq{}
+	mockCaseReq.Token = s.client.Authentication.token
+	mockCaseReq.ID = c.ID
+	mockCaseReq.MockCase = *c
+	mockCaseReq.MockCase.ID = 0
+	if mockCaseReq.Params == nil {
+		mockCaseReq.Params = map[string]interface{}{}
+	}
+	if mockCaseReq.Headers == nil {
+		mockCaseReq.Headers = []MockHeader{}
+	}
+	resp, err := s.client.Post(apiEndpoint, mockCaseReq)
+	if err != nil {
+		return nil, err
+	}
+	result := MockCaseResp{}
+	result.string = resp
+	err = json.Unmarshal([]byte(resp), &result)
+	return &result, err
+}
+
+// DeleteCase removes an expectation case.
+func (s *MockService) DeleteCase(id int) (*ModifyResp, error) {
+	apiEndpoint := "api/plugin/advmock/case/del"
+	delMockCaseReq := DelMockCaseReq{}
+	delMockCaseReq.Token = s.client.Authentication.token
+	delMockCaseReq.ID = id
+	resp, err := s.client.Post(apiEndpoint, delMockCaseReq)
+	if err != nil {
+		return nil, err
+	}
+	result := ModifyResp{}
+	result.string = resp
+	err = json.Unmarshal([]byte(resp), &result)
+	return &result, err
+}
+
+// SyncCases makes the expectation cases of an interface match cases: cases
+// are matched on name, existing ones are updated, missing ones created and
+// the others, duplicates included, deleted. It returns the saved cases.
+func (s *MockService) SyncCases(interfaceID int, cases []MockCase) ([]MockCase, error) {
+	list, err := s.ListCases(interfaceID)
+	if err != nil {
+		return nil, err
+	}
+	if err := CheckErrCode(list.ErrCode, list.ErrMsg); err != nil {
+		return nil, err
+	}
+	existing := map[string]int{}
+	for _, c := range list.Data {
+		if _, ok := existing[c.Name]; !ok {
+			existing[c.Name] = c.ID
+		}
+	}
+	kept := map[int]bool{}
+	var saved []MockCase
+	for _, c := range cases {
+		c.InterfaceID = interfaceID
+		c.ID = existing[c.Name]
+		delete(existing, c.Name)
+		resp, err := s.SaveCase(&c)
+		if err == nil {
+			err = CheckErrCode(resp.ErrCode, resp.ErrMsg)
+		}
+		if err != nil {
+			return saved, err
+		}
+		if resp.Data.ID != 0 {
+			c.ID = resp.Data.ID
+		}
+		kept[c.ID] = true
+		saved = append(saved, c)
+	}
+	for _, c := range list.Data {
+		if kept[c.ID] {
+			continue
+		}
+		resp, err := s.DeleteCase(c.ID)
+		if err == nil {
+			err = CheckErrCode(resp.ErrCode, resp.ErrMsg)
+		}
+		if err != nil {
+			return saved, err
+		}
+	}
+	return saved, nil
+}
@@ -0,0 +1,111 @@
+package yapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// MockURL returns the root of the mock URLs of a project: the interfaces of
+// the project are mocked at MockURL(...) + path.
+func MockURL(baseURL string, projectID int, basepath string) string {
+	u := strings.TrimRight(baseURL, "/") + "/mock/" + strconv.Itoa(projectID)
+	if basepath = strings.Trim(basepath, "/"); basepath != "" {
+		u += "/" + basepath
+	}
+	return u
+}
+
+// MockRequest builds a request for the mock of the interface, see
+// SampleRequest and MockURL.
+func (d *InterfaceData) MockRequest(baseURL string, projectID int, basepath string) (*http.Request, error) {
+	return d.SampleRequest(MockURL(baseURL, projectID, basepath))
+}
+
+// MockCallOptions changes the request sent by MockService.Call.
+type MockCallOptions struct {
+	// Basepath is the base path of the project, which YApi puts in the mock
+	// URLs.
+	Basepath string
+	// BaseURL sends the request to another host than the client's, such as
+	// a local mockserver mirroring the YApi mock URLs. The session cookies
+	// and basic auth credentials of the client are only sent to the client's
+	// own scheme and host.
+	BaseURL string
+	// Header is added to the request, replacing the documented headers of
+	// the same name.
+	Header http.Header
+	// Body, when not nil, is sent encoded as JSON instead of the example
+	// body.
+	Body interface{}
+}
+
+// MockResponse is the answer of a mock.
+type MockResponse struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// Call sends the example request of the interface, built with
+// InterfaceData.MockRequest, to the mock of the project and decodes the JSON
+// response into v unless v is nil. A response outside the 2xx range is
+// returned with an error and not decoded. opts may be nil.
+func (s *MockService) Call(d *InterfaceData, projectID int, opts *MockCallOptions, v interface{}) (*MockResponse, error) {
+	if opts == nil {
+		opts = &MockCallOptions{}
+	}
+	baseURL := opts.BaseURL
+	if baseURL == "" {
+		u := s.client.GetBaseURL()
+		baseURL = u.String()
+	}
+	req, err := d.MockRequest(baseURL, projectID, opts.Basepath)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Body != nil {
+		body, err := json.Marshal(opts.Body)
+		if err != nil {
+			return nil, err
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+		req.ContentLength = int64(len(body))
+		req.GetBody = func() (io.ReadCloser, error) {
+			return ioutil.NopCloser(bytes.NewReader(body)), nil
+		}
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for name, values := range opts.Header {
+		req.Header[http.CanonicalHeaderKey(name)] = append([]string(nil), values...)
+	}
+	if u := s.client.GetBaseURL(); req.URL.Scheme == u.Scheme && req.URL.Host == u.Host {
+		s.client.Authentication.authenticate(req)
+	}
+
+	resp, err := s.client.doer().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	result := &MockResponse{StatusCode: resp.StatusCode, Header: resp.Header, Body: body}
+	if err := CheckResponse(resp); err != nil {
+		return result, errors.Wrapf(err, "mock %s %s", req.Method, req.URL.Path)
+	}
+	if v != nil {
+		if err := json.Unmarshal(body, v); err != nil {
+			return result, errors.Wrapf(err, "decode mock %s %s", req.Method, req.URL.Path)
+		}
+	}
+	return result, nil
+}
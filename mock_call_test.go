@@ -0,0 +1,117 @@
+package yapi
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMockURL(t *testing.T) {
+	tests := []struct {
+		base, basepath, want string
+	}{
+		{"http://yapi/", "", "http://yapi/mock/11"},
+		{"http://yapi", "/api/", "http://yapi/mock/11/api"},
+		{"http://host/yapi/", "v1", "http://host/yapi/mock/11/v1"},
+	}
+	for _, tt := range tests {
+		if got := MockURL(tt.base, 11, tt.basepath); got != tt.want {
+			t.Errorf("MockURL(%q, 11, %q) = %q, want %q", tt.base, tt.basepath, got, tt.want)
+		}
+	}
+}
+
+func TestMockService_Call(t *testing.T) {
+	var d InterfaceData
+	d.Method, d.Path = "POST", "/orders/{id}"
+	d.ReqParams = []ReqKVItemSimple{{Name: "id", Example: "7"}}
+	d.ReqHeaders = []ReqKVItemDetail{{ReqKVItemSimple: ReqKVItemSimple{Name: "X-Tenant", Example: "acme"}}}
+	d.ReqBodyType, d.ReqBodyOther = "json", `{"qty": 1}`
+
+	var gotPath, gotTenant, gotBody string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		gotPath, gotTenant, gotBody = r.Method+" "+r.URL.Path, r.Header.Get("X-Tenant"), string(body)
+		if r.URL.Path == "/mock/11/api/orders/7" {
+			w.Write([]byte(`{"id": 7, "status": "paid"}`))
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"errcode": 404, "errmsg": "不存在的api"}`))
+	}))
+	defer server.Close()
+	client, err := NewClient(server.URL, "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var order struct {
+		ID     int
+		Status string
+	}
+	resp, err := client.Mock.Call(&d, 11, &MockCallOptions{Basepath: "/api"}, &order)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != 200 || order.ID != 7 || order.Status != "paid" {
+		t.Errorf("response %d %s, order %+v", resp.StatusCode, resp.Body, order)
+	}
+	if gotPath != "POST /mock/11/api/orders/7" || gotTenant != "acme" || gotBody != `{"qty": 1}` {
+		t.Errorf("request %s, tenant %q, body %s", gotPath, gotTenant, gotBody)
+	}
+
+	opts := &MockCallOptions{
+		Basepath: "/api",
+		Header:   http.Header{"x-tenant": {"other"}},
+		Body:     map[string]int{"qty": 2},
+	}
+	if _, err := client.Mock.Call(&d, 11, opts, nil); err != nil {
+		t.Fatal(err)
+	}
+	if gotTenant != "other" || gotBody != `{"qty":2}` {
+		t.Errorf("tenant %q, body %s", gotTenant, gotBody)
+	}
+
+	resp, err = client.Mock.Call(&d, 11, nil, &order)
+	if err == nil || resp == nil || resp.StatusCode != http.StatusNotFound {
+		t.Errorf("call without basepath = %+v, %v", resp, err)
+	}
+}
+
+func TestMockService_CallAuthentication(t *testing.T) {
+	var d InterfaceData
+	d.Method, d.Path = "GET", "/orders"
+
+	var cookie, user string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cookie = r.Header.Get("Cookie")
+		user, _, _ = r.BasicAuth()
+		w.Write([]byte(`{}`))
+	})
+	yapiServer := httptest.NewServer(handler)
+	defer yapiServer.Close()
+	otherServer := httptest.NewServer(handler)
+	defer otherServer.Close()
+
+	client, err := NewClient(yapiServer.URL, "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.Authentication.authType = authTypeBasic
+	client.Authentication.username, client.Authentication.password = "ci", "secret"
+	client.Authentication.cookies = []*http.Cookie{{Name: "_yapi_token", Value: "session"}}
+
+	if _, err := client.Mock.Call(&d, 11, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if cookie != "_yapi_token=session" || user != "ci" {
+		t.Errorf("mock of the client's host got cookie %q, user %q", cookie, user)
+	}
+	if _, err := client.Mock.Call(&d, 11, &MockCallOptions{BaseURL: otherServer.URL}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if cookie != "" || user != "" {
+		t.Errorf("mock of another host got cookie %q, user %q", cookie, user)
+	}
+}
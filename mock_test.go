@@ -0,0 +1,106 @@
+package yapi
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"testing"
+)
+
+func TestMockService(t *testing.T) {
+	var script map[string]interface{}
+	var saved []map[string]interface{}
+	var deleted []int
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/plugin/advmock/get", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("interface_id") != "7" || r.URL.Query().Get("token") != "token" {
+			t.Errorf("get query = %s", r.URL.RawQuery)
+		}
+		w.Write([]byte(`{"errcode": 0, "data": {"interface_id": 7, "project_id": 11, "enable": true, "mock_script": "mockJson.code = 1"}}`))
+	})
+	mux.HandleFunc("/api/plugin/advmock/save", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		json.Unmarshal(body, &script)
+		w.Write([]byte(`{"errcode": 0, "data": {"n": 1}}`))
+	})
+	mux.HandleFunc("/api/plugin/advmock/case/list", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"errcode": 0, "data": [
+			{"_id": 1, "interface_id": 7, "name": "ok", "params": {"id": 1}, "code": 200, "res_body": "{\"name\": \"a\"}"},
+			{"_id": 2, "interface_id": 7, "name": "old"},
+			{"_id": 3, "interface_id": 7, "name": "ok"}
+		]}`))
+	})
+	mux.HandleFunc("/api/plugin/advmock/case/save", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		var c map[string]interface{}
+		json.Unmarshal(body, &c)
+		saved = append(saved, c)
+		id := 20
+		if v, ok := c["id"].(float64); ok {
+			id = int(v)
+		}
+		w.Write([]byte(`{"errcode": 0, "data": {"_id": ` + strconv.Itoa(id) + `}}`))
+	})
+	mux.HandleFunc("/api/plugin/advmock/case/del", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		var req DelMockCaseReq
+		json.Unmarshal(body, &req)
+		deleted = append(deleted, req.ID)
+		w.Write([]byte(`{"errcode": 0, "data": {"n": 1}}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	client, err := NewClient(server.URL, "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := client.Mock.GetScript(7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !s.Data.Enable || s.Data.MockScript != "mockJson.code = 1" {
+		t.Errorf("script = %+v", s.Data)
+	}
+	if _, err := client.Mock.SaveScript(&MockScript{InterfaceID: 7, ProjectID: 11, MockScript: "delay = 100"}); err != nil {
+		t.Fatal(err)
+	}
+	if script["token"] != "token" || script["interface_id"] != float64(7) || script["mock_script"] != "delay = 100" {
+		t.Errorf("saved script = %v", script)
+	}
+
+	list, err := client.Mock.ListCases(7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var body struct{ Name string }
+	if err := list.Data[0].DecodeResBody(&body); err != nil || body.Name != "a" || list.Data[0].Params["id"] != float64(1) {
+		t.Errorf("case = %+v, body %+v, %v", list.Data[0], body, err)
+	}
+
+	ok := MockCase{Name: "ok", Code: 200, Params: map[string]interface{}{"id": 2}}
+	if err := ok.SetResBody(map[string]string{"name": "b"}); err != nil {
+		t.Fatal(err)
+	}
+	missing := MockCase{Name: "missing", Code: 404, Headers: []MockHeader{{Name: "X-Reason", Value: "gone"}}}
+	cases, err := client.Mock.SyncCases(7, []MockCase{ok, missing})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cases) != 2 || cases[0].ID != 1 || cases[1].ID != 20 || cases[1].InterfaceID != 7 {
+		t.Errorf("cases = %+v", cases)
+	}
+	if len(saved) != 2 || saved[0]["id"] != float64(1) || saved[0]["_id"] != nil || saved[1]["id"] != nil {
+		t.Errorf("saved = %v", saved)
+	}
+	if headers, _ := saved[0]["headers"].([]interface{}); headers == nil {
+		t.Errorf("headers of %v should be an empty list", saved[0])
+	}
+	sort.Ints(deleted)
+	if len(deleted) != 2 || deleted[0] != 2 || deleted[1] != 3 {
+		t.Errorf("deleted = %v", deleted)
+	}
+}
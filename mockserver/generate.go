@@ -0,0 +1,140 @@
+package mockserver
+
+import (
+	"math"
+	"strings"
+	"time"
+
+	yapi "github.com/micrease/go-yapi"
+)
+
+// Schema generates a value conforming to s. The YApi "mock" extension takes
+// precedence, then enum, then the declared type and format.
+func (g *Generator) Schema(s *yapi.Schema) interface{} {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.schema(s)
+}
+
+func (g *Generator) schema(s *yapi.Schema) interface{} {
+	if s == nil {
+		return nil
+	}
+	if s.Mock != nil && s.Mock.Mock != "" {
+		return g.placeholder(s.Mock.Mock)
+	}
+	if len(s.Enum) > 0 {
+		return s.Enum[g.rnd.Intn(len(s.Enum))]
+	}
+	switch schemaType(s) {
+	case "object":
+		out := make(map[string]interface{}, len(s.Properties))
+		for _, name := range s.PropertyNames() {
+			out[name] = g.schema(s.Properties[name])
+		}
+		return out
+	case "array":
+		min, max := 1, 3
+		if s.MinItems != nil {
+			min = clamp(*s.MinItems, 0, maxLength)
+			if max < min {
+				max = min
+			}
+		}
+		if s.MaxItems != nil {
+			max = clamp(*s.MaxItems, 0, maxLength)
+		}
+		out := make([]interface{}, g.between(min, max))
+		for i := range out {
+			out[i] = g.schema(s.Items)
+		}
+		return out
+	case "integer":
+		min, max := bounds(s, 0, 1000)
+		return g.between(int(math.Ceil(min)), int(math.Floor(max)))
+	case "number":
+		min, max := bounds(s, 0, 1000)
+		return math.Round((min+g.rnd.Float64()*(max-min))*100) / 100
+	case "boolean":
+		return g.rnd.Intn(2) == 0
+	case "null":
+		return nil
+	case "string":
+		return g.formatted(s)
+	}
+	return nil
+}
+
+// schemaType returns the type of s, picking the first non-null type of a
+// type list and inferring object or array from the other keywords.
+func schemaType(s *yapi.Schema) string {
+	for _, t := range s.Types() {
+		if t != "null" {
+			return t
+		}
+	}
+	if s.Properties != nil {
+		return "object"
+	}
+	if s.Items != nil {
+		return "array"
+	}
+	return ""
+}
+
+func bounds(s *yapi.Schema, min, max float64) (float64, float64) {
+	if s.Minimum != nil {
+		min = *s.Minimum
+		if max < min {
+			max = min + 1000
+		}
+	}
+	if s.Maximum != nil {
+		max = *s.Maximum
+		if min > max {
+			min = max - 1000
+		}
+	}
+	return min, max
+}
+
+func (g *Generator) formatted(s *yapi.Schema) string {
+	switch strings.ToLower(s.Format) {
+	case "date-time":
+		return g.randomTime().Format(time.RFC3339)
+	case "date":
+		return g.randomTime().Format("2006-01-02")
+	case "time":
+		return g.randomTime().Format("15:04:05")
+	case "email":
+		return g.call1("email")
+	case "uri", "url":
+		return g.call1("url")
+	case "uuid":
+		return g.uuid()
+	case "ipv4":
+		return g.call1("ip")
+	case "hostname":
+		return g.call1("domain")
+	}
+	min, max := 3, 10
+	if s.MinLength != nil {
+		min = clamp(*s.MinLength, 0, maxLength)
+		if max < min {
+			max = min
+		}
+	}
+	if s.MaxLength != nil {
+		max = clamp(*s.MaxLength, 0, maxLength)
+		if min > max {
+			min = max
+		}
+	}
+	return g.letters(g.between(min, max))
+}
+
+func (g *Generator) call1(name string) string {
+	v, _ := g.call(name, nil)
+	s, _ := v.(string)
+	return s
+}
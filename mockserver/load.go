@@ -0,0 +1,26 @@
+package mockserver
+
+import (
+	yapi "github.com/micrease/go-yapi"
+)
+
+// LoadFile reads the interfaces of a YApi "json" data export file.
+func LoadFile(path string) ([]yapi.InterfaceData, error) {
+	export, err := yapi.ReadExportFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return export.Interfaces(), nil
+}
+
+// LoadProject fetches every interface of the project the client token belongs to.
+func LoadProject(c *yapi.Client) ([]yapi.InterfaceData, error) {
+	project, err := c.Project.Get()
+	if err != nil {
+		return nil, err
+	}
+	if err := yapi.CheckErrCode(project.ErrCode, project.ErrMsg); err != nil {
+		return nil, err
+	}
+	return c.Interface.GetAll(project.Data.ID)
+}
@@ -0,0 +1,528 @@
+package mockserver
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Generator produces random data following Mock.js placeholders ("@string",
+// "@integer(1,10)") and data template rules ("list|1-10"). It is safe for
+// concurrent use.
+type Generator struct {
+	mu        sync.Mutex
+	rnd       *rand.Rand
+	increment int
+}
+
+// NewGenerator returns a Generator seeded with seed, so that the same
+// sequence of calls produces the same data.
+func NewGenerator(seed int64) *Generator {
+	return &Generator{rnd: rand.New(rand.NewSource(seed))}
+}
+
+var (
+	placeholderRe = regexp.MustCompile(`@([a-zA-Z]+)(?:\(([^)]*)\))?`)
+	ruleRe        = regexp.MustCompile(`^(?:\+(\d+)|(\d+)(?:-(\d+))?(?:\.(\d+)(?:-(\d+))?)?)$`)
+)
+
+const (
+	// maxLength bounds the lengths and repeat counts read from placeholders
+	// and rules, maxDigits the decimal places of generated floats.
+	maxLength = 10000
+	maxDigits = 15
+)
+
+const (
+	lowerChars  = "abcdefghijklmnopqrstuvwxyz"
+	upperChars  = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	digitChars  = "0123456789"
+	symbolChars = "!@#$%^&*()[]"
+)
+
+var (
+	firstNames  = []string{"James", "John", "Robert", "Michael", "William", "David", "Mary", "Patricia", "Linda", "Barbara", "Elizabeth", "Jennifer"}
+	lastNames   = []string{"Smith", "Johnson", "Williams", "Brown", "Jones", "Miller", "Davis", "Garcia", "Wilson", "Moore", "Taylor", "Clark"}
+	cFirstNames = []string{"王", "李", "张", "刘", "陈", "杨", "赵", "黄", "周", "吴", "徐", "孙"}
+	cLastNames  = []string{"伟", "芳", "娜", "秀英", "敏", "静", "丽", "强", "磊", "军", "洋", "勇"}
+	cChars      = []rune("的一是在不了有和人这中大为上个国我以要他时来用们生到作地于出就分对成会可主发年动同工也能下过子说产种面而方后多定行学法所民得经")
+	provinces   = []string{"北京", "上海", "天津", "重庆", "广东省", "浙江省", "江苏省", "四川省", "湖北省", "山东省"}
+	cities      = []string{"北京市", "上海市", "广州市", "深圳市", "杭州市", "南京市", "成都市", "武汉市", "济南市", "天津市"}
+	domains     = []string{"com", "net", "org", "cn", "io"}
+)
+
+// Placeholder resolves the Mock.js placeholders in s. A string consisting of a
+// single placeholder yields a typed value (e.g. a number for "@integer"),
+// otherwise every placeholder is substituted in the string. Unknown
+// placeholders are left untouched.
+func (g *Generator) Placeholder(s string) interface{} {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.placeholder(s)
+}
+
+// Template evaluates a Mock.js data template, as decoded by encoding/json.
+func (g *Generator) Template(tpl interface{}) interface{} {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.template(tpl)
+}
+
+func (g *Generator) placeholder(s string) interface{} {
+	if m := placeholderRe.FindStringSubmatchIndex(s); m != nil && m[0] == 0 && m[1] == len(s) {
+		name := s[m[2]:m[3]]
+		var args []string
+		if m[4] >= 0 {
+			args = splitArgs(s[m[4]:m[5]])
+		}
+		if v, ok := g.call(name, args); ok {
+			return v
+		}
+		return s
+	}
+	return placeholderRe.ReplaceAllStringFunc(s, func(match string) string {
+		sub := placeholderRe.FindStringSubmatch(match)
+		v, ok := g.call(sub[1], splitArgs(sub[2]))
+		if !ok {
+			return match
+		}
+		return fmt.Sprint(v)
+	})
+}
+
+func splitArgs(s string) []string {
+	if strings.TrimSpace(s) == "" {
+		return nil
+	}
+	args := strings.Split(s, ",")
+	for i, a := range args {
+		args[i] = strings.Trim(strings.TrimSpace(a), `"'`)
+	}
+	return args
+}
+
+func intArg(args []string, i int, def int) int {
+	if i < len(args) {
+		if v, err := strconv.Atoi(args[i]); err == nil {
+			return v
+		}
+	}
+	return def
+}
+
+func (g *Generator) call(name string, args []string) (interface{}, bool) {
+	switch strings.ToLower(name) {
+	case "boolean", "bool":
+		return g.rnd.Intn(2) == 0, true
+	case "natural":
+		return g.between(intArg(args, 0, 0), intArg(args, 1, 10000)), true
+	case "integer", "int":
+		return g.between(intArg(args, 0, -10000), intArg(args, 1, 10000)), true
+	case "float":
+		return g.float(intArg(args, 0, -1000), intArg(args, 1, 1000), intArg(args, 2, 0), intArg(args, 3, 3)), true
+	case "character", "char":
+		pool := lowerChars + upperChars + digitChars + symbolChars
+		if len(args) > 0 && args[0] != "" {
+			pool = charPool(args[0])
+		}
+		return string(pool[g.rnd.Intn(len(pool))]), true
+	case "string", "str":
+		return g.stringArgs(args), true
+	case "word":
+		return g.letters(g.lengthArgs(args, 3, 10)), true
+	case "cword":
+		return g.cletters(g.lengthArgs(args, 1, 1)), true
+	case "title":
+		return g.words(g.lengthArgs(args, 3, 7), true), true
+	case "ctitle":
+		return g.cletters(g.lengthArgs(args, 3, 7)), true
+	case "sentence":
+		return g.words(g.lengthArgs(args, 12, 18), false) + ".", true
+	case "csentence":
+		return g.cletters(g.lengthArgs(args, 12, 18)) + "。", true
+	case "paragraph":
+		n := g.lengthArgs(args, 3, 7)
+		parts := make([]string, n)
+		for i := range parts {
+			parts[i] = g.words(g.between(12, 18), false) + "."
+		}
+		return strings.Join(parts, " "), true
+	case "cparagraph":
+		n := g.lengthArgs(args, 3, 7)
+		var b strings.Builder
+		for i := 0; i < n; i++ {
+			b.WriteString(g.cletters(g.between(12, 18)) + "。")
+		}
+		return b.String(), true
+	case "first":
+		return g.pick(firstNames), true
+	case "last":
+		return g.pick(lastNames), true
+	case "name":
+		return g.pick(firstNames) + " " + g.pick(lastNames), true
+	case "cfirst":
+		return g.pick(cFirstNames), true
+	case "clast":
+		return g.pick(cLastNames), true
+	case "cname":
+		return g.pick(cFirstNames) + g.pick(cLastNames), true
+	case "domain":
+		return g.letters(g.between(3, 8)) + "." + g.pick(domains), true
+	case "url":
+		scheme := "http"
+		if len(args) > 0 {
+			scheme = args[0]
+		}
+		return scheme + "://" + g.letters(g.between(3, 8)) + "." + g.pick(domains) + "/" + g.letters(g.between(3, 8)), true
+	case "email":
+		return g.letters(g.between(3, 8)) + "@" + g.letters(g.between(3, 8)) + "." + g.pick(domains), true
+	case "ip":
+		return fmt.Sprintf("%d.%d.%d.%d", g.rnd.Intn(256), g.rnd.Intn(256), g.rnd.Intn(256), g.rnd.Intn(256)), true
+	case "guid", "uuid":
+		return g.uuid(), true
+	case "id":
+		return g.digits(18), true
+	case "zip":
+		return g.digits(6), true
+	case "increment":
+		g.increment += intArg(args, 0, 1)
+		return g.increment, true
+	case "color", "hex":
+		return fmt.Sprintf("#%06x", g.rnd.Intn(1<<24)), true
+	case "rgb":
+		return fmt.Sprintf("rgb(%d, %d, %d)", g.rnd.Intn(256), g.rnd.Intn(256), g.rnd.Intn(256)), true
+	case "date":
+		return formatDate(g.randomTime(), argOr(args, 0, "yyyy-MM-dd")), true
+	case "time":
+		return formatDate(g.randomTime(), argOr(args, 0, "HH:mm:ss")), true
+	case "datetime":
+		return formatDate(g.randomTime(), argOr(args, 0, "yyyy-MM-dd HH:mm:ss")), true
+	case "now":
+		return formatDate(time.Now(), argOr(args, 1, argOr(args, 0, "yyyy-MM-dd HH:mm:ss"))), true
+	case "timestamp":
+		return g.randomTime().Unix(), true
+	case "image", "img":
+		size := argOr(args, 0, fmt.Sprintf("%dx%d", g.between(100, 400), g.between(100, 400)))
+		return "http://dummyimage.com/" + size, true
+	case "province":
+		return g.pick(provinces), true
+	case "city":
+		return g.pick(cities), true
+	case "pick":
+		if len(args) == 0 {
+			return nil, false
+		}
+		return g.pick(args), true
+	case "upper":
+		return strings.ToUpper(argOr(args, 0, "")), true
+	case "lower":
+		return strings.ToLower(argOr(args, 0, "")), true
+	}
+	return nil, false
+}
+
+func argOr(args []string, i int, def string) string {
+	if i < len(args) && args[i] != "" {
+		return args[i]
+	}
+	return def
+}
+
+func charPool(name string) string {
+	switch name {
+	case "lower":
+		return lowerChars
+	case "upper":
+		return upperChars
+	case "number":
+		return digitChars
+	case "symbol":
+		return symbolChars
+	case "alpha":
+		return lowerChars + upperChars
+	}
+	return name
+}
+
+// stringArgs implements @string([pool,] [min,] [max]).
+func (g *Generator) stringArgs(args []string) string {
+	pool := lowerChars
+	if len(args) > 0 {
+		if _, err := strconv.Atoi(args[0]); err != nil {
+			if args[0] != "" {
+				pool = charPool(args[0])
+			}
+			args = args[1:]
+		}
+	}
+	n := g.lengthArgs(args, 3, 7)
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = pool[g.rnd.Intn(len(pool))]
+	}
+	return string(b)
+}
+
+// lengthArgs reads the (len) or (min, max) arguments shared by text
+// placeholders. The length is kept within [0, maxLength].
+func (g *Generator) lengthArgs(args []string, min, max int) int {
+	switch len(args) {
+	case 0:
+		return g.between(min, max)
+	case 1:
+		return clamp(intArg(args, 0, min), 0, maxLength)
+	}
+	return g.between(clamp(intArg(args, 0, min), 0, maxLength), clamp(intArg(args, 1, max), 0, maxLength))
+}
+
+func (g *Generator) between(min, max int) int {
+	if max <= min {
+		return min
+	}
+	span := max - min + 1
+	if span <= 0 {
+		// The range overflows int, draw from its lower part.
+		span = math.MaxInt32
+	}
+	return min + g.rnd.Intn(span)
+}
+
+func clamp(n, min, max int) int {
+	if n < min {
+		return min
+	}
+	if n > max {
+		return max
+	}
+	return n
+}
+
+func (g *Generator) float(min, max, dmin, dmax int) float64 {
+	whole := float64(g.between(min, max))
+	digits := g.between(clamp(dmin, 0, maxDigits), clamp(dmax, 0, maxDigits))
+	if digits == 0 {
+		return whole
+	}
+	scale := math.Pow10(digits)
+	frac := float64(g.between(1, int(scale)-1)) / scale
+	if whole < 0 {
+		return whole - frac
+	}
+	return whole + frac
+}
+
+func (g *Generator) pick(values []string) string {
+	return values[g.rnd.Intn(len(values))]
+}
+
+func (g *Generator) letters(n int) string {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = lowerChars[g.rnd.Intn(len(lowerChars))]
+	}
+	return string(b)
+}
+
+func (g *Generator) cletters(n int) string {
+	r := make([]rune, n)
+	for i := range r {
+		r[i] = cChars[g.rnd.Intn(len(cChars))]
+	}
+	return string(r)
+}
+
+func (g *Generator) words(n int, title bool) string {
+	words := make([]string, n)
+	for i := range words {
+		words[i] = g.letters(g.between(1, 10))
+		if title || i == 0 {
+			words[i] = strings.ToUpper(words[i][:1]) + words[i][1:]
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+func (g *Generator) digits(n int) string {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = digitChars[g.rnd.Intn(len(digitChars))]
+	}
+	if n > 0 && b[0] == '0' {
+		b[0] = '1'
+	}
+	return string(b)
+}
+
+func (g *Generator) uuid() string {
+	b := make([]byte, 16)
+	g.rnd.Read(b)
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+func (g *Generator) randomTime() time.Time {
+	start := time.Date(2000, 1, 1, 0, 0, 0, 0, time.Local).Unix()
+	end := time.Date(2030, 1, 1, 0, 0, 0, 0, time.Local).Unix()
+	return time.Unix(start+g.rnd.Int63n(end-start), 0)
+}
+
+// formatDate formats t with a Mock.js date pattern such as "yyyy-MM-dd HH:mm:ss".
+func formatDate(t time.Time, pattern string) string {
+	r := strings.NewReplacer(
+		"yyyy", "2006", "yy", "06",
+		"MM", "01", "M", "1",
+		"dd", "02", "d", "2",
+		"HH", "15", "H", "15",
+		"hh", "03", "h", "3",
+		"mm", "04", "ss", "05",
+		"SS", "000", "A", "PM", "a", "pm",
+	)
+	return t.Format(r.Replace(pattern))
+}
+
+// template evaluates a data template value.
+func (g *Generator) template(tpl interface{}) interface{} {
+	switch v := tpl.(type) {
+	case map[string]interface{}:
+		return g.object(v)
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = g.template(item)
+		}
+		return out
+	case string:
+		return g.placeholder(v)
+	}
+	return tpl
+}
+
+func (g *Generator) object(tpl map[string]interface{}) map[string]interface{} {
+	// walk the keys in a fixed order so a seeded generator is deterministic
+	keys := make([]string, 0, len(tpl))
+	for key := range tpl {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	out := make(map[string]interface{}, len(tpl))
+	for _, key := range keys {
+		value := tpl[key]
+		name, rule := key, ""
+		if i := strings.Index(key, "|"); i >= 0 {
+			name, rule = key[:i], key[i+1:]
+		}
+		out[name] = g.applyRule(rule, value)
+	}
+	return out
+}
+
+type rule struct {
+	step       int
+	min, max   int
+	dmin, dmax int
+	hasRange   bool
+	hasDecimal bool
+}
+
+func parseRule(s string) (rule, bool) {
+	m := ruleRe.FindStringSubmatch(s)
+	if m == nil {
+		return rule{}, false
+	}
+	var r rule
+	if m[1] != "" {
+		r.step, _ = strconv.Atoi(m[1])
+		return r, true
+	}
+	r.min, _ = strconv.Atoi(m[2])
+	r.max = r.min
+	if m[3] != "" {
+		r.max, _ = strconv.Atoi(m[3])
+		r.hasRange = true
+	}
+	if m[4] != "" {
+		r.hasDecimal = true
+		r.dmin, _ = strconv.Atoi(m[4])
+		r.dmax = r.dmin
+		if m[5] != "" {
+			r.dmax, _ = strconv.Atoi(m[5])
+		}
+	}
+	return r, true
+}
+
+// applyRule implements the "name|rule" generation rules of Mock.js.
+func (g *Generator) applyRule(s string, value interface{}) interface{} {
+	r, ok := parseRule(s)
+	if s == "" || !ok {
+		return g.template(value)
+	}
+	count := g.between(r.min, r.max)
+	repeat := clamp(count, 0, maxLength)
+	switch v := value.(type) {
+	case string:
+		var b strings.Builder
+		for i := 0; i < repeat; i++ {
+			b.WriteString(fmt.Sprint(g.placeholder(v)))
+		}
+		return b.String()
+	case float64:
+		if r.step > 0 {
+			g.increment += r.step
+			return v + float64(g.increment-r.step)
+		}
+		if r.hasDecimal {
+			return g.float(r.min, r.max, r.dmin, r.dmax)
+		}
+		return count
+	case bool:
+		if !r.hasRange || r.min+r.max <= 0 {
+			return g.rnd.Intn(2) == 0
+		}
+		if g.rnd.Intn(r.min+r.max) < r.min {
+			return v
+		}
+		return !v
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		g.rnd.Shuffle(len(keys), func(i, j int) { keys[i], keys[j] = keys[j], keys[i] })
+		if count > len(keys) {
+			count = len(keys)
+		}
+		sub := make(map[string]interface{}, count)
+		for _, key := range keys[:count] {
+			sub[key] = v[key]
+		}
+		return g.object(sub)
+	case []interface{}:
+		if len(v) == 0 {
+			return v
+		}
+		if r.step > 0 {
+			g.increment += r.step
+			return g.template(v[(g.increment-r.step)%len(v)])
+		}
+		if !r.hasRange && r.min == 1 {
+			return g.template(v[g.rnd.Intn(len(v))])
+		}
+		out := make([]interface{}, 0, repeat*len(v))
+		for i := 0; i < repeat; i++ {
+			for _, item := range v {
+				out = append(out, g.template(item))
+			}
+		}
+		return out
+	}
+	return g.template(value)
+}
@@ -0,0 +1,107 @@
+// Package mockserver serves mock responses for YApi interface definitions
+// without access to the YApi host. Requests are matched on method and path,
+// including {param} and :param path params, and responses are generated from
+// the response body JSON Schema or Mock.js template.
+package mockserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	yapi "github.com/micrease/go-yapi"
+	"github.com/micrease/go-yapi/internal/route"
+)
+
+// Options configures a Server.
+type Options struct {
+	// Seed of the random data generator.
+	Seed int64
+
+	// Prefix is stripped from request paths before matching,
+	// e.g. "/mock/11/api" to mirror the YApi mock URL layout.
+	Prefix string
+}
+
+// Server is an http.Handler answering requests with mock data.
+type Server struct {
+	interfaces []yapi.InterfaceData
+	table      route.Table
+	gen        *Generator
+	prefix     string
+}
+
+// New returns a Server for interfaces. opts may be nil.
+func New(interfaces []yapi.InterfaceData, opts *Options) *Server {
+	if opts == nil {
+		opts = &Options{}
+	}
+	s := &Server{
+		interfaces: interfaces,
+		gen:        NewGenerator(opts.Seed),
+		prefix:     strings.TrimRight(opts.Prefix, "/"),
+	}
+	for i, d := range interfaces {
+		s.table.Add(d.Method, d.Path, i)
+	}
+	return s
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := r.URL.Path
+	if s.prefix != "" {
+		if !strings.HasPrefix(path, s.prefix) || (len(path) > len(s.prefix) && path[len(s.prefix)] != '/') {
+			writeError(w, http.StatusNotFound, "不存在的api")
+			return
+		}
+		path = strings.TrimPrefix(path, s.prefix)
+	}
+
+	index, _, found := s.table.Match(r.Method, path)
+	if index < 0 {
+		if found {
+			writeError(w, http.StatusMethodNotAllowed, "请求方法不匹配")
+			return
+		}
+		writeError(w, http.StatusNotFound, "不存在的api")
+		return
+	}
+
+	body, contentType, err := s.Response(&s.interfaces[index])
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Write(body)
+}
+
+// Response generates a response body for d and returns it with its content type.
+func (s *Server) Response(d *yapi.InterfaceData) ([]byte, string, error) {
+	if d.ResBodyType != "" && d.ResBodyType != "json" {
+		return []byte(d.ResBody), "text/plain; charset=utf-8", nil
+	}
+	const contentType = "application/json; charset=utf-8"
+	if d.ResBodyIsJsonSchema {
+		schema, err := d.ResBodySchema()
+		if err != nil {
+			return nil, "", err
+		}
+		body, err := json.Marshal(s.gen.Schema(schema))
+		return body, contentType, err
+	}
+	var tpl interface{}
+	if err := json.Unmarshal([]byte(d.ResBody), &tpl); err != nil {
+		// not a JSON template, serve it verbatim
+		return []byte(d.ResBody), contentType, nil
+	}
+	body, err := json.Marshal(s.gen.Template(tpl))
+	return body, contentType, err
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(yapi.CommonResp{ErrCode: status, ErrMsg: msg})
+}
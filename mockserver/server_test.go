@@ -0,0 +1,193 @@
+package mockserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"regexp"
+	"testing"
+
+	yapi "github.com/micrease/go-yapi"
+)
+
+func testInterfaces() []yapi.InterfaceData {
+	var user, list, raw, text yapi.InterfaceData
+	user.Method, user.Path = "GET", "/api/users/{id}"
+	user.ResBodyIsJsonSchema = true
+	user.ResBody = `{"type":"object","properties":{
+		"id":{"type":"integer","minimum":1,"maximum":5},
+		"email":{"type":"string","format":"email"},
+		"nick":{"type":"string","mock":{"mock":"@pick(foo,bar)"}},
+		"tags":{"type":"array","minItems":2,"maxItems":2,"items":{"type":"string","enum":["a","b"]}}
+	},"required":["id"]}`
+
+	list.Method, list.Path = "GET", "/api/users/:id/orders"
+	list.ResBody = `{"code":0,"data|3":[{"id|+1":1,"price|1-10.2":1,"ok|1":true}]}`
+
+	raw.Method, raw.Path = "POST", "/api/users/me"
+	raw.ResBody = `{"name":"@first","n":"@integer(7,7)"}`
+
+	text.Method, text.Path = "GET", "/ping"
+	text.ResBodyType = "raw"
+	text.ResBody = "pong"
+	return []yapi.InterfaceData{user, list, raw, text}
+}
+
+func serve(t *testing.T, s *Server, method, path string) (*httptest.ResponseRecorder, map[string]interface{}) {
+	req := httptest.NewRequest(method, path, nil)
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	var body map[string]interface{}
+	if w.Header().Get("Content-Type") == "application/json; charset=utf-8" {
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatalf("%s %s: invalid JSON %q: %v", method, path, w.Body.String(), err)
+		}
+	}
+	return w, body
+}
+
+func TestServer_Schema(t *testing.T) {
+	s := New(testInterfaces(), nil)
+	w, body := serve(t, s, "GET", "/api/users/42")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+	if id, _ := body["id"].(float64); id < 1 || id > 5 {
+		t.Errorf("id = %v, want 1..5", body["id"])
+	}
+	if email, _ := body["email"].(string); !regexp.MustCompile(`^\w+@\w+\.\w+$`).MatchString(email) {
+		t.Errorf("email = %v, want an email address", body["email"])
+	}
+	if nick := body["nick"]; nick != "foo" && nick != "bar" {
+		t.Errorf("nick = %v, want foo or bar", nick)
+	}
+	if tags, _ := body["tags"].([]interface{}); len(tags) != 2 {
+		t.Errorf("tags = %v, want 2 items", body["tags"])
+	}
+}
+
+func TestServer_Template(t *testing.T) {
+	s := New(testInterfaces(), &Options{Prefix: "/mock/11"})
+	_, body := serve(t, s, "GET", "/mock/11/api/users/1/orders")
+	data, _ := body["data"].([]interface{})
+	if len(data) != 3 {
+		t.Fatalf("data = %v, want 3 items", body["data"])
+	}
+	for i, item := range data {
+		order := item.(map[string]interface{})
+		if order["id"] != float64(i+1) {
+			t.Errorf("data[%d].id = %v, want %d", i, order["id"], i+1)
+		}
+		if price := order["price"].(float64); price < 1 || price >= 11 {
+			t.Errorf("data[%d].price = %v, want 1..10", i, price)
+		}
+		if _, ok := order["ok"].(bool); !ok {
+			t.Errorf("data[%d].ok = %v, want a bool", i, order["ok"])
+		}
+	}
+
+	_, body = serve(t, s, "POST", "/mock/11/api/users/me")
+	if body["n"] != float64(7) {
+		t.Errorf("n = %v, want 7", body["n"])
+	}
+	if name, _ := body["name"].(string); name == "" || name == "@first" {
+		t.Errorf("name = %v, want a generated first name", body["name"])
+	}
+}
+
+func TestServer_Routing(t *testing.T) {
+	s := New(testInterfaces(), nil)
+	if w, _ := serve(t, s, "GET", "/ping"); w.Body.String() != "pong" {
+		t.Errorf("GET /ping = %q, want pong", w.Body.String())
+	}
+	// the static "me" segment wins over the {id} param
+	if w, _ := serve(t, s, "POST", "/api/users/me"); w.Code != http.StatusOK {
+		t.Errorf("POST /api/users/me status = %d, want 200", w.Code)
+	}
+	if w, _ := serve(t, s, "DELETE", "/api/users/42"); w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("DELETE status = %d, want 405", w.Code)
+	}
+	if w, body := serve(t, s, "GET", "/nope"); w.Code != http.StatusNotFound || body["errcode"] != float64(404) {
+		t.Errorf("GET /nope = %d %v, want 404", w.Code, body)
+	}
+}
+
+func TestServer_Prefix(t *testing.T) {
+	s := New(testInterfaces(), &Options{Prefix: "/mock/11"})
+	if w, _ := serve(t, s, "GET", "/mock/11/ping"); w.Body.String() != "pong" {
+		t.Errorf("GET /mock/11/ping = %q, want pong", w.Body.String())
+	}
+	if w, _ := serve(t, s, "GET", "/mock/110/ping"); w.Code != http.StatusNotFound {
+		t.Errorf("GET /mock/110/ping status = %d, want 404", w.Code)
+	}
+}
+
+func TestGenerator_BadArguments(t *testing.T) {
+	for _, tpl := range []interface{}{
+		"@string('')",
+		"@string(,2)",
+		"@character('')",
+		"@string(-1)",
+		"@string(-5,-1)",
+		"@word(-2)",
+		"@cword(-2, -1)",
+		"@paragraph(-1)",
+		"@float(1, 2, 400, 500)",
+		"@float(1, 2, -3, -1)",
+		map[string]interface{}{"ok|0-0": true},
+		map[string]interface{}{"n|1-100000": 1},
+		map[string]interface{}{"s|99999999999": "a"},
+		map[string]interface{}{"list|99999999999": []interface{}{}},
+	} {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					t.Errorf("Template(%v) panicked: %v", tpl, r)
+				}
+			}()
+			NewGenerator(1).Template(tpl)
+		}()
+	}
+}
+
+func TestGenerator_SchemaBounds(t *testing.T) {
+	for _, src := range []string{
+		`{"type":"string","maxLength":-1}`,
+		`{"type":"string","minLength":-5,"maxLength":-1}`,
+		`{"type":"string","minLength":99999999999}`,
+		`{"type":"array","maxItems":-1,"items":{"type":"integer"}}`,
+		`{"type":"array","minItems":99999999999,"items":{"type":"integer"}}`,
+	} {
+		s, err := yapi.ParseSchema(src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					t.Errorf("Schema(%s) panicked: %v", src, r)
+				}
+			}()
+			switch v := NewGenerator(1).Schema(s).(type) {
+			case string:
+				if len(v) > maxLength {
+					t.Errorf("Schema(%s) has length %d", src, len(v))
+				}
+			case []interface{}:
+				if len(v) > maxLength {
+					t.Errorf("Schema(%s) has %d items", src, len(v))
+				}
+			}
+		}()
+	}
+}
+
+func TestGenerator_Seed(t *testing.T) {
+	tpl := map[string]interface{}{"a": "@string", "b|1-100": 1, "c": "@guid"}
+	a := NewGenerator(7).Template(tpl)
+	b := NewGenerator(7).Template(tpl)
+	if !reflect.DeepEqual(a, b) {
+		t.Errorf("same seed produced different data: %v != %v", a, b)
+	}
+}
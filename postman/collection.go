@@ -0,0 +1,201 @@
+// Package postman converts between Postman Collection v2.1 files and the
+// go-yapi model: folders are categories and requests are interfaces.
+package postman
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+// SchemaURL identifies the Postman Collection v2.1 format.
+const SchemaURL = "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"
+
+// Collection is a Postman collection. Only the parts that have a YApi
+// counterpart are modeled.
+type Collection struct {
+	Info     Info       `json:"info"`
+	Item     []Item     `json:"item"`
+	Variable []Variable `json:"variable,omitempty"`
+}
+
+// Info describes a collection.
+type Info struct {
+	PostmanID   string      `json:"_postman_id,omitempty"`
+	Name        string      `json:"name"`
+	Description Description `json:"description,omitempty"`
+	Schema      string      `json:"schema"`
+}
+
+// Item is a folder when Item is set and a request otherwise.
+type Item struct {
+	Name        string      `json:"name"`
+	Description Description `json:"description,omitempty"`
+	Item        []Item      `json:"item,omitempty"`
+	Request     *Request    `json:"request,omitempty"`
+	Response    []Response  `json:"response,omitempty"`
+}
+
+// IsFolder reports whether the item is a folder.
+func (i *Item) IsFolder() bool {
+	return i.Request == nil
+}
+
+// Request is the request of an item.
+type Request struct {
+	Method      string      `json:"method"`
+	Header      []KeyValue  `json:"header"`
+	Body        *Body       `json:"body,omitempty"`
+	URL         URL         `json:"url"`
+	Description Description `json:"description,omitempty"`
+}
+
+// KeyValue is a header, query parameter or form field.
+type KeyValue struct {
+	Key         string      `json:"key"`
+	Value       string      `json:"value"`
+	Description Description `json:"description,omitempty"`
+	Disabled    bool        `json:"disabled,omitempty"`
+	// Type is "text" or "file" for form data fields.
+	Type string `json:"type,omitempty"`
+}
+
+// Variable is a path variable or a collection variable.
+type Variable struct {
+	Key         string      `json:"key"`
+	Value       string      `json:"value"`
+	Description Description `json:"description,omitempty"`
+}
+
+// Body is a request body. Mode is "raw", "urlencoded", "formdata" or "file".
+type Body struct {
+	Mode       string       `json:"mode"`
+	Raw        string       `json:"raw,omitempty"`
+	URLEncoded []KeyValue   `json:"urlencoded,omitempty"`
+	FormData   []KeyValue   `json:"formdata,omitempty"`
+	Options    *BodyOptions `json:"options,omitempty"`
+}
+
+// BodyOptions holds the language of a raw body.
+type BodyOptions struct {
+	Raw struct {
+		Language string `json:"language"`
+	} `json:"raw"`
+}
+
+// Response is an example response saved with a request.
+type Response struct {
+	Name   string     `json:"name"`
+	Status string     `json:"status,omitempty"`
+	Code   int        `json:"code,omitempty"`
+	Header []KeyValue `json:"header,omitempty"`
+	Body   string     `json:"body,omitempty"`
+}
+
+// URL is a request URL. Collections may store it as a plain string, which
+// only sets Raw.
+type URL struct {
+	Raw      string     `json:"raw"`
+	Host     []string   `json:"host,omitempty"`
+	Path     []string   `json:"path,omitempty"`
+	Query    []KeyValue `json:"query,omitempty"`
+	Variable []Variable `json:"variable,omitempty"`
+}
+
+// UnmarshalJSON accepts a string, and host and path given as strings.
+func (u *URL) UnmarshalJSON(data []byte) error {
+	var raw string
+	if json.Unmarshal(data, &raw) == nil {
+		*u = URL{Raw: raw}
+		return nil
+	}
+	var v struct {
+		Raw      string          `json:"raw"`
+		Host     json.RawMessage `json:"host"`
+		Path     json.RawMessage `json:"path"`
+		Query    []KeyValue      `json:"query"`
+		Variable []Variable      `json:"variable"`
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*u = URL{Raw: v.Raw, Query: v.Query, Variable: v.Variable}
+	u.Host = stringOrList(v.Host, ".")
+	u.Path = stringOrList(v.Path, "/")
+	return nil
+}
+
+// stringOrList decodes a list of strings, or a string split on sep.
+func stringOrList(data json.RawMessage, sep string) []string {
+	var list []string
+	if json.Unmarshal(data, &list) == nil {
+		return list
+	}
+	var s string
+	if json.Unmarshal(data, &s) != nil || s == "" {
+		return nil
+	}
+	return strings.Split(strings.Trim(s, sep), sep)
+}
+
+// Description is a description, stored by Postman either as a string or as
+// an object with the text in "content".
+type Description string
+
+// UnmarshalJSON accepts a string or a description object.
+func (d *Description) UnmarshalJSON(data []byte) error {
+	var s string
+	if json.Unmarshal(data, &s) == nil {
+		*d = Description(s)
+		return nil
+	}
+	var v struct {
+		Content string `json:"content"`
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*d = Description(v.Content)
+	return nil
+}
+
+// Environment is a Postman environment file.
+type Environment struct {
+	ID     string             `json:"id,omitempty"`
+	Name   string             `json:"name"`
+	Values []EnvironmentValue `json:"values"`
+	Scope  string             `json:"_postman_variable_scope"`
+}
+
+// EnvironmentValue is a variable of an environment.
+type EnvironmentValue struct {
+	Key     string `json:"key"`
+	Value   string `json:"value"`
+	Type    string `json:"type,omitempty"`
+	Enabled bool   `json:"enabled"`
+}
+
+// Read decodes a collection.
+func Read(r io.Reader) (*Collection, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	c := new(Collection)
+	if err := json.Unmarshal(data, c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// ReadFile decodes the collection stored at path.
+func ReadFile(path string) (*Collection, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Read(f)
+}
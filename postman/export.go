@@ -0,0 +1,158 @@
+package postman
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	yapi "github.com/micrease/go-yapi"
+	"github.com/micrease/go-yapi/snapshot"
+)
+
+// BaseURLVariable is the variable holding the server address in exported
+// requests, set by the exported environments.
+const BaseURLVariable = "baseUrl"
+
+// Export converts a snapshot into a collection with a folder per category.
+// Request URLs start with {{baseUrl}} followed by the project base path.
+// JSON Schema bodies are replaced by an example generated from the schema,
+// and the response body is saved as an example response.
+func Export(s *snapshot.Snapshot) *Collection {
+	c := &Collection{Info: Info{Name: s.Project.Name, Schema: SchemaURL}, Item: []Item{}}
+	if len(s.Project.Env) > 0 {
+		c.Variable = []Variable{{Key: BaseURLVariable, Value: s.Project.Env[0].Domain}}
+	}
+	for _, cat := range s.Categories {
+		folder := Item{Name: cat.Name, Description: Description(cat.Desc), Item: []Item{}}
+		for i := range cat.Interfaces {
+			folder.Item = append(folder.Item, exportInterface(&cat.Interfaces[i], s.Project.Basepath))
+		}
+		c.Item = append(c.Item, folder)
+	}
+	return c
+}
+
+// Environments converts the environments of a project. The domain becomes
+// the baseUrl variable and the global variables are copied; the headers of
+// an environment have no Postman equivalent and are left out.
+func Environments(p yapi.ProjectData) []Environment {
+	var envs []Environment
+	for _, env := range p.Env {
+		e := Environment{ID: env.ID, Name: env.Name, Scope: "environment"}
+		e.Values = append(e.Values, EnvironmentValue{Key: BaseURLVariable, Value: env.Domain, Type: "default", Enabled: true})
+		for _, g := range env.Global {
+			e.Values = append(e.Values, EnvironmentValue{Key: g.Name, Value: g.Value, Type: "default", Enabled: true})
+		}
+		envs = append(envs, e)
+	}
+	return envs
+}
+
+// yapiPathParam matches a "{name}" or ":name" path parameter.
+var yapiPathParam = regexp.MustCompile(`^\{(\w+)\}$|^:(\w+)$`)
+
+func exportInterface(d *yapi.InterfaceData, basepath string) Item {
+	req := &Request{Method: strings.ToUpper(first(d.Method, "GET")), Header: []KeyValue{}}
+
+	params := map[string]yapi.ReqKVItemSimple{}
+	for _, p := range d.ReqParams {
+		params[p.Name] = p
+	}
+	u := URL{Host: []string{"{{" + BaseURLVariable + "}}"}}
+	for _, seg := range strings.Split(strings.Trim(strings.TrimRight(basepath, "/")+"/"+strings.TrimLeft(d.Path, "/"), "/"), "/") {
+		if seg == "" {
+			continue
+		}
+		if m := yapiPathParam.FindStringSubmatch(seg); m != nil {
+			name := m[1] + m[2]
+			seg = ":" + name
+			p := params[name]
+			u.Variable = append(u.Variable, Variable{Key: name, Value: first(p.Example, p.Value), Description: Description(p.Desc)})
+		}
+		u.Path = append(u.Path, seg)
+	}
+	var query []string
+	for _, q := range d.ReqQuery {
+		u.Query = append(u.Query, exportKV(q))
+		query = append(query, q.Name+"="+first(q.Example, q.Value))
+	}
+	u.Raw = strings.Join(u.Host, ".") + "/" + strings.Join(u.Path, "/")
+	if len(query) > 0 {
+		u.Raw += "?" + strings.Join(query, "&")
+	}
+	req.URL = u
+
+	for _, h := range d.ReqHeaders {
+		req.Header = append(req.Header, exportKV(h))
+	}
+
+	switch d.ReqBodyType {
+	case "form":
+		mode := "urlencoded"
+		var fields []KeyValue
+		for _, f := range d.ReqBodyForm {
+			kv := exportKV(f)
+			kv.Type = first(f.Type, "text")
+			if kv.Type == "file" {
+				mode = "formdata"
+			}
+			fields = append(fields, kv)
+		}
+		req.Body = &Body{Mode: mode}
+		if mode == "formdata" {
+			req.Body.FormData = fields
+		} else {
+			for i := range fields {
+				fields[i].Type = ""
+			}
+			req.Body.URLEncoded = fields
+		}
+	case "file":
+		req.Body = &Body{Mode: "file"}
+	case "json", "raw":
+		if text := bodyExample(d.ReqBodyOther, d.ReqBodyIsJsonSchema); text != "" {
+			req.Body = &Body{Mode: "raw", Raw: text}
+			if d.ReqBodyType == "json" {
+				req.Body.Options = &BodyOptions{}
+				req.Body.Options.Raw.Language = "json"
+			}
+		}
+	}
+
+	item := Item{Name: first(d.Title, req.Method+" "+d.Path), Request: req, Response: []Response{}}
+	if text := bodyExample(d.ResBody, d.ResBodyIsJsonSchema); text != "" {
+		resp := Response{Name: "Example", Status: "OK", Code: 200, Body: text}
+		if d.ResBodyType == "json" {
+			resp.Header = []KeyValue{{Key: "Content-Type", Value: "application/json"}}
+		}
+		item.Response = append(item.Response, resp)
+	}
+	return item
+}
+
+// exportKV converts a header or query parameter, preferring its fixed value
+// over its example. Optional parameters are disabled.
+func exportKV(kv yapi.ReqKVItemDetail) KeyValue {
+	return KeyValue{Key: kv.Name, Value: first(kv.Value, kv.Example), Description: Description(kv.Desc), Disabled: kv.Required == "0"}
+}
+
+// bodyExample returns the text of a body, generating an example from a JSON
+// Schema body.
+func bodyExample(text string, isSchema bool) string {
+	if strings.TrimSpace(text) == "" || !isSchema {
+		return text
+	}
+	s, err := yapi.ParseSchema(text)
+	if err != nil || s == nil {
+		return text
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(s.Example()); err != nil {
+		return text
+	}
+	return strings.TrimRight(buf.String(), "\n")
+}
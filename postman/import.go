@@ -0,0 +1,204 @@
+package postman
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	yapi "github.com/micrease/go-yapi"
+)
+
+// ImportOptions controls the conversion of a collection.
+type ImportOptions struct {
+	// Basepath is removed from the start of the request paths, as YApi
+	// stores it on the project.
+	Basepath string
+	// Category receives the requests that are not in a folder. It defaults
+	// to the collection name.
+	Category string
+}
+
+// Import converts a collection into the YApi "json" data export format.
+// Every folder becomes a category, nested folders are named
+// "parent / child". Of the saved responses of a request, the first one
+// becomes the response body.
+func Import(c *Collection, opts *ImportOptions) yapi.ExportData {
+	if opts == nil {
+		opts = &ImportOptions{}
+	}
+	var cats yapi.ExportData
+	root := yapi.ExportCat{Name: first(opts.Category, c.Info.Name, "default"), Desc: string(c.Info.Description)}
+	var walk func(prefix string, items []Item, cat *yapi.ExportCat)
+	walk = func(prefix string, items []Item, cat *yapi.ExportCat) {
+		for i := range items {
+			item := &items[i]
+			if !item.IsFolder() {
+				cat.List = append(cat.List, importRequest(item, opts))
+				continue
+			}
+			folder := yapi.ExportCat{Name: prefix + item.Name, Desc: string(item.Description)}
+			index := len(cats)
+			cats = append(cats, folder)
+			walk(prefix+item.Name+" / ", item.Item, &folder)
+			cats[index].List = folder.List
+		}
+	}
+	walk("", c.Item, &root)
+	if len(root.List) > 0 {
+		cats = append(yapi.ExportData{root}, cats...)
+	}
+	for i := range cats {
+		cats[i].Index = i
+		if cats[i].List == nil {
+			cats[i].List = []yapi.InterfaceData{}
+		}
+	}
+	return cats
+}
+
+// pathVariable matches a ":name" path segment or a "{{name}}" variable.
+var pathVariable = regexp.MustCompile(`^:(\w+)$|\{\{\s*(\w+)\s*\}\}`)
+
+func importRequest(item *Item, opts *ImportOptions) yapi.InterfaceData {
+	req := item.Request
+	var d yapi.InterfaceData
+	d.Title = item.Name
+	d.Method = strings.ToUpper(first(req.Method, "GET"))
+	d.Status = "undone"
+
+	u := req.URL
+	if len(u.Path) == 0 && u.Raw != "" {
+		parsed := parseRaw(u.Raw)
+		u.Path = parsed.Path
+		if len(u.Query) == 0 {
+			u.Query = parsed.Query
+		}
+	}
+	vars := map[string]Variable{}
+	for _, v := range u.Variable {
+		vars[v.Key] = v
+	}
+	segments := make([]string, len(u.Path))
+	for i, seg := range u.Path {
+		segments[i] = pathVariable.ReplaceAllStringFunc(seg, func(m string) string {
+			sub := pathVariable.FindStringSubmatch(m)
+			name := sub[1] + sub[2]
+			v := vars[name]
+			d.ReqParams = append(d.ReqParams, yapi.ReqKVItemSimple{Name: name, Example: v.Value, Desc: string(v.Description)})
+			return "{" + name + "}"
+		})
+	}
+	d.Path = "/" + strings.Join(segments, "/")
+	if base := strings.TrimRight(opts.Basepath, "/"); base != "" && (d.Path == base || strings.HasPrefix(d.Path, base+"/")) {
+		d.Path = "/" + strings.TrimLeft(d.Path[len(base):], "/")
+	}
+
+	for _, q := range u.Query {
+		d.ReqQuery = append(d.ReqQuery, kvItem(q, ""))
+	}
+	for _, h := range req.Header {
+		item := kvItem(h, "")
+		item.Value, item.Example = h.Value, ""
+		d.ReqHeaders = append(d.ReqHeaders, item)
+	}
+
+	if b := req.Body; b != nil {
+		switch b.Mode {
+		case "raw":
+			d.ReqBodyOther = b.Raw
+			if (b.Options != nil && b.Options.Raw.Language == "json") || isJSON(b.Raw) {
+				d.ReqBodyType = "json"
+			} else {
+				d.ReqBodyType = "raw"
+			}
+		case "urlencoded":
+			d.ReqBodyType = "form"
+			for _, f := range b.URLEncoded {
+				d.ReqBodyForm = append(d.ReqBodyForm, kvItem(f, "text"))
+			}
+		case "formdata":
+			d.ReqBodyType = "form"
+			for _, f := range b.FormData {
+				d.ReqBodyForm = append(d.ReqBodyForm, kvItem(f, "text"))
+			}
+		case "file":
+			d.ReqBodyType = "file"
+		}
+	}
+
+	if len(item.Response) > 0 {
+		d.ResBody = item.Response[0].Body
+		if isJSON(d.ResBody) {
+			d.ResBodyType = "json"
+		} else {
+			d.ResBodyType = "raw"
+		}
+	}
+	return d
+}
+
+// kvItem converts a Postman key/value. Disabled values are optional.
+func kvItem(kv KeyValue, defType string) yapi.ReqKVItemDetail {
+	item := yapi.ReqKVItemDetail{Type: first(kv.Type, defType), Required: "1"}
+	item.Name, item.Example, item.Desc = kv.Key, kv.Value, string(kv.Description)
+	if kv.Disabled {
+		item.Required = "0"
+	}
+	return item
+}
+
+// parseRaw splits a raw URL such as "{{baseUrl}}/users/:id?page=1" into its
+// path and query, dropping the scheme and host.
+func parseRaw(raw string) URL {
+	var u URL
+	rest := raw
+	if i := strings.Index(rest, "#"); i >= 0 {
+		rest = rest[:i]
+	}
+	var query string
+	if i := strings.Index(rest, "?"); i >= 0 {
+		rest, query = rest[:i], rest[i+1:]
+	}
+	switch {
+	case strings.HasPrefix(rest, "{{"):
+		if i := strings.Index(rest, "}}"); i >= 0 {
+			rest = rest[i+2:]
+		}
+	case strings.Contains(rest, "://"):
+		rest = rest[strings.Index(rest, "://")+3:]
+		if i := strings.Index(rest, "/"); i >= 0 {
+			rest = rest[i:]
+		} else {
+			rest = ""
+		}
+	}
+	if rest = strings.Trim(rest, "/"); rest != "" {
+		u.Path = strings.Split(rest, "/")
+	}
+	for _, pair := range strings.Split(query, "&") {
+		if pair == "" {
+			continue
+		}
+		kv := strings.SplitN(pair, "=", 2)
+		q := KeyValue{Key: kv[0]}
+		if len(kv) == 2 {
+			q.Value = kv[1]
+		}
+		u.Query = append(u.Query, q)
+	}
+	return u
+}
+
+func isJSON(s string) bool {
+	s = strings.TrimSpace(s)
+	return s != "" && json.Valid([]byte(s))
+}
+
+func first(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
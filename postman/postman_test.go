@@ -0,0 +1,236 @@
+package postman
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	yapi "github.com/micrease/go-yapi"
+	"github.com/micrease/go-yapi/snapshot"
+)
+
+const testCollection = `{
+  "info": {"name": "shop", "description": {"content": "shop api", "type": "text/markdown"}, "schema": "` + SchemaURL + `"},
+  "item": [
+    {"name": "ping", "request": {"method": "get", "url": "{{baseUrl}}/api/ping?verbose=1"}},
+    {"name": "users", "description": "user apis", "item": [
+      {"name": "get user", "request": {
+        "method": "GET",
+        "header": [{"key": "X-Token", "value": "{{token}}", "description": "auth"}],
+        "url": {"raw": "{{baseUrl}}/api/users/:id?fields=name", "host": "{{baseUrl}}", "path": "api/users/:id",
+          "query": [{"key": "fields", "value": "name"}, {"key": "debug", "value": "1", "disabled": true}],
+          "variable": [{"key": "id", "value": "42", "description": "user id"}]}
+      }, "response": [{"name": "ok", "code": 200, "body": "{\"id\": 42}"}]},
+      {"name": "admin", "item": [
+        {"name": "upload avatar", "request": {"method": "POST", "url": {"host": ["{{baseUrl}}"], "path": ["api", "users", "{{uid}}", "avatar"]},
+          "body": {"mode": "formdata", "formdata": [{"key": "file", "type": "file"}, {"key": "note", "value": "x", "type": "text"}]}}}
+      ]},
+      {"name": "create user", "request": {"method": "POST", "url": "https://shop.example.com/api/users",
+        "body": {"mode": "raw", "raw": "{\"name\": \"x\"}", "options": {"raw": {"language": "json"}}}}}
+    ]}
+  ]
+}`
+
+func TestImport(t *testing.T) {
+	c, err := Read(strings.NewReader(testCollection))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := Import(c, &ImportOptions{Basepath: "/api/"})
+
+	var names []string
+	for _, cat := range data {
+		names = append(names, cat.Name)
+	}
+	if want := []string{"shop", "users", "users / admin"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("categories = %q, want %q", names, want)
+	}
+	if data[0].Desc != "shop api" || data[1].Desc != "user apis" || data[2].Index != 2 {
+		t.Errorf("categories = %+v", data)
+	}
+
+	ping := data[0].List[0]
+	if ping.Method != "GET" || ping.Path != "/ping" || len(ping.ReqQuery) != 1 || ping.ReqQuery[0].Name != "verbose" || ping.ReqQuery[0].Example != "1" {
+		t.Errorf("ping = %+v", ping)
+	}
+
+	get := data[1].List[0]
+	if get.Path != "/users/{id}" || get.Title != "get user" {
+		t.Errorf("get user = %s %s", get.Path, get.Title)
+	}
+	if want := []yapi.ReqKVItemSimple{{Name: "id", Example: "42", Desc: "user id"}}; !reflect.DeepEqual(get.ReqParams, want) {
+		t.Errorf("params = %+v", get.ReqParams)
+	}
+	if len(get.ReqQuery) != 2 || get.ReqQuery[0].Required != "1" || get.ReqQuery[1].Required != "0" {
+		t.Errorf("query = %+v", get.ReqQuery)
+	}
+	if h := get.ReqHeaders; len(h) != 1 || h[0].Name != "X-Token" || h[0].Value != "{{token}}" || h[0].Desc != "auth" {
+		t.Errorf("headers = %+v", h)
+	}
+	if get.ResBodyType != "json" || get.ResBody != `{"id": 42}` {
+		t.Errorf("response = %s %s", get.ResBodyType, get.ResBody)
+	}
+
+	create := data[1].List[1]
+	if create.Path != "/users" || create.ReqBodyType != "json" || create.ReqBodyOther != `{"name": "x"}` || create.ReqBodyIsJsonSchema {
+		t.Errorf("create user = %+v", create)
+	}
+
+	upload := data[2].List[0]
+	if upload.Path != "/users/{uid}/avatar" || upload.ReqBodyType != "form" || len(upload.ReqBodyForm) != 2 || upload.ReqBodyForm[0].Type != "file" || upload.ReqBodyForm[1].Example != "x" {
+		t.Errorf("upload avatar = %+v", upload)
+	}
+}
+
+func testSnapshot() *snapshot.Snapshot {
+	var get yapi.InterfaceData
+	get.Method, get.Path, get.Title = "GET", "/users/{id}", "get user"
+	get.ReqParams = []yapi.ReqKVItemSimple{{Name: "id", Example: "42"}}
+	get.ReqQuery = []yapi.ReqKVItemDetail{{ReqKVItemSimple: yapi.ReqKVItemSimple{Name: "fields", Example: "name"}, Required: "0"}}
+	get.ReqHeaders = []yapi.ReqKVItemDetail{{ReqKVItemSimple: yapi.ReqKVItemSimple{Name: "Accept", Value: "application/json"}, Required: "1"}}
+	get.ResBodyType, get.ResBodyIsJsonSchema = "json", true
+	get.ResBody = `{"type":"object","properties":{"id":{"type":"integer"}}}`
+
+	var login yapi.InterfaceData
+	login.Method, login.Path, login.Title = "POST", "/login", "login"
+	login.ReqBodyType = "form"
+	login.ReqBodyForm = []yapi.ReqKVItemDetail{{ReqKVItemSimple: yapi.ReqKVItemSimple{Name: "user"}, Type: "text", Required: "1"}}
+
+	return &snapshot.Snapshot{
+		Project: yapi.ProjectData{Name: "shop", Basepath: "/api", Env: []yapi.ProjectEnv{
+			{ID: "e1", Name: "local", Domain: "http://localhost:8080", Global: []yapi.EnvGlobal{{Name: "token", Value: "t"}}},
+			{ID: "e2", Name: "prod", Domain: "https://shop.example.com"},
+		}},
+		Categories: []snapshot.Category{{CatData: yapi.CatData{Name: "users", Desc: "user apis"}, Interfaces: []yapi.InterfaceData{get, login}}},
+	}
+}
+
+func TestExport(t *testing.T) {
+	s := testSnapshot()
+	c := Export(s)
+	if c.Info.Name != "shop" || c.Info.Schema != SchemaURL || len(c.Item) != 1 || c.Item[0].Name != "users" || len(c.Item[0].Item) != 2 {
+		t.Fatalf("collection = %+v", c)
+	}
+	if want := []Variable{{Key: "baseUrl", Value: "http://localhost:8080"}}; !reflect.DeepEqual(c.Variable, want) {
+		t.Errorf("variables = %+v", c.Variable)
+	}
+
+	get := c.Item[0].Item[0]
+	u := get.Request.URL
+	if u.Raw != "{{baseUrl}}/api/users/:id?fields=name" || !reflect.DeepEqual(u.Path, []string{"api", "users", ":id"}) {
+		t.Errorf("url = %+v", u)
+	}
+	if len(u.Variable) != 1 || u.Variable[0].Value != "42" || len(u.Query) != 1 || !u.Query[0].Disabled {
+		t.Errorf("url = %+v", u)
+	}
+	if h := get.Request.Header; len(h) != 1 || h[0].Value != "application/json" {
+		t.Errorf("headers = %+v", h)
+	}
+	if len(get.Response) != 1 || get.Response[0].Body != "{\n  \"id\": 0\n}" {
+		t.Errorf("responses = %+v", get.Response)
+	}
+
+	login := c.Item[0].Item[1]
+	if b := login.Request.Body; b == nil || b.Mode != "urlencoded" || len(b.URLEncoded) != 1 || b.URLEncoded[0].Key != "user" {
+		t.Errorf("login body = %+v", b)
+	}
+
+	// the collection reads back as written
+	data, err := json.Marshal(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	back, err := Read(strings.NewReader(string(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	imported := Import(back, &ImportOptions{Basepath: s.Project.Basepath})
+	if len(imported) != 1 || imported[0].Name != "users" || len(imported[0].List) != 2 {
+		t.Fatalf("imported = %+v", imported)
+	}
+	if d := imported[0].List[0]; d.Path != "/users/{id}" || d.Method != "GET" || d.ReqQuery[0].Required != "0" || d.ReqParams[0].Example != "42" {
+		t.Errorf("imported get user = %+v", d)
+	}
+}
+
+func TestEnvironments(t *testing.T) {
+	envs := Environments(testSnapshot().Project)
+	if len(envs) != 2 {
+		t.Fatalf("environments = %+v", envs)
+	}
+	want := Environment{ID: "e1", Name: "local", Scope: "environment", Values: []EnvironmentValue{
+		{Key: "baseUrl", Value: "http://localhost:8080", Type: "default", Enabled: true},
+		{Key: "token", Value: "t", Type: "default", Enabled: true},
+	}}
+	if !reflect.DeepEqual(envs[0], want) {
+		t.Errorf("environment = %+v", envs[0])
+	}
+}
+
+func TestPush(t *testing.T) {
+	var calls []string
+	reply := func(w http.ResponseWriter, data interface{}) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"errcode": 0, "errmsg": "成功！", "data": data})
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/project/get", func(w http.ResponseWriter, r *http.Request) {
+		reply(w, yapi.ProjectData{ID: 11, Name: "shop"})
+	})
+	mux.HandleFunc("/api/interface/getCatMenu", func(w http.ResponseWriter, r *http.Request) {
+		reply(w, yapi.CatMenuData{{ID: 1, Name: "users"}})
+	})
+	mux.HandleFunc("/api/interface/list_cat", func(w http.ResponseWriter, r *http.Request) {
+		reply(w, map[string]interface{}{"count": 0, "total": 1, "list": []interface{}{}})
+	})
+	nextCat := 2
+	mux.HandleFunc("/api/interface/add_cat", func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.URL.Path)
+		reply(w, map[string]interface{}{"_id": nextCat})
+		nextCat++
+	})
+	mux.HandleFunc("/api/interface/save", func(w http.ResponseWriter, r *http.Request) {
+		var d yapi.InterfaceData
+		body, _ := ioutil.ReadAll(r.Body)
+		json.Unmarshal(body, &d)
+		calls = append(calls, r.URL.Path+" "+d.Path+" "+strconv.Itoa(d.CatID))
+		reply(w, []interface{}{})
+	})
+	mux.HandleFunc("/api/open/import_data", func(w http.ResponseWriter, r *http.Request) {
+		var req yapi.UploadSwaggerReq
+		body, _ := ioutil.ReadAll(r.Body)
+		json.Unmarshal(body, &req)
+		var data yapi.ExportData
+		json.Unmarshal([]byte(req.Json), &data)
+		calls = append(calls, r.URL.Path+" "+req.Type+" "+req.Merge+" "+data[1].List[0].Path)
+		reply(w, nil)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	client, _ := yapi.NewClient(server.URL, "token")
+
+	c, _ := Read(strings.NewReader(testCollection))
+	data := Import(c, nil)
+	if err := Push(client, data, &PushOptions{Merge: "good"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := Push(client, data, &PushOptions{Save: true}); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"/api/open/import_data json good /api/users/{id}",
+		"/api/interface/add_cat",
+		"/api/interface/add_cat",
+		"/api/interface/save /api/ping 2",
+		"/api/interface/save /api/users/{id} 1",
+		"/api/interface/save /api/users 1",
+		"/api/interface/save /api/users/{uid}/avatar 3",
+	}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("calls =\n%s", strings.Join(calls, "\n"))
+	}
+}
@@ -0,0 +1,42 @@
+package postman
+
+import (
+	"encoding/json"
+
+	yapi "github.com/micrease/go-yapi"
+	"github.com/micrease/go-yapi/snapshot"
+)
+
+// PushOptions controls how imported data is sent to YApi.
+type PushOptions struct {
+	// Save sends the interfaces one by one with the save api, creating the
+	// missing categories, instead of a single import_data call. Categories
+	// are matched by name and interfaces by method and path.
+	Save bool
+	// Merge is the import_data conflict mode: "normal", "good" or "merge",
+	// the default.
+	Merge string
+}
+
+// Push sends converted data to the project the client token belongs to.
+func Push(c *yapi.Client, data yapi.ExportData, opts *PushOptions) error {
+	if opts == nil {
+		opts = &PushOptions{}
+	}
+	if opts.Save {
+		plan, err := snapshot.PlanSnapshot(c, snapshot.FromExport(data), nil)
+		if err != nil {
+			return err
+		}
+		return plan.Execute(c)
+	}
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	resp, err := c.Interface.Import("json", first(opts.Merge, "merge"), string(raw))
+	if err != nil {
+		return err
+	}
+	return yapi.CheckErrCode(resp.ErrCode, resp.ErrMsg)
+}
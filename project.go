@@ -25,19 +25,20 @@ type ProjectEnv struct {
 }
 
 type ProjectData struct {
-	ID      int          `json:"_id" structs:"_id"`
-	UID     int          `json:"uid" structs:"uid"`
-	GroupID int          `json:"group_id" structs:"group_id"`
-	Name    string       `json:"name" structs:"name"`
-	Role    bool         `json:"role" structs:"role"`
-	Env     []ProjectEnv `json:"env" structs:"env"`
+	ID       int          `json:"_id" structs:"_id"`
+	UID      int          `json:"uid" structs:"uid"`
+	GroupID  int          `json:"group_id" structs:"group_id"`
+	Name     string       `json:"name" structs:"name"`
+	Basepath string       `json:"basepath" structs:"basepath"`
+	Role     bool         `json:"role" structs:"role"`
+	Env      []ProjectEnv `json:"env" structs:"env"`
 }
 
 type Project struct {
 	ErrCode int         `json:"errcode" structs:"errcode"`
 	ErrMsg  string      `json:"errmsg" structs:"errmsg"`
 	Data    ProjectData `json:"data" structs:"data"`
-	string  string      `json:"string"`
+	string  string
 }
 
 func (p *Project) ToString() string {
@@ -0,0 +1,187 @@
+package recorder
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sync"
+)
+
+// Mode selects whether a Recorder talks to YApi or to its cassette.
+type Mode int
+
+const (
+	// ModeReplay serves the responses of the cassette and fails requests
+	// that were not recorded.
+	ModeReplay Mode = iota
+	// ModeRecord sends the requests to YApi and records them, replacing the
+	// cassette on Stop.
+	ModeRecord
+	// ModeAuto replays the cassette when it exists and records it otherwise.
+	ModeAuto
+)
+
+// Options configure a Recorder.
+type Options struct {
+	Mode Mode
+	// Redact lists the query parameters, form and JSON fields and headers
+	// whose values are redacted. Defaults to DefaultRedact.
+	Redact []string
+	// Transport sends the recorded requests. Defaults to http.DefaultTransport.
+	Transport http.RoundTripper
+}
+
+// Recorder is an http.RoundTripper recording or replaying a cassette.
+type Recorder struct {
+	path      string
+	mode      Mode
+	redact    redactor
+	transport http.RoundTripper
+
+	mu       sync.Mutex
+	cassette *Cassette
+	used     []bool
+}
+
+// New returns a Recorder for the cassette file at path. In replay mode the
+// cassette is loaded immediately.
+func New(path string, opts *Options) (*Recorder, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	r := &Recorder{
+		path:      path,
+		mode:      opts.Mode,
+		transport: opts.Transport,
+		cassette:  &Cassette{},
+	}
+	if opts.Redact != nil {
+		r.redact = newRedactor(opts.Redact)
+	} else {
+		r.redact = newRedactor(DefaultRedact)
+	}
+	if r.transport == nil {
+		r.transport = http.DefaultTransport
+	}
+	if r.mode == ModeAuto {
+		r.mode = ModeRecord
+		if _, err := os.Stat(path); err == nil {
+			r.mode = ModeReplay
+		}
+	}
+	if r.mode == ModeReplay {
+		c, err := Load(path)
+		if err != nil {
+			return nil, err
+		}
+		r.cassette = c
+		r.used = make([]bool, len(c.Interactions))
+	}
+	return r, nil
+}
+
+// Mode returns the mode the Recorder runs in, ModeAuto being resolved.
+func (r *Recorder) Mode() Mode {
+	return r.mode
+}
+
+// Cassette returns the recorded or loaded interactions.
+func (r *Recorder) Cassette() *Cassette {
+	return r.cassette
+}
+
+// Client returns an http.Client using the Recorder, to be passed to
+// yapi.Client.SetHTTPClient.
+func (r *Recorder) Client() *http.Client {
+	return &http.Client{Transport: r}
+}
+
+// Stop saves the cassette when recording.
+func (r *Recorder) Stop() error {
+	if r.mode != ModeRecord {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.cassette.Save(r.path)
+}
+
+// RoundTrip implements http.RoundTripper.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	recorded := Request{
+		Method: req.Method,
+		URL:    r.redact.url(req.URL.String()),
+		Header: r.redact.header(req.Header),
+		Body:   r.redact.body(body, req.Header.Get("Content-Type")),
+	}
+	if r.mode == ModeReplay {
+		return r.replay(req, recorded)
+	}
+
+	out := req.Clone(req.Context())
+	out.Body = ioutil.NopCloser(bytes.NewReader(body))
+	out.ContentLength = int64(len(body))
+	resp, err := r.transport.RoundTrip(out)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(respBody))
+
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, Interaction{
+		Request: recorded,
+		Response: Response{
+			StatusCode: resp.StatusCode,
+			Header:     r.redact.header(resp.Header),
+			Body:       r.redact.body(respBody, resp.Header.Get("Content-Type")),
+		},
+	})
+	r.mu.Unlock()
+	return resp, nil
+}
+
+// replay answers with the first unused interaction matching the method, URL
+// and body of the request, so repeated requests get their responses in the
+// recorded order.
+func (r *Recorder) replay(req *http.Request, recorded Request) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, in := range r.cassette.Interactions {
+		if r.used[i] || in.Request.Method != recorded.Method || in.Request.URL != recorded.URL || in.Request.Body != recorded.Body {
+			continue
+		}
+		r.used[i] = true
+		header := in.Response.Header.Clone()
+		if header == nil {
+			header = http.Header{}
+		}
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", in.Response.StatusCode, http.StatusText(in.Response.StatusCode)),
+			StatusCode:    in.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        header,
+			Body:          ioutil.NopCloser(bytes.NewReader([]byte(in.Response.Body))),
+			ContentLength: int64(len(in.Response.Body)),
+			Request:       req,
+		}, nil
+	}
+	return nil, fmt.Errorf("recorder: no interaction recorded for %s %s in %s", recorded.Method, recorded.URL, r.path)
+}
@@ -0,0 +1,92 @@
+package recorder
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	yapi "github.com/micrease/go-yapi"
+	"github.com/micrease/go-yapi/yapitest"
+)
+
+func TestRecorder(t *testing.T) {
+	srv := yapitest.NewServer()
+	token := srv.AddProject(yapi.ProjectData{Name: "demo", Basepath: "/api"})
+	path := filepath.Join(t.TempDir(), "cassettes", "demo.json")
+
+	rec, err := New(path, &Options{Mode: ModeAuto})
+	if err != nil || rec.Mode() != ModeRecord {
+		t.Fatalf("New = %v, %v", rec, err)
+	}
+	client := srv.Client(token)
+	client.SetHTTPClient(rec.Client())
+	project, err := client.Project.Get()
+	if err != nil || project.Data.Name != "demo" {
+		t.Fatalf("project = %+v, %v", project, err)
+	}
+	param := new(yapi.ModifyMenuParam)
+	param.ProjectID, param.Name = project.Data.ID, "orders"
+	if resp, err := client.CatMenu.AddOrUpdate(param); err != nil || resp.ErrCode != 0 {
+		t.Fatalf("add_cat = %+v, %v", resp, err)
+	}
+	menu, err := client.CatMenu.Get(project.Data.ID)
+	if err != nil || len(menu.Data) != 2 {
+		t.Fatalf("menu = %+v, %v", menu, err)
+	}
+	if err := rec.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	srv.Close()
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), token) {
+		t.Errorf("cassette contains the token:\n%s", data)
+	}
+	if in := rec.Cassette().Interactions; !strings.HasSuffix(in[0].Request.URL, "token="+Redacted) || !strings.Contains(in[1].Request.Body, `"token":"`+Redacted+`"`) {
+		t.Errorf("requests not redacted: %+v", in)
+	}
+
+	rec, err = New(path, &Options{Mode: ModeAuto})
+	if err != nil || rec.Mode() != ModeReplay || len(rec.Cassette().Interactions) != 3 {
+		t.Fatalf("replay New = %v, %v", rec, err)
+	}
+	client, _ = yapi.NewClient(srv.URL, "another-token")
+	client.SetHTTPClient(rec.Client())
+	replayed, err := client.Project.Get()
+	if err != nil || replayed.Data.Name != "demo" {
+		t.Fatalf("replayed project = %+v, %v", replayed, err)
+	}
+	if resp, err := client.CatMenu.AddOrUpdate(param); err != nil || resp.ErrCode != 0 {
+		t.Fatalf("replayed add_cat = %+v, %v", resp, err)
+	}
+	if menu, err := client.CatMenu.Get(project.Data.ID); err != nil || len(menu.Data) != 2 || menu.Data[1].Name != "orders" {
+		t.Errorf("replayed menu = %+v, %v", menu, err)
+	}
+	if _, err := client.CatMenu.Get(project.Data.ID); err == nil || !strings.Contains(err.Error(), "no interaction recorded") {
+		t.Errorf("unrecorded request = %v", err)
+	}
+}
+
+func TestRedactor(t *testing.T) {
+	r := newRedactor(DefaultRedact)
+	if got := r.url("http://yapi/api/project/get?token=abc&id=1"); got != "http://yapi/api/project/get?token=REDACTED&id=1" {
+		t.Errorf("url = %s", got)
+	}
+	if got := r.body([]byte(`{"id":1,"user":{"password":"x"}}`), "application/json"); got != `{"id":1,"user":{"password":"REDACTED"}}` {
+		t.Errorf("json body = %s", got)
+	}
+	if got := r.body([]byte(`{ "id": 1 }`), "application/json"); got != `{ "id": 1 }` {
+		t.Errorf("unchanged body = %s", got)
+	}
+	if got := r.body([]byte("email=a&password=x"), "application/x-www-form-urlencoded"); got != "email=a&password=REDACTED" {
+		t.Errorf("form body = %s", got)
+	}
+	h := r.header(map[string][]string{"Cookie": {"_yapi_token=x"}, "Accept": {"*/*"}})
+	if h.Get("Cookie") != Redacted || h.Get("Accept") != "*/*" {
+		t.Errorf("header = %v", h)
+	}
+}
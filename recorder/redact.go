@@ -0,0 +1,120 @@
+package recorder
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Redacted replaces the redacted values.
+const Redacted = "REDACTED"
+
+// DefaultRedact lists the names redacted when Options.Redact is nil.
+var DefaultRedact = []string{"token", "password", "Authorization", "Cookie", "Set-Cookie"}
+
+// redactor replaces the values of the query parameters, form and JSON
+// fields and headers with one of its names, compared case insensitively.
+type redactor map[string]bool
+
+func newRedactor(names []string) redactor {
+	r := redactor{}
+	for _, name := range names {
+		r[strings.ToLower(name)] = true
+	}
+	return r
+}
+
+func (r redactor) has(name string) bool {
+	return r[strings.ToLower(name)]
+}
+
+func (r redactor) url(s string) string {
+	u, err := url.Parse(s)
+	if err != nil || u.RawQuery == "" {
+		return s
+	}
+	u.RawQuery = r.query(u.RawQuery)
+	return u.String()
+}
+
+// query redacts an encoded query, keeping the order of the parameters.
+func (r redactor) query(q string) string {
+	pairs := strings.Split(q, "&")
+	changed := false
+	for i, pair := range pairs {
+		kv := strings.SplitN(pair, "=", 2)
+		name, err := url.QueryUnescape(kv[0])
+		if err == nil && r.has(name) && len(kv) == 2 {
+			pairs[i] = kv[0] + "=" + Redacted
+			changed = true
+		}
+	}
+	if !changed {
+		return q
+	}
+	return strings.Join(pairs, "&")
+}
+
+func (r redactor) header(h http.Header) http.Header {
+	if len(h) == 0 {
+		return nil
+	}
+	out := make(http.Header, len(h))
+	for name, values := range h {
+		if r.has(name) {
+			values = []string{Redacted}
+		}
+		out[name] = append([]string(nil), values...)
+	}
+	return out
+}
+
+// body redacts a JSON or form body. Other bodies, and bodies without
+// anything to redact, are returned unchanged.
+func (r redactor) body(body []byte, contentType string) string {
+	if strings.HasPrefix(contentType, "application/x-www-form-urlencoded") {
+		return r.query(string(body))
+	}
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) == 0 || (trimmed[0] != '{' && trimmed[0] != '[') {
+		return string(body)
+	}
+	var v interface{}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	if dec.Decode(&v) != nil || !r.value(v) {
+		return string(body)
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if enc.Encode(v) != nil {
+		return string(body)
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// value redacts the JSON value in place and reports whether it changed.
+func (r redactor) value(v interface{}) bool {
+	changed := false
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, item := range v {
+			if r.has(k) {
+				if item != Redacted {
+					v[k] = Redacted
+					changed = true
+				}
+				continue
+			}
+			changed = r.value(item) || changed
+		}
+	case []interface{}:
+		for _, item := range v {
+			changed = r.value(item) || changed
+		}
+	}
+	return changed
+}
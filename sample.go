@@ -0,0 +1,164 @@
+package yapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/micrease/go-yapi/internal/route"
+)
+
+// Example returns a deterministic sample value for the schema: its default,
+// its first enum value or a placeholder value of its type.
+func (s *Schema) Example() interface{} {
+	if s == nil {
+		return nil
+	}
+	if s.Default != nil {
+		return s.Default
+	}
+	if len(s.Enum) > 0 {
+		return s.Enum[0]
+	}
+	typ := ""
+	for _, t := range s.Types() {
+		if t != "null" {
+			typ = t
+			break
+		}
+	}
+	if typ == "" && s.Properties != nil {
+		typ = "object"
+	}
+	switch typ {
+	case "object":
+		out := make(map[string]interface{}, len(s.Properties))
+		for _, name := range s.PropertyNames() {
+			out[name] = s.Properties[name].Example()
+		}
+		return out
+	case "array":
+		n := 1
+		if s.MinItems != nil && *s.MinItems > n {
+			n = *s.MinItems
+		}
+		out := make([]interface{}, n)
+		for i := range out {
+			out[i] = s.Items.Example()
+		}
+		return out
+	case "integer", "number":
+		if s.Minimum != nil {
+			return *s.Minimum
+		}
+		if s.Maximum != nil && *s.Maximum < 0 {
+			return *s.Maximum
+		}
+		return 0
+	case "boolean":
+		return false
+	case "string":
+		switch s.Format {
+		case "date-time":
+			return "1970-01-01T00:00:00Z"
+		case "date":
+			return "1970-01-01"
+		case "email":
+			return "user@example.com"
+		case "uri", "url":
+			return "http://example.com"
+		case "uuid":
+			return "00000000-0000-0000-0000-000000000000"
+		}
+		if s.MinLength != nil && *s.MinLength > 0 {
+			return strings.Repeat("s", *s.MinLength)
+		}
+		return "string"
+	}
+	return nil
+}
+
+// sampleValue returns the example of a documented param, falling back to its value.
+func sampleValue(item ReqKVItemSimple) string {
+	if item.Example != "" {
+		return item.Example
+	}
+	return item.Value
+}
+
+// SampleBody returns the documented example request body and its content type.
+// A JSON Schema body is turned into a value with Schema.Example.
+func (d *InterfaceData) SampleBody() ([]byte, string, error) {
+	switch d.ReqBodyType {
+	case "form":
+		form := url.Values{}
+		for _, item := range d.ReqBodyForm {
+			if item.Type == "file" {
+				continue
+			}
+			form.Add(item.Name, sampleValue(item.ReqKVItemSimple))
+		}
+		return []byte(form.Encode()), "application/x-www-form-urlencoded", nil
+	case "json":
+		if !d.ReqBodyIsJsonSchema {
+			return []byte(d.ReqBodyOther), "application/json", nil
+		}
+		schema, err := d.ReqBodySchema()
+		if err != nil || schema == nil {
+			return nil, "application/json", err
+		}
+		body, err := json.Marshal(schema.Example())
+		return body, "application/json", err
+	case "raw":
+		return []byte(d.ReqBodyOther), "text/plain", nil
+	}
+	return nil, "", nil
+}
+
+// SampleRequest builds a request for the interface from its documented
+// examples: path params from ReqParams, query from ReqQuery, headers from
+// ReqHeaders and the body from SampleBody. The path is resolved against baseURL.
+func (d *InterfaceData) SampleRequest(baseURL string) (*http.Request, error) {
+	params := make(map[string]string, len(d.ReqParams))
+	for _, p := range d.ReqParams {
+		params[p.Name] = sampleValue(p)
+	}
+	u, err := url.Parse(strings.TrimRight(baseURL, "/") + route.Expand(d.Path, params))
+	if err != nil {
+		return nil, err
+	}
+	q := u.Query()
+	for _, item := range d.ReqQuery {
+		q.Add(item.Name, sampleValue(item.ReqKVItemSimple))
+	}
+	u.RawQuery = q.Encode()
+
+	body, contentType, err := d.SampleBody()
+	if err != nil {
+		return nil, err
+	}
+	var r io.Reader
+	if body != nil {
+		r = bytes.NewReader(body)
+	}
+	method := strings.ToUpper(d.Method)
+	if method == "" {
+		method = http.MethodGet
+	}
+	req, err := http.NewRequest(method, u.String(), r)
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	for _, h := range d.ReqHeaders {
+		if v := sampleValue(h.ReqKVItemSimple); v != "" {
+			req.Header.Set(h.Name, v)
+		}
+	}
+	return req, nil
+}
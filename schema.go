@@ -0,0 +1,356 @@
+package yapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ErrNotJSONSchema is returned by the body schema accessors of InterfaceData
+// when the body is a raw example instead of a JSON Schema.
+var ErrNotJSONSchema = errors.New("body is not a json schema")
+
+// SchemaMock is the YApi mock extension of a schema node, e.g. {"mock":"@string"}.
+type SchemaMock struct {
+	Mock string `json:"mock"`
+
+	// Extra holds the other keys of the mock object.
+	Extra map[string]json.RawMessage `json:"-"`
+
+	// omitMock is set when the source has no mock rule.
+	omitMock bool
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (m *SchemaMock) UnmarshalJSON(data []byte) error {
+	raw := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*m = SchemaMock{}
+	for key, value := range raw {
+		if key == "mock" && decodeInto(value, &m.Mock) {
+			continue
+		}
+		if m.Extra == nil {
+			m.Extra = make(map[string]json.RawMessage)
+		}
+		m.Extra[key] = value
+	}
+	_, hasMock := raw["mock"]
+	m.omitMock = !hasMock
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler. The mock rule is written first,
+// followed by Extra in lexical order.
+func (m *SchemaMock) MarshalJSON() ([]byte, error) {
+	values := make(map[string]json.RawMessage, len(m.Extra)+1)
+	for key, value := range m.Extra {
+		values[key] = value
+	}
+	var keys []string
+	if _, ok := values["mock"]; !ok && (m.Mock != "" || !m.omitMock) {
+		values["mock"], _ = json.Marshal(m.Mock)
+		keys = append(keys, "mock")
+	}
+	extra := make([]string, 0, len(m.Extra))
+	for key := range m.Extra {
+		extra = append(extra, key)
+	}
+	sort.Strings(extra)
+	return writeObject(append(keys, extra...), values), nil
+}
+
+// Schema is a JSON Schema node as edited by the YApi schema editor.
+// Keywords without a dedicated field are kept in Extra, so a parse/serialize
+// round trip does not lose any information.
+type Schema struct {
+	Schema      string             `json:"$schema,omitempty"`
+	Type        string             `json:"type,omitempty"`
+	Title       string             `json:"title,omitempty"`
+	Description string             `json:"description,omitempty"`
+	Format      string             `json:"format,omitempty"`
+	Pattern     string             `json:"pattern,omitempty"`
+	Default     interface{}        `json:"default,omitempty"`
+	Enum        []interface{}      `json:"enum,omitempty"`
+	Minimum     *float64           `json:"minimum,omitempty"`
+	Maximum     *float64           `json:"maximum,omitempty"`
+	MinLength   *int               `json:"minLength,omitempty"`
+	MaxLength   *int               `json:"maxLength,omitempty"`
+	MinItems    *int               `json:"minItems,omitempty"`
+	MaxItems    *int               `json:"maxItems,omitempty"`
+	Properties  map[string]*Schema `json:"properties,omitempty"`
+	Items       *Schema            `json:"items,omitempty"`
+	Required    []string           `json:"required,omitempty"`
+	Mock        *SchemaMock        `json:"mock,omitempty"`
+
+	// Extra holds every keyword that is not mapped to a field above.
+	Extra map[string]json.RawMessage `json:"-"`
+
+	// propertyOrder remembers the order of Properties as found in the source.
+	propertyOrder []string
+
+	// present records the keywords found in the source, so that empty values
+	// such as "title":"" or "required":[] are written back.
+	present map[string]bool
+}
+
+// schemaFields is the ordered list of keywords handled by Schema itself.
+var schemaFields = []string{
+	"$schema", "type", "title", "description", "format", "pattern", "default", "enum",
+	"minimum", "maximum", "minLength", "maxLength", "minItems", "maxItems",
+	"properties", "items", "required", "mock",
+}
+
+// ParseSchema parses a JSON Schema document. An empty document yields a nil schema.
+func ParseSchema(data string) (*Schema, error) {
+	if strings.TrimSpace(data) == "" {
+		return nil, nil
+	}
+	s := new(Schema)
+	if err := json.Unmarshal([]byte(data), s); err != nil {
+		return nil, errors.Wrap(err, "parse json schema")
+	}
+	return s, nil
+}
+
+// String returns the schema as indented JSON, the format used by the YApi editor.
+func (s *Schema) String() string {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// PropertyNames returns the property names in source order. Properties added
+// after parsing follow in lexical order.
+func (s *Schema) PropertyNames() []string {
+	names := make([]string, 0, len(s.Properties))
+	seen := make(map[string]bool, len(s.Properties))
+	for _, name := range s.propertyOrder {
+		if _, ok := s.Properties[name]; ok && !seen[name] {
+			names = append(names, name)
+			seen[name] = true
+		}
+	}
+	var rest []string
+	for name := range s.Properties {
+		if !seen[name] {
+			rest = append(rest, name)
+		}
+	}
+	sort.Strings(rest)
+	return append(names, rest...)
+}
+
+// SetProperty adds or replaces a property, keeping it at the end of the property order.
+func (s *Schema) SetProperty(name string, prop *Schema) {
+	if s.Properties == nil {
+		s.Properties = make(map[string]*Schema)
+	}
+	if _, ok := s.Properties[name]; !ok {
+		s.propertyOrder = append(s.PropertyNames(), name)
+	}
+	s.Properties[name] = prop
+}
+
+// IsRequired reports whether name is listed in Required.
+func (s *Schema) IsRequired(name string) bool {
+	for _, r := range s.Required {
+		if r == name {
+			return true
+		}
+	}
+	return false
+}
+
+// UnmarshalJSON implements json.Unmarshaler. A keyword whose value does not
+// fit its field (e.g. "type":["string","null"]) is kept verbatim in Extra.
+func (s *Schema) UnmarshalJSON(data []byte) error {
+	raw := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*s = Schema{}
+	targets := s.fields()
+	for key, value := range raw {
+		// null is kept verbatim, the fields cannot tell it from an absent keyword.
+		if target, ok := targets[key]; ok && string(value) != "null" && decodeInto(value, target) {
+			if key == "properties" {
+				s.propertyOrder = objectKeys(value)
+			}
+			if s.present == nil {
+				s.present = make(map[string]bool)
+			}
+			s.present[key] = true
+			continue
+		}
+		if s.Extra == nil {
+			s.Extra = make(map[string]json.RawMessage)
+		}
+		s.Extra[key] = value
+	}
+	return nil
+}
+
+// fields maps the keywords handled by Schema to its fields.
+func (s *Schema) fields() map[string]interface{} {
+	return map[string]interface{}{
+		"$schema":     &s.Schema,
+		"type":        &s.Type,
+		"title":       &s.Title,
+		"description": &s.Description,
+		"format":      &s.Format,
+		"pattern":     &s.Pattern,
+		"default":     &s.Default,
+		"enum":        &s.Enum,
+		"minimum":     &s.Minimum,
+		"maximum":     &s.Maximum,
+		"minLength":   &s.MinLength,
+		"maxLength":   &s.MaxLength,
+		"minItems":    &s.MinItems,
+		"maxItems":    &s.MaxItems,
+		"properties":  &s.Properties,
+		"items":       &s.Items,
+		"required":    &s.Required,
+		"mock":        &s.Mock,
+	}
+}
+
+// MarshalJSON implements json.Marshaler. Known keywords are written first in a
+// fixed order, followed by Extra in lexical order. Keywords present in the
+// parsed source are written even when their value is empty.
+func (s *Schema) MarshalJSON() ([]byte, error) {
+	type plain Schema
+	known, err := json.Marshal((*plain)(s))
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(known, &values); err != nil {
+		return nil, err
+	}
+	if len(s.Properties) > 0 || s.present["properties"] {
+		props, err := s.marshalProperties()
+		if err != nil {
+			return nil, err
+		}
+		values["properties"] = props
+	}
+	fields := s.fields()
+	for key := range s.present {
+		if _, ok := values[key]; !ok {
+			values[key] = emptyValue(fields[key])
+		}
+	}
+
+	var keys []string
+	for _, key := range schemaFields {
+		if _, ok := values[key]; ok {
+			keys = append(keys, key)
+		}
+	}
+	extra := make([]string, 0, len(s.Extra))
+	for key, value := range s.Extra {
+		if _, ok := values[key]; !ok {
+			extra = append(extra, key)
+			values[key] = value
+		}
+	}
+	sort.Strings(extra)
+	return writeObject(append(keys, extra...), values), nil
+}
+
+// emptyValue encodes the empty value of the field pointed to by target,
+// writing nil slices and maps as [] and {}.
+func emptyValue(target interface{}) json.RawMessage {
+	field := reflect.ValueOf(target).Elem()
+	switch {
+	case field.Kind() == reflect.Slice && field.IsNil():
+		return json.RawMessage("[]")
+	case field.Kind() == reflect.Map && field.IsNil():
+		return json.RawMessage("{}")
+	}
+	data, err := json.Marshal(field.Interface())
+	if err != nil {
+		return json.RawMessage("null")
+	}
+	return data
+}
+
+// writeObject writes the values as a JSON object in the order of keys.
+func writeObject(keys []string, values map[string]json.RawMessage) []byte {
+	buf := new(bytes.Buffer)
+	buf.WriteByte('{')
+	for i, key := range keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		k, _ := json.Marshal(key)
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(values[key])
+	}
+	buf.WriteByte('}')
+	return buf.Bytes()
+}
+
+func (s *Schema) marshalProperties() (json.RawMessage, error) {
+	buf := new(bytes.Buffer)
+	buf.WriteByte('{')
+	for i, name := range s.PropertyNames() {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		k, _ := json.Marshal(name)
+		v, err := json.Marshal(s.Properties[name])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// decodeInto decodes value into the field pointed to by target. The field is
+// left untouched when value does not fit its type.
+func decodeInto(value json.RawMessage, target interface{}) bool {
+	field := reflect.ValueOf(target).Elem()
+	tmp := reflect.New(field.Type())
+	if err := json.Unmarshal(value, tmp.Interface()); err != nil {
+		return false
+	}
+	field.Set(tmp.Elem())
+	return true
+}
+
+// objectKeys returns the top level keys of a JSON object in document order.
+func objectKeys(data []byte) []string {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if t, err := dec.Token(); err != nil || t != json.Delim('{') {
+		return nil
+	}
+	var keys []string
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return keys
+		}
+		key, _ := t.(string)
+		keys = append(keys, key)
+		var skip json.RawMessage
+		if err := dec.Decode(&skip); err != nil {
+			return keys
+		}
+	}
+	return keys
+}
@@ -0,0 +1,344 @@
+package yapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// InferOptions tunes schema inference.
+type InferOptions struct {
+	// EnumMax is the largest number of distinct string or integer values
+	// listed as an enum. Zero disables enums.
+	EnumMax int
+	// EnumMinSamples is the number of values a node must have seen, counting
+	// repeats, before its values can be listed as an enum.
+	EnumMinSamples int
+	// NoFormats disables the detection of string formats: date-time, date,
+	// email, uuid, uri and ipv4.
+	NoFormats bool
+}
+
+// DefaultInferOptions are used when no options are given: a node seen at
+// least 5 times with at most 5 distinct values gets an enum.
+var DefaultInferOptions = InferOptions{EnumMax: 5, EnumMinSamples: 5}
+
+// SchemaInferrer derives a schema from sample documents. The samples are
+// merged: a property is required when every object sample has it, a node
+// seen with several types lists them all, integers seen next to other
+// numbers become "number", and a string format or enum is only kept when
+// every value fits it.
+type SchemaInferrer struct {
+	opts InferOptions
+	root inferNode
+}
+
+// NewSchemaInferrer returns an inferrer. opts may be nil for
+// DefaultInferOptions.
+func NewSchemaInferrer(opts *InferOptions) *SchemaInferrer {
+	if opts == nil {
+		opts = &DefaultInferOptions
+	}
+	return &SchemaInferrer{opts: *opts}
+}
+
+// Add adds a sample value as decoded by encoding/json. Object properties
+// first seen in such a value are ordered by name.
+func (in *SchemaInferrer) Add(v interface{}) {
+	in.root.add(v, &in.opts)
+}
+
+// AddJSON adds a JSON document, keeping the order of the object properties
+// as first seen.
+func (in *SchemaInferrer) AddJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	v, err := decodeOrdered(dec)
+	if err != nil {
+		return err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return errors.New("unexpected data after the document")
+	}
+	in.Add(v)
+	return nil
+}
+
+// Schema returns the schema of the samples added so far.
+func (in *SchemaInferrer) Schema() *Schema {
+	return in.root.schema(&in.opts)
+}
+
+// InferSchema derives a schema from sample values as decoded by
+// encoding/json with DefaultInferOptions. Object properties are ordered by
+// name; use InferSchemaJSON to keep the document order.
+func InferSchema(samples ...interface{}) *Schema {
+	in := NewSchemaInferrer(nil)
+	for _, v := range samples {
+		in.Add(v)
+	}
+	return in.Schema()
+}
+
+// InferSchemaJSON is like InferSchema for JSON documents, keeping the order
+// of the object properties as first seen.
+func InferSchemaJSON(samples ...[]byte) (*Schema, error) {
+	in := NewSchemaInferrer(nil)
+	for i, data := range samples {
+		if err := in.AddJSON(data); err != nil {
+			return nil, errors.Wrapf(err, "sample %d", i+1)
+		}
+	}
+	return in.Schema(), nil
+}
+
+// orderedObject is a decoded JSON object that remembers its key order.
+type orderedObject struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+func decodeOrdered(dec *json.Decoder) (interface{}, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch tok {
+	case json.Delim('{'):
+		obj := &orderedObject{values: map[string]interface{}{}}
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			v, err := decodeOrdered(dec)
+			if err != nil {
+				return nil, err
+			}
+			name := key.(string)
+			if _, ok := obj.values[name]; !ok {
+				obj.keys = append(obj.keys, name)
+			}
+			obj.values[name] = v
+		}
+		_, err = dec.Token()
+		return obj, err
+	case json.Delim('['):
+		list := []interface{}{}
+		for dec.More() {
+			v, err := decodeOrdered(dec)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, v)
+		}
+		_, err = dec.Token()
+		return list, err
+	}
+	return tok, nil
+}
+
+// inferNode accumulates the samples seen at one place of the documents.
+type inferNode struct {
+	types   []string
+	objects int
+	order   []string
+	props   map[string]*inferNode
+	seen    map[string]int
+	items   *inferNode
+
+	// scalars counts the string and integer values, values holds the
+	// distinct ones until there are too many for an enum.
+	scalars  int
+	values   []interface{}
+	tooMany  bool
+	format   string
+	noFormat bool
+}
+
+func (n *inferNode) addType(t string) {
+	for _, seen := range n.types {
+		if seen == t {
+			return
+		}
+	}
+	n.types = append(n.types, t)
+}
+
+func (n *inferNode) add(v interface{}, opts *InferOptions) {
+	switch value := v.(type) {
+	case *orderedObject:
+		n.addObject(value.keys, value.values, opts)
+	case map[string]interface{}:
+		keys := make([]string, 0, len(value))
+		for k := range value {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		n.addObject(keys, value, opts)
+	case []interface{}:
+		n.addType("array")
+		if n.items == nil {
+			n.items = new(inferNode)
+		}
+		for _, item := range value {
+			n.items.add(item, opts)
+		}
+	case string:
+		n.addType("string")
+		n.addScalar(value, opts)
+		n.addFormat(value, opts)
+	default:
+		t := jsonType(v)
+		n.addType(t)
+		if t == "integer" {
+			n.addScalar(v, opts)
+		}
+	}
+}
+
+func (n *inferNode) addScalar(v interface{}, opts *InferOptions) {
+	n.scalars++
+	if n.tooMany || opts.EnumMax <= 0 {
+		return
+	}
+	for _, seen := range n.values {
+		if jsonEqual(seen, v) {
+			return
+		}
+	}
+	if len(n.values) == opts.EnumMax {
+		n.tooMany, n.values = true, nil
+		return
+	}
+	n.values = append(n.values, v)
+}
+
+func (n *inferNode) addFormat(s string, opts *InferOptions) {
+	if n.noFormat || opts.NoFormats {
+		return
+	}
+	if n.format == "" {
+		n.format = detectFormat(s)
+	}
+	if n.format == "" || !formatMatchers[n.format](s) {
+		n.format, n.noFormat = "", true
+	}
+}
+
+func (n *inferNode) addObject(keys []string, values map[string]interface{}, opts *InferOptions) {
+	n.addType("object")
+	n.objects++
+	if n.props == nil {
+		n.props = map[string]*inferNode{}
+		n.seen = map[string]int{}
+	}
+	for _, k := range keys {
+		prop, ok := n.props[k]
+		if !ok {
+			prop = new(inferNode)
+			n.props[k] = prop
+			n.order = append(n.order, k)
+		}
+		n.seen[k]++
+		prop.add(values[k], opts)
+	}
+}
+
+func (n *inferNode) schema(opts *InferOptions) *Schema {
+	s := new(Schema)
+	types := n.mergedTypes()
+	switch len(types) {
+	case 0:
+	case 1:
+		s.Type = types[0]
+	default:
+		data, _ := json.Marshal(types)
+		s.Extra = map[string]json.RawMessage{"type": data}
+	}
+	for _, name := range n.order {
+		s.SetProperty(name, n.props[name].schema(opts))
+		if n.seen[name] == n.objects {
+			s.Required = append(s.Required, name)
+		}
+	}
+	if n.items != nil && len(n.items.types) > 0 {
+		s.Items = n.items.schema(opts)
+	}
+	if s.Type == "string" && !n.noFormat {
+		s.Format = n.format
+	}
+	if (s.Type == "string" || s.Type == "integer") && !n.tooMany && len(n.values) > 0 &&
+		n.scalars >= opts.EnumMinSamples && n.scalars > len(n.values) {
+		s.Enum = n.values
+	}
+	return s
+}
+
+// mergedTypes returns the types seen, folding integer into number and
+// listing null last.
+func (n *inferNode) mergedTypes() []string {
+	hasNumber, hasNull := false, false
+	for _, t := range n.types {
+		hasNumber = hasNumber || t == "number"
+		hasNull = hasNull || t == "null"
+	}
+	var types []string
+	for _, t := range n.types {
+		if t == "null" || (t == "integer" && hasNumber) {
+			continue
+		}
+		types = append(types, t)
+	}
+	if hasNull {
+		types = append(types, "null")
+	}
+	return types
+}
+
+// stringFormats lists the detected formats, most specific first.
+var stringFormats = []string{"date-time", "date", "uuid", "email", "ipv4", "uri"}
+
+var (
+	uuidPattern  = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	emailPattern = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)
+)
+
+var formatMatchers = map[string]func(string) bool{
+	"date-time": func(s string) bool {
+		_, err := time.Parse(time.RFC3339Nano, s)
+		return err == nil
+	},
+	"date": func(s string) bool {
+		_, err := time.Parse("2006-01-02", s)
+		return err == nil
+	},
+	"uuid":  uuidPattern.MatchString,
+	"email": emailPattern.MatchString,
+	"ipv4": func(s string) bool {
+		ip := net.ParseIP(s)
+		return ip != nil && ip.To4() != nil && strings.Count(s, ".") == 3
+	},
+	"uri": func(s string) bool {
+		u, err := url.Parse(s)
+		return err == nil && u.Scheme != "" && u.Host != ""
+	},
+}
+
+// detectFormat returns the format of s, or "" when none fits.
+func detectFormat(s string) string {
+	for _, f := range stringFormats {
+		if formatMatchers[f](s) {
+			return f
+		}
+	}
+	return ""
+}
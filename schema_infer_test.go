@@ -0,0 +1,99 @@
+package yapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestInferSchemaJSON(t *testing.T) {
+	s, err := InferSchemaJSON(
+		[]byte(`{"id": 1, "name": "a", "score": 1, "tags": ["x"], "owner": {"id": 7}, "items": []}`),
+		[]byte(`{"id": 2, "name": null, "score": 2.5, "tags": [], "extra": true, "items": []}`),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := s.PropertyNames(), []string{"id", "name", "score", "tags", "owner", "items", "extra"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("properties = %q, want %q", got, want)
+	}
+	if want := []string{"id", "name", "score", "tags", "items"}; !reflect.DeepEqual(s.Required, want) {
+		t.Errorf("required = %q, want %q", s.Required, want)
+	}
+	for name, want := range map[string][]string{
+		"id":    {"integer"},
+		"name":  {"string", "null"},
+		"score": {"number"},
+		"tags":  {"array"},
+		"owner": {"object"},
+		"extra": {"boolean"},
+	} {
+		if got := s.Properties[name].Types(); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s types = %q, want %q", name, got, want)
+		}
+	}
+	if s.Properties["tags"].Items.Type != "string" || s.Properties["items"].Items != nil {
+		t.Errorf("array items = %+v, %+v", s.Properties["tags"].Items, s.Properties["items"].Items)
+	}
+	if owner := s.Properties["owner"]; owner.Properties["id"].Type != "integer" || !owner.IsRequired("id") {
+		t.Errorf("owner = %s", owner)
+	}
+
+	// the inferred schema accepts its samples and serializes the type list
+	parsed, err := ParseSchema(s.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if errs := parsed.Validate(map[string]interface{}{"id": 3.0, "name": nil, "score": 1.0, "tags": []interface{}{}, "items": []interface{}{}}); len(errs) != 0 {
+		t.Errorf("Validate = %v", errs)
+	}
+
+	if _, err := InferSchemaJSON([]byte(`{"a": 1} x`)); err == nil {
+		t.Error("InferSchemaJSON accepted trailing data")
+	}
+}
+
+func TestInferSchema(t *testing.T) {
+	s := InferSchema([]interface{}{map[string]interface{}{"b": 1.0, "a": "x"}, map[string]interface{}{"a": "y"}})
+	if s.Type != "array" || s.Items.Type != "object" || !reflect.DeepEqual(s.Items.PropertyNames(), []string{"a", "b"}) || !reflect.DeepEqual(s.Items.Required, []string{"a"}) {
+		t.Errorf("schema = %s", s)
+	}
+}
+
+func TestSchemaInferrer_EnumsAndFormats(t *testing.T) {
+	in := NewSchemaInferrer(&InferOptions{EnumMax: 3, EnumMinSamples: 4})
+	for _, doc := range []string{
+		`{"status": "paid", "code": 1, "name": "a", "at": "2024-01-02T03:04:05Z", "day": "2024-01-02", "id": "0b5e1e7a-6f4c-4f5e-9d2a-3c1d2e4f5a6b", "mail": "a@example.com", "ip": "10.0.0.1", "site": "https://example.com/a", "mixed": "2024-01-02"}`,
+		`{"status": "open", "code": 2, "name": "b", "at": "2024-01-02T03:04:05.123+08:00", "day": "2024-02-29", "id": "1b5e1e7a-6f4c-4f5e-9d2a-3c1d2e4f5a6b", "mail": "b@example.org", "ip": "192.168.1.20", "site": "http://example.org", "mixed": "soon"}`,
+		`{"status": "paid", "code": 1, "name": "c"}`,
+		`{"status": "open", "code": 1, "name": "d"}`,
+	} {
+		if err := in.AddJSON([]byte(doc)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s := in.Schema()
+	if enum := s.Properties["status"].Enum; !reflect.DeepEqual(enum, []interface{}{"paid", "open"}) {
+		t.Errorf("status enum = %v", enum)
+	}
+	if data, _ := json.Marshal(s.Properties["code"].Enum); string(data) != "[1,2]" {
+		t.Errorf("code enum = %s", data)
+	}
+	if enum := s.Properties["name"].Enum; enum != nil {
+		t.Errorf("name enum = %v, want none with 4 distinct values", enum)
+	}
+	if enum := s.Properties["at"].Enum; enum != nil {
+		t.Errorf("at enum = %v, want none with 2 samples", enum)
+	}
+	for name, want := range map[string]string{"at": "date-time", "day": "date", "id": "uuid", "mail": "email", "ip": "ipv4", "site": "uri", "mixed": "", "name": ""} {
+		if got := s.Properties[name].Format; got != want {
+			t.Errorf("%s format = %q, want %q", name, got, want)
+		}
+	}
+
+	plain := NewSchemaInferrer(&InferOptions{NoFormats: true})
+	plain.Add("2024-01-02")
+	if s := plain.Schema(); s.Format != "" || s.Enum != nil {
+		t.Errorf("schema without formats and enums = %s", s)
+	}
+}
@@ -0,0 +1,149 @@
+package yapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+const testSchemaBody = `{
+  "$schema": "http://json-schema.org/draft-04/schema#",
+  "type": "object",
+  "properties": {
+    "zeta": {"type": "string", "mock": {"mock": "@string"}, "description": "last letter"},
+    "alpha": {"type": "array", "items": {"type": "integer", "minimum": 1}},
+    "nullable": {"type": ["string", "null"]}
+  },
+  "required": ["zeta"],
+  "x-custom": {"keep": true}
+}`
+
+func TestParseSchema(t *testing.T) {
+	s, err := ParseSchema(testSchemaBody)
+	if err != nil {
+		t.Fatalf("ParseSchema returned error: %v", err)
+	}
+	if s.Type != "object" {
+		t.Errorf("Type = %q, want object", s.Type)
+	}
+	if got, want := s.PropertyNames(), []string{"zeta", "alpha", "nullable"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("PropertyNames = %v, want %v", got, want)
+	}
+	if m := s.Properties["zeta"].Mock; m == nil || m.Mock != "@string" {
+		t.Errorf("zeta mock = %+v, want @string", m)
+	}
+	if min := s.Properties["alpha"].Items.Minimum; min == nil || *min != 1 {
+		t.Errorf("alpha items minimum = %v, want 1", min)
+	}
+	if !s.IsRequired("zeta") || s.IsRequired("alpha") {
+		t.Errorf("IsRequired mismatch, required = %v", s.Required)
+	}
+	if _, ok := s.Properties["nullable"].Extra["type"]; !ok {
+		t.Errorf("expected non-string type to be kept in Extra")
+	}
+}
+
+func TestSchema_RoundTrip(t *testing.T) {
+	s, err := ParseSchema(testSchemaBody)
+	if err != nil {
+		t.Fatalf("ParseSchema returned error: %v", err)
+	}
+	var want, got interface{}
+	if err := json.Unmarshal([]byte(testSchemaBody), &want); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(s.String()), &got); err != nil {
+		t.Fatalf("String() is not valid JSON: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("round trip mismatch\ngot  %s\nwant %s", s.String(), testSchemaBody)
+	}
+}
+
+func TestSchema_RoundTripEmptyValues(t *testing.T) {
+	for _, body := range []string{
+		`{"type":"object","title":"","properties":{},"required":[]}`,
+		`{"type":"string","default":null}`,
+		`{"type":"string","mock":{"mock":"@string","x":1}}`,
+		`{"type":"string","mock":{"x":1}}`,
+		`{"type":"array","items":{"type":"string","enum":[],"description":""},"required":null}`,
+	} {
+		s, err := ParseSchema(body)
+		if err != nil {
+			t.Fatalf("ParseSchema(%s) returned error: %v", body, err)
+		}
+		data, err := json.Marshal(s)
+		if err != nil {
+			t.Fatalf("Marshal(%s) returned error: %v", body, err)
+		}
+		var want, got interface{}
+		json.Unmarshal([]byte(body), &want)
+		json.Unmarshal(data, &got)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("round trip of %s = %s", body, data)
+		}
+	}
+}
+
+func TestSchema_SetProperty(t *testing.T) {
+	s, _ := ParseSchema(`{"type":"object","properties":{"b":{"type":"string"},"a":{"type":"string"}}}`)
+	s.SetProperty("c", &Schema{Type: "number"})
+	data, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"type":"object","properties":{"b":{"type":"string"},"a":{"type":"string"},"c":{"type":"number"}}}`
+	if string(data) != want {
+		t.Errorf("Marshal = %s, want %s", data, want)
+	}
+}
+
+func TestInterfaceData_BodySchema(t *testing.T) {
+	d := InterfaceData{}
+	d.ResBody = `{"code":0}`
+	if _, err := d.ResBodySchema(); err != ErrNotJSONSchema {
+		t.Errorf("ResBodySchema error = %v, want ErrNotJSONSchema", err)
+	}
+
+	d.SetResBodySchema(&Schema{Type: "object", Properties: map[string]*Schema{"code": {Type: "integer"}}})
+	if !d.ResBodyIsJsonSchema || d.ResBodyType != "json" {
+		t.Errorf("SetResBodySchema did not switch to json schema mode: %+v", d.interfaceRes)
+	}
+	s, err := d.ResBodySchema()
+	if err != nil {
+		t.Fatalf("ResBodySchema returned error: %v", err)
+	}
+	if s.Properties["code"].Type != "integer" {
+		t.Errorf("code type = %q, want integer", s.Properties["code"].Type)
+	}
+
+	d.ReqBodyIsJsonSchema = true
+	if s, err := d.ReqBodySchema(); s != nil || err != nil {
+		t.Errorf("empty ReqBodySchema = %v, %v, want nil, nil", s, err)
+	}
+}
+
+func TestSchema_Validate(t *testing.T) {
+	s, _ := ParseSchema(`{"type":"object","properties":{
+		"id":{"type":"integer","minimum":1},
+		"a/b":{"type":"string","maxLength":2},
+		"list":{"type":"array","items":{"type":"string","enum":["x","y"]}},
+		"opt":{"type":["string","null"]}
+	},"required":["id","missing"]}`)
+
+	var v interface{}
+	json.Unmarshal([]byte(`{"id":0.5,"a/b":"abc","list":["x","z"],"opt":null}`), &v)
+	got := map[string]bool{}
+	for _, e := range s.Validate(v) {
+		got[e.Pointer] = true
+	}
+	want := map[string]bool{"/id": true, "/missing": true, "/a~1b": true, "/list/1": true}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Validate pointers = %v, want %v", got, want)
+	}
+
+	json.Unmarshal([]byte(`{"id":3,"missing":1}`), &v)
+	if errs := s.Validate(v); len(errs) != 0 {
+		t.Errorf("Validate returned %v, want no errors", errs)
+	}
+}
@@ -0,0 +1,116 @@
+package yapi
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// DraftSchema is the $schema of the schemas written by the YApi editor.
+const DraftSchema = "http://json-schema.org/draft-04/schema#"
+
+// UpgradeOptions controls the conversion of example bodies to JSON Schemas.
+type UpgradeOptions struct {
+	// Infer tunes the inference, nil for DefaultInferOptions.
+	Infer *InferOptions
+	// Request also converts json request bodies; by default only the
+	// responses are.
+	Request bool
+	// DryRun computes the new bodies without saving them.
+	DryRun bool
+}
+
+// UpgradeSkip is an interface whose example body could not be converted.
+type UpgradeSkip struct {
+	ID     int    `json:"id"`
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	Reason string `json:"reason"`
+}
+
+// UpgradeResult lists the interfaces converted by UpgradeToSchema.
+type UpgradeResult struct {
+	Upgraded []InterfaceData `json:"upgraded"`
+	Skipped  []UpgradeSkip   `json:"skipped,omitempty"`
+}
+
+// UpgradeBodies replaces the JSON example response body of d, and request
+// body with opts.Request, by a schema inferred from the example. Bodies
+// already in schema mode, empty or of another type are left alone. It
+// reports whether d changed; the error describes the bodies that are not
+// valid JSON, the other body may still have been converted.
+func (d *InterfaceData) UpgradeBodies(opts *UpgradeOptions) (bool, error) {
+	if opts == nil {
+		opts = &UpgradeOptions{}
+	}
+	changed := false
+	var problems []string
+	if d.ResBodyType == "json" && !d.ResBodyIsJsonSchema && strings.TrimSpace(d.ResBody) != "" {
+		s, err := inferExample(d.ResBody, opts.Infer)
+		if err != nil {
+			problems = append(problems, "res_body: "+err.Error())
+		} else {
+			d.SetResBodySchema(s)
+			changed = true
+		}
+	}
+	if opts.Request && d.ReqBodyType == "json" && !d.ReqBodyIsJsonSchema && strings.TrimSpace(d.ReqBodyOther) != "" {
+		s, err := inferExample(d.ReqBodyOther, opts.Infer)
+		if err != nil {
+			problems = append(problems, "req_body_other: "+err.Error())
+		} else {
+			d.SetReqBodySchema(s)
+			changed = true
+		}
+	}
+	if len(problems) > 0 {
+		return changed, errors.New(strings.Join(problems, "; "))
+	}
+	return changed, nil
+}
+
+func inferExample(body string, opts *InferOptions) (*Schema, error) {
+	in := NewSchemaInferrer(opts)
+	if err := in.AddJSON([]byte(body)); err != nil {
+		return nil, err
+	}
+	s := in.Schema()
+	s.Schema = DraftSchema
+	return s, nil
+}
+
+// UpgradeToSchema converts the JSON example bodies of every interface of the
+// project to schema mode, see InterfaceData.UpgradeBodies, and saves the
+// changed interfaces with the up api unless opts.DryRun is set. Interfaces
+// with invalid examples are listed in Skipped.
+func (s *InterfaceService) UpgradeToSchema(projectID int, opts *UpgradeOptions) (*UpgradeResult, error) {
+	if opts == nil {
+		opts = &UpgradeOptions{}
+	}
+	all, err := s.GetAll(projectID)
+	if err != nil {
+		return nil, err
+	}
+	res := &UpgradeResult{}
+	for i := range all {
+		d := &all[i]
+		changed, err := d.UpgradeBodies(opts)
+		if err != nil {
+			res.Skipped = append(res.Skipped, UpgradeSkip{ID: d.ID, Method: d.Method, Path: d.Path, Reason: err.Error()})
+		}
+		if !changed {
+			continue
+		}
+		if !opts.DryRun {
+			resp, err := s.Update(d)
+			if err == nil {
+				err = CheckErrCode(resp.ErrCode, resp.ErrMsg)
+			}
+			if err != nil {
+				return res, errors.Wrapf(err, "update %s %s", d.Method, d.Path)
+			}
+		}
+		res.Upgraded = append(res.Upgraded, *d)
+	}
+	return res, nil
+}
@@ -0,0 +1,86 @@
+package yapi
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestInterfaceData_UpgradeBodies(t *testing.T) {
+	var d InterfaceData
+	d.ResBodyType, d.ResBody = "json", `{"id": 1, "tags": ["a"]}`
+	d.ReqBodyType, d.ReqBodyOther = "json", `{"name": "x",}`
+	changed, err := d.UpgradeBodies(&UpgradeOptions{Request: true})
+	if !changed || err == nil || !strings.HasPrefix(err.Error(), "req_body_other: ") {
+		t.Fatalf("UpgradeBodies = %v, %v", changed, err)
+	}
+	s, err := d.ResBodySchema()
+	if err != nil || s.Schema != DraftSchema || s.Type != "object" || s.Properties["tags"].Items.Type != "string" {
+		t.Errorf("response schema = %s, %v", s, err)
+	}
+	if d.ReqBodyIsJsonSchema || d.ReqBodyOther != `{"name": "x",}` {
+		t.Errorf("invalid request body was modified: %q", d.ReqBodyOther)
+	}
+
+	// schema bodies and other body types are left alone
+	if changed, err := d.UpgradeBodies(nil); changed || err != nil {
+		t.Errorf("second UpgradeBodies = %v, %v", changed, err)
+	}
+	var raw InterfaceData
+	raw.ResBodyType, raw.ResBody = "raw", `{"id": 1}`
+	if changed, _ := raw.UpgradeBodies(nil); changed {
+		t.Error("raw body was upgraded")
+	}
+}
+
+func TestInterfaceService_UpgradeToSchema(t *testing.T) {
+	interfaces := map[string]string{
+		"1": `{"_id": 1, "method": "GET", "path": "/a", "res_body_type": "json", "res_body": "{\"id\": 1}"}`,
+		"2": `{"_id": 2, "method": "GET", "path": "/b", "res_body_type": "json", "res_body_is_json_schema": true, "res_body": "{\"type\": \"object\"}"}`,
+		"3": `{"_id": 3, "method": "GET", "path": "/c", "res_body_type": "json", "res_body": "{id: 1}"}`,
+	}
+	var updated []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/interface/getCatMenu", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"errcode": 0, "data": [{"_id": 5, "name": "all"}]}`))
+	})
+	mux.HandleFunc("/api/interface/list_cat", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"errcode": 0, "data": {"count": 3, "total": 1, "list": [{"_id": 1}, {"_id": 2}, {"_id": 3}]}}`))
+	})
+	mux.HandleFunc("/api/interface/get", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"errcode": 0, "data": ` + interfaces[r.URL.Query().Get("id")] + `}`))
+	})
+	mux.HandleFunc("/api/interface/up", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		var d InterfaceData
+		json.Unmarshal(body, &d)
+		updated = append(updated, d.Path)
+		if !d.ResBodyIsJsonSchema || !strings.Contains(d.ResBody, `"integer"`) {
+			t.Errorf("update %s with %s", d.Path, d.ResBody)
+		}
+		w.Write([]byte(`{"errcode": 0, "data": {"n": 1}}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	client, err := NewClient(server.URL, "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := client.Interface.UpgradeToSchema(11, &UpgradeOptions{DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Upgraded) != 1 || res.Upgraded[0].Path != "/a" || len(res.Skipped) != 1 || res.Skipped[0].ID != 3 || len(updated) != 0 {
+		t.Errorf("dry run = %+v, updated %v", res, updated)
+	}
+	if _, err := client.Interface.UpgradeToSchema(11, nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(updated) != 1 || updated[0] != "/a" {
+		t.Errorf("updated = %v", updated)
+	}
+}
@@ -0,0 +1,174 @@
+package yapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ValidationError describes a place where a value does not conform to a Schema.
+// Pointer is the JSON pointer (RFC 6901) of the offending value.
+type ValidationError struct {
+	Pointer string `json:"pointer"`
+	Message string `json:"message"`
+}
+
+func (e ValidationError) Error() string {
+	pointer := e.Pointer
+	if pointer == "" {
+		pointer = "/"
+	}
+	return pointer + ": " + e.Message
+}
+
+// Types returns the allowed types of the node, reading a type list kept in Extra
+// when "type" is not a single string.
+func (s *Schema) Types() []string {
+	if s.Type != "" {
+		return []string{s.Type}
+	}
+	var types []string
+	if raw, ok := s.Extra["type"]; ok {
+		json.Unmarshal(raw, &types)
+	}
+	return types
+}
+
+// Validate checks v, a value as decoded by encoding/json, against the schema
+// and returns every mismatch found. A nil schema accepts any value.
+func (s *Schema) Validate(v interface{}) []ValidationError {
+	var errs []ValidationError
+	s.validate(v, "", &errs)
+	return errs
+}
+
+func (s *Schema) validate(v interface{}, pointer string, errs *[]ValidationError) {
+	if s == nil {
+		return
+	}
+	fail := func(format string, args ...interface{}) {
+		*errs = append(*errs, ValidationError{Pointer: pointer, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if types := s.Types(); len(types) > 0 {
+		actual := jsonType(v)
+		ok := false
+		for _, t := range types {
+			if t == actual || (t == "number" && actual == "integer") {
+				ok = true
+			}
+		}
+		if !ok {
+			fail("expected type %s, got %s", strings.Join(types, " or "), actual)
+			return
+		}
+	}
+
+	if len(s.Enum) > 0 {
+		found := false
+		for _, e := range s.Enum {
+			if jsonEqual(e, v) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			fail("value %v is not one of the enum values", v)
+		}
+	}
+
+	switch value := v.(type) {
+	case map[string]interface{}:
+		for _, name := range s.Required {
+			if _, ok := value[name]; !ok {
+				*errs = append(*errs, ValidationError{Pointer: pointer + "/" + escapePointer(name), Message: "required property is missing"})
+			}
+		}
+		for _, name := range s.PropertyNames() {
+			if pv, ok := value[name]; ok {
+				s.Properties[name].validate(pv, pointer+"/"+escapePointer(name), errs)
+			}
+		}
+		if raw, ok := s.Extra["additionalProperties"]; ok && string(raw) == "false" {
+			for name := range value {
+				if _, ok := s.Properties[name]; !ok {
+					*errs = append(*errs, ValidationError{Pointer: pointer + "/" + escapePointer(name), Message: "additional property is not allowed"})
+				}
+			}
+		}
+	case []interface{}:
+		if s.MinItems != nil && len(value) < *s.MinItems {
+			fail("expected at least %d items, got %d", *s.MinItems, len(value))
+		}
+		if s.MaxItems != nil && len(value) > *s.MaxItems {
+			fail("expected at most %d items, got %d", *s.MaxItems, len(value))
+		}
+		if s.Items != nil {
+			for i, item := range value {
+				s.Items.validate(item, pointer+"/"+strconv.Itoa(i), errs)
+			}
+		}
+	case string:
+		n := utf8.RuneCountInString(value)
+		if s.MinLength != nil && n < *s.MinLength {
+			fail("expected at least %d characters, got %d", *s.MinLength, n)
+		}
+		if s.MaxLength != nil && n > *s.MaxLength {
+			fail("expected at most %d characters, got %d", *s.MaxLength, n)
+		}
+		if s.Pattern != "" {
+			if re, err := regexp.Compile(s.Pattern); err == nil && !re.MatchString(value) {
+				fail("value %q does not match pattern %s", value, s.Pattern)
+			}
+		}
+	case float64:
+		if s.Minimum != nil && value < *s.Minimum {
+			fail("value %v is less than minimum %v", value, *s.Minimum)
+		}
+		if s.Maximum != nil && value > *s.Maximum {
+			fail("value %v is greater than maximum %v", value, *s.Maximum)
+		}
+	}
+}
+
+// jsonType returns the JSON Schema type name of a decoded JSON value.
+func jsonType(v interface{}) string {
+	switch value := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if value == math.Trunc(value) {
+			return "integer"
+		}
+		return "number"
+	case json.Number:
+		if _, err := value.Int64(); err == nil {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return reflect.TypeOf(v).String()
+}
+
+func jsonEqual(a, b interface{}) bool {
+	ja, err1 := json.Marshal(a)
+	jb, err2 := json.Marshal(b)
+	return err1 == nil && err2 == nil && string(ja) == string(jb)
+}
+
+func escapePointer(s string) string {
+	return strings.Replace(strings.Replace(s, "~", "~0", -1), "/", "~1", -1)
+}
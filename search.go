@@ -0,0 +1,312 @@
+package yapi
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+// SearchService finds projects and interfaces, on the server with Remote or
+// across the projects added to a SearchIndex.
+type SearchService struct {
+	client *Client
+}
+
+type SearchProject struct {
+	ID       int    `json:"_id" structs:"_id"`
+	UID      int    `json:"uid" structs:"uid"`
+	GroupID  int    `json:"group_id" structs:"group_id"`
+	Name     string `json:"name" structs:"name"`
+	Basepath string `json:"basepath" structs:"basepath"`
+}
+
+type SearchGroup struct {
+	ID        int    `json:"_id" structs:"_id"`
+	UID       int    `json:"uid" structs:"uid"`
+	GroupName string `json:"group_name" structs:"group_name"`
+	GroupDesc string `json:"group_desc" structs:"group_desc"`
+}
+
+type SearchInterface struct {
+	ID        int    `json:"_id" structs:"_id"`
+	UID       int    `json:"uid" structs:"uid"`
+	ProjectID int    `json:"project_id" structs:"project_id"`
+	Title     string `json:"title" structs:"title"`
+}
+
+// SearchResult is the answer of api/project/search, which matches q in the
+// names of projects and groups and in the titles of interfaces.
+type SearchResult struct {
+	Project   []SearchProject   `json:"project" structs:"project"`
+	Group     []SearchGroup     `json:"group" structs:"group"`
+	Interface []SearchInterface `json:"interface" structs:"interface"`
+}
+
+type SearchResp struct {
+	CommonResp
+	Data   SearchResult `json:"data" structs:"data"`
+	string string
+}
+
+func (s *SearchResp) ToString() string {
+	return s.string
+}
+
+type SearchParam struct {
+	Token string `url:"token,omitempty"`
+	Q     string `url:"q"`
+}
+
+// Remote searches the projects, groups and interfaces visible to the user
+// with api/project/search.
+func (s *SearchService) Remote(q string) (*SearchResp, error) {
+	apiEndpoint := "api/project/search"
+	url, err := addOptions(apiEndpoint, &SearchParam{Token: s.client.Authentication.token, Q: q})
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.client.Get(url, nil)
+	if err != nil {
+		return nil, err
+	}
+	result := SearchResp{}
+	result.string = resp
+	err = json.Unmarshal([]byte(resp), &result)
+	return &result, err
+}
+
+// IndexProject adds the project of the client token, with its categories
+// and interfaces, to ix. The hits name the base URL of the client as their
+// Instance.
+func (s *SearchService) IndexProject(ix *SearchIndex) error {
+	project, err := s.client.Project.Get()
+	if err != nil {
+		return err
+	}
+	if err := CheckErrCode(project.ErrCode, project.ErrMsg); err != nil {
+		return err
+	}
+	menu, err := s.client.CatMenu.Get(project.Data.ID)
+	if err != nil {
+		return err
+	}
+	if err := CheckErrCode(menu.ErrCode, menu.ErrMsg); err != nil {
+		return err
+	}
+	all, err := s.client.Interface.GetAllInCats(menu.Data)
+	if err != nil {
+		return err
+	}
+	u := s.client.GetBaseURL()
+	ix.AddInstance(u.String(), project.Data, menu.Data, all)
+	return nil
+}
+
+// Search field weights: a match in the title counts more than one in a
+// parameter description.
+const (
+	weightTitle = 5
+	weightPath  = 4
+	weightTag   = 3
+	weightParam = 2
+	weightDesc  = 1
+)
+
+// SearchHit is an interface found by SearchIndex.Search.
+type SearchHit struct {
+	Score int `json:"score"`
+	// Instance tells apart projects of several YApi instances, which may
+	// share project IDs; see SearchIndex.AddInstance.
+	Instance    string `json:"instance,omitempty"`
+	ProjectID   int    `json:"project_id"`
+	ProjectName string `json:"project_name"`
+	CatID       int    `json:"catid"`
+	CatName     string `json:"cat_name"`
+	InterfaceID int    `json:"interface_id"`
+	Method      string `json:"method"`
+	Path        string `json:"path"`
+	Title       string `json:"title"`
+	// Fields lists where the terms were found: "title", "path", "tag",
+	// "param" or "desc".
+	Fields []string `json:"fields"`
+}
+
+// SearchIndex is a full-text index of interfaces of one or more projects,
+// kept in memory. The zero value is an empty index.
+type SearchIndex struct {
+	docs []searchDoc
+}
+
+type searchDoc struct {
+	hit    SearchHit
+	fields []searchField
+}
+
+type searchField struct {
+	name   string
+	weight int
+	text   string
+}
+
+// NewSearchIndex returns an empty index.
+func NewSearchIndex() *SearchIndex {
+	return &SearchIndex{}
+}
+
+// Add indexes the interfaces of a project. cats names the categories of the
+// interfaces; unknown categories are left blank.
+func (ix *SearchIndex) Add(project ProjectData, cats []CatData, interfaces []InterfaceData) {
+	ix.AddInstance("", project, cats, interfaces)
+}
+
+// AddInstance is Add for a project of the YApi instance named instance,
+// usually its base URL, which is set as the Instance of the hits.
+func (ix *SearchIndex) AddInstance(instance string, project ProjectData, cats []CatData, interfaces []InterfaceData) {
+	names := make(map[int]string, len(cats))
+	for _, cat := range cats {
+		names[cat.ID] = cat.Name
+	}
+	for i := range interfaces {
+		d := &interfaces[i]
+		doc := searchDoc{hit: SearchHit{
+			Instance:    instance,
+			ProjectID:   project.ID,
+			ProjectName: project.Name,
+			CatID:       d.CatID,
+			CatName:     names[d.CatID],
+			InterfaceID: d.ID,
+			Method:      d.Method,
+			Path:        d.Path,
+			Title:       d.Title,
+		}}
+		doc.add("title", weightTitle, d.Title)
+		doc.add("path", weightPath, d.Path)
+		for _, tag := range d.Tag {
+			doc.add("tag", weightTag, tag)
+		}
+		for _, p := range d.ReqParams {
+			doc.add("param", weightParam, p.Name)
+			doc.add("desc", weightDesc, p.Desc)
+		}
+		for _, list := range [][]ReqKVItemDetail{d.ReqQuery, d.ReqHeaders, d.ReqBodyForm} {
+			for _, p := range list {
+				doc.add("param", weightParam, p.Name)
+				doc.add("desc", weightDesc, p.Desc)
+			}
+		}
+		if d.ReqBodyIsJsonSchema {
+			if s, err := d.ReqBodySchema(); err == nil {
+				doc.addSchema(s)
+			}
+		}
+		if d.ResBodyIsJsonSchema {
+			if s, err := d.ResBodySchema(); err == nil {
+				doc.addSchema(s)
+			}
+		}
+		ix.docs = append(ix.docs, doc)
+	}
+}
+
+func (doc *searchDoc) add(name string, weight int, text string) {
+	if text = strings.ToLower(strings.TrimSpace(text)); text != "" {
+		doc.fields = append(doc.fields, searchField{name: name, weight: weight, text: text})
+	}
+}
+
+// addSchema indexes the property names and descriptions of a body schema.
+func (doc *searchDoc) addSchema(s *Schema) {
+	if s == nil {
+		return
+	}
+	doc.add("desc", weightDesc, s.Title)
+	doc.add("desc", weightDesc, s.Description)
+	for _, name := range s.PropertyNames() {
+		doc.add("param", weightParam, name)
+		doc.addSchema(s.Properties[name])
+	}
+	doc.addSchema(s.Items)
+}
+
+// Search returns the interfaces matching every term of q, best first, at
+// most limit of them unless limit is 0. Terms are matched case
+// insensitively as substrings; a term matching a whole word of a field
+// scores twice as much.
+func (ix *SearchIndex) Search(q string, limit int) []SearchHit {
+	terms := strings.Fields(strings.ToLower(q))
+	if len(terms) == 0 {
+		return nil
+	}
+	var hits []SearchHit
+	for _, doc := range ix.docs {
+		if hit, ok := doc.match(terms); ok {
+			hits = append(hits, hit)
+		}
+	}
+	sort.SliceStable(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		if hits[i].Instance != hits[j].Instance {
+			return hits[i].Instance < hits[j].Instance
+		}
+		if hits[i].ProjectID != hits[j].ProjectID {
+			return hits[i].ProjectID < hits[j].ProjectID
+		}
+		return hits[i].Path < hits[j].Path
+	})
+	if limit > 0 && len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return hits
+}
+
+func (doc *searchDoc) match(terms []string) (SearchHit, bool) {
+	hit := doc.hit
+	seen := map[string]bool{}
+	for _, term := range terms {
+		best := 0
+		for _, f := range doc.fields {
+			if !strings.Contains(f.text, term) {
+				continue
+			}
+			score := f.weight
+			if hasWord(f.text, term) {
+				score *= 2
+			}
+			hit.Score += score
+			if score > best {
+				best = score
+			}
+			if !seen[f.name] {
+				seen[f.name] = true
+				hit.Fields = append(hit.Fields, f.name)
+			}
+		}
+		if best == 0 {
+			return hit, false
+		}
+	}
+	return hit, true
+}
+
+// hasWord reports whether term appears in text between characters that are
+// not ASCII letters, digits or underscores.
+func hasWord(text, term string) bool {
+	for i := 0; ; {
+		j := strings.Index(text[i:], term)
+		if j < 0 {
+			return false
+		}
+		start, end := i+j, i+j+len(term)
+		if (start == 0 || !isWordByte(text, start-1)) && (end == len(text) || !isWordByte(text, end)) {
+			return true
+		}
+		i = start + 1
+	}
+}
+
+func isWordByte(text string, i int) bool {
+	c := text[i]
+	return c == '_' || ('0' <= c && c <= '9') || ('a' <= c && c <= 'z')
+}
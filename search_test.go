@@ -0,0 +1,138 @@
+package yapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSearchIndex(t *testing.T) {
+	var orders, refund, users InterfaceData
+	orders.ID, orders.CatID, orders.Method, orders.Path, orders.Title = 1, 10, "GET", "/orders", "List orders"
+	orders.ReqQuery = []ReqKVItemDetail{{ReqKVItemSimple: ReqKVItemSimple{Name: "status", Desc: "filter by payment state"}}}
+	refund.ID, refund.CatID, refund.Method, refund.Path, refund.Title = 2, 10, "POST", "/orders/{id}/refund", "退款"
+	refund.Tag = []string{"payment"}
+	users.ID, users.CatID, users.Method, users.Path, users.Title = 3, 20, "GET", "/users", "List users"
+	users.SetResBodySchema(&Schema{Type: "object", Properties: map[string]*Schema{
+		"orders": {Type: "array", Description: "recent orders of the user"},
+	}})
+
+	ix := NewSearchIndex()
+	ix.Add(ProjectData{ID: 11, Name: "shop"}, []CatData{{ID: 10, Name: "orders"}}, []InterfaceData{orders, refund})
+	ix.Add(ProjectData{ID: 12, Name: "accounts"}, nil, []InterfaceData{users})
+
+	tests := []struct {
+		q    string
+		want string
+	}{
+		// title and path words beat a path prefix and a schema property
+		{"orders", "[shop/orders GET /orders shop/orders POST /orders/{id}/refund accounts/ GET /users]"},
+		{"ORDER", "[shop/orders GET /orders shop/orders POST /orders/{id}/refund accounts/ GET /users]"},
+		{"payment", "[shop/orders POST /orders/{id}/refund shop/orders GET /orders]"},
+		{"orders refund", "[shop/orders POST /orders/{id}/refund]"},
+		{"退款", "[shop/orders POST /orders/{id}/refund]"},
+		{"recent", "[accounts/ GET /users]"},
+		{"missing", "[]"},
+		{"  ", "[]"},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, h := range ix.Search(tt.q, 0) {
+			got = append(got, h.ProjectName+"/"+h.CatName+" "+h.Method+" "+h.Path)
+		}
+		if fmt.Sprint(got) != tt.want {
+			t.Errorf("Search(%q) = %v, want %v", tt.q, got, tt.want)
+		}
+	}
+
+	hits := ix.Search("orders", 1)
+	if len(hits) != 1 || hits[0].InterfaceID != 1 || fmt.Sprint(hits[0].Fields) != "[title path]" {
+		t.Errorf("limited search = %+v", hits)
+	}
+}
+
+func TestSearchService_Remote(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/project/search" || r.URL.Query().Get("q") != "order" {
+			t.Errorf("request %s", r.URL)
+		}
+		w.Write([]byte(`{"errcode": 0, "data": {
+			"project": [{"_id": 11, "name": "orders", "basepath": "/api"}],
+			"group": [{"_id": 3, "group_name": "order team"}],
+			"interface": [{"_id": 7, "title": "create order", "project_id": 11}]
+		}}`))
+	}))
+	defer server.Close()
+	client, err := NewClient(server.URL, "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.Search.Remote("order")
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := resp.Data
+	if len(d.Project) != 1 || d.Project[0].Basepath != "/api" || d.Group[0].GroupName != "order team" || d.Interface[0].ProjectID != 11 {
+		t.Errorf("result = %+v", d)
+	}
+}
+
+func TestSearchService_IndexProject(t *testing.T) {
+	// two instances with a project of the same ID
+	instance := func(title string, menuCalls *int) *httptest.Server {
+		var d InterfaceData
+		d.ID, d.CatID, d.ProjectID, d.Method, d.Path, d.Title = 1, 10, 11, "GET", "/orders", title
+		reply := func(w http.ResponseWriter, data interface{}) {
+			json.NewEncoder(w).Encode(map[string]interface{}{"errcode": 0, "errmsg": "成功！", "data": data})
+		}
+		mux := http.NewServeMux()
+		mux.HandleFunc("/api/project/get", func(w http.ResponseWriter, r *http.Request) {
+			reply(w, ProjectData{ID: 11, Name: "shop"})
+		})
+		mux.HandleFunc("/api/interface/getCatMenu", func(w http.ResponseWriter, r *http.Request) {
+			*menuCalls++
+			reply(w, CatMenuData{{ID: 10, Name: "orders"}})
+		})
+		mux.HandleFunc("/api/interface/list_cat", func(w http.ResponseWriter, r *http.Request) {
+			reply(w, InterfaceListData{Count: 1, Total: 1, List: []InterfaceData{d}})
+		})
+		mux.HandleFunc("/api/interface/get", func(w http.ResponseWriter, r *http.Request) {
+			reply(w, d)
+		})
+		server := httptest.NewServer(mux)
+		t.Cleanup(server.Close)
+		return server
+	}
+	var prodCalls, testCalls int
+	prod, test := instance("list orders", &prodCalls), instance("list test orders", &testCalls)
+
+	ix := NewSearchIndex()
+	for _, server := range []*httptest.Server{prod, test} {
+		client, err := NewClient(server.URL, "token")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := client.Search.IndexProject(ix); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if prodCalls != 1 || testCalls != 1 {
+		t.Errorf("category menu fetched %d and %d times, want once", prodCalls, testCalls)
+	}
+	hits := ix.Search("orders", 0)
+	if len(hits) != 2 || hits[0].ProjectID != hits[1].ProjectID {
+		t.Fatalf("hits = %+v", hits)
+	}
+	for _, h := range hits {
+		server := prod
+		if strings.Contains(h.Title, "test") {
+			server = test
+		}
+		if h.Instance != server.URL+"/" {
+			t.Errorf("hit %q has instance %q, want %q", h.Title, h.Instance, server.URL+"/")
+		}
+	}
+}
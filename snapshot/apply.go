@@ -0,0 +1,241 @@
+package snapshot
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	yapi "github.com/micrease/go-yapi"
+	"github.com/micrease/go-yapi/diff"
+)
+
+// Action is the kind of change a plan item makes to the remote project.
+type Action string
+
+const (
+	ActionCreate Action = "create"
+	ActionUpdate Action = "update"
+	ActionDelete Action = "delete"
+)
+
+// ApplyOptions configures how a local snapshot is reconciled with the remote project.
+type ApplyOptions struct {
+	// Prune deletes remote interfaces that do not exist locally.
+	Prune bool
+}
+
+// CategoryChange is a category to create.
+type CategoryChange struct {
+	Action Action `json:"action"`
+	Name   string `json:"name"`
+	Desc   string `json:"desc,omitempty"`
+}
+
+// InterfaceChange is an interface to create, update or delete.
+type InterfaceChange struct {
+	Action   Action        `json:"action"`
+	Category string        `json:"category"`
+	Method   string        `json:"method"`
+	Path     string        `json:"path"`
+	Title    string        `json:"title"`
+	RemoteID int           `json:"remote_id,omitempty"`
+	Changes  []diff.Change `json:"changes,omitempty"`
+
+	local       *yapi.InterfaceData
+	pathChanged bool
+}
+
+// Plan lists the changes that make the remote project match a local snapshot.
+type Plan struct {
+	ProjectID  int               `json:"project_id"`
+	Creates    int               `json:"creates"`
+	Updates    int               `json:"updates"`
+	Deletes    int               `json:"deletes"`
+	Categories []CategoryChange  `json:"categories,omitempty"`
+	Interfaces []InterfaceChange `json:"interfaces,omitempty"`
+
+	remoteCats map[string]int
+}
+
+// Empty reports whether the plan has nothing to do.
+func (p *Plan) Empty() bool {
+	return len(p.Categories) == 0 && len(p.Interfaces) == 0
+}
+
+// ComputePlan compares a local snapshot with the remote one. Categories are
+// matched by name, interfaces by method and path, or by ID when the local
+// interface carries the ID of a remote interface whose path changed. IDs are
+// only followed when both snapshots are of the same project, and when the
+// method and path of the remote interface are gone from the local snapshot.
+func ComputePlan(local, remote *Snapshot, opts *ApplyOptions) *Plan {
+	if opts == nil {
+		opts = &ApplyOptions{}
+	}
+	p := &Plan{ProjectID: remote.Project.ID, remoteCats: make(map[string]int)}
+
+	type remoteItem struct {
+		data     *yapi.InterfaceData
+		category string
+		matched  bool
+	}
+	var remoteItems []*remoteItem
+	byKey := make(map[string]*remoteItem)
+	byID := make(map[int]*remoteItem)
+	for ci := range remote.Categories {
+		cat := &remote.Categories[ci]
+		if _, ok := p.remoteCats[cat.Name]; !ok {
+			p.remoteCats[cat.Name] = cat.ID
+		}
+		for i := range cat.Interfaces {
+			item := &remoteItem{data: &cat.Interfaces[i], category: cat.Name}
+			remoteItems = append(remoteItems, item)
+			byKey[diff.Key(item.data)] = item
+			byID[item.data.ID] = item
+		}
+	}
+
+	followIDs := local.Project.ID != 0 && local.Project.ID == remote.Project.ID
+	localKeys := make(map[string]bool)
+	for _, d := range local.Interfaces() {
+		localKeys[diff.Key(&d)] = true
+	}
+
+	for ci := range local.Categories {
+		cat := &local.Categories[ci]
+		if _, ok := p.remoteCats[cat.Name]; !ok {
+			p.Categories = append(p.Categories, CategoryChange{Action: ActionCreate, Name: cat.Name, Desc: cat.Desc})
+			p.remoteCats[cat.Name] = 0
+		}
+		for i := range cat.Interfaces {
+			d := &cat.Interfaces[i]
+			change := InterfaceChange{Category: cat.Name, Method: strings.ToUpper(d.Method), Path: d.Path, Title: d.Title, local: d}
+			item, ok := byKey[diff.Key(d)]
+			if !ok && d.ID != 0 && followIDs {
+				if found := byID[d.ID]; found != nil && !localKeys[diff.Key(found.data)] {
+					item, ok = found, true
+				}
+			}
+			if !ok || item.matched {
+				change.Action = ActionCreate
+				p.Creates++
+				p.Interfaces = append(p.Interfaces, change)
+				continue
+			}
+			item.matched = true
+			change.RemoteID = item.data.ID
+			change.pathChanged = diff.Key(item.data) != diff.Key(d)
+			change.Changes = diff.Interfaces(item.data, d).Changes
+			if item.category != cat.Name {
+				moved := diff.Change{Location: diff.LocInterface, Kind: diff.Changed, Name: "category", Old: item.category, New: cat.Name}
+				change.Changes = append([]diff.Change{moved}, change.Changes...)
+			}
+			if len(change.Changes) > 0 {
+				change.Action = ActionUpdate
+				p.Updates++
+				p.Interfaces = append(p.Interfaces, change)
+			}
+		}
+	}
+
+	if opts.Prune {
+		for _, item := range remoteItems {
+			if item.matched {
+				continue
+			}
+			p.Deletes++
+			p.Interfaces = append(p.Interfaces, InterfaceChange{
+				Action: ActionDelete, Category: item.category, Method: strings.ToUpper(item.data.Method),
+				Path: item.data.Path, Title: item.data.Title, RemoteID: item.data.ID,
+			})
+		}
+	}
+	p.Creates += len(p.Categories)
+	return p
+}
+
+// WriteText writes the plan in a human readable form.
+func (p *Plan) WriteText(w io.Writer) error {
+	for _, c := range p.Categories {
+		fmt.Fprintf(w, "+ category %q\n", c.Name)
+	}
+	for _, c := range p.Interfaces {
+		sign := map[Action]string{ActionCreate: "+", ActionUpdate: "~", ActionDelete: "-"}[c.Action]
+		fmt.Fprintf(w, "%s %s %s (%s) [%s]\n", sign, c.Method, c.Path, c.Title, c.Category)
+		for _, f := range c.Changes {
+			fmt.Fprintf(w, "    %s\n", f)
+		}
+	}
+	_, err := fmt.Fprintf(w, "Plan: %d to create, %d to update, %d to delete.\n", p.Creates, p.Updates, p.Deletes)
+	return err
+}
+
+// Execute applies the plan to the remote project: it creates the missing
+// categories, then creates and updates interfaces with the save api and
+// deletes interfaces, in plan order.
+func (p *Plan) Execute(c *yapi.Client) error {
+	for _, cat := range p.Categories {
+		param := new(yapi.ModifyMenuParam)
+		param.ProjectID = p.ProjectID
+		param.Name = cat.Name
+		param.Desc = cat.Desc
+		resp, err := c.CatMenu.AddOrUpdate(param)
+		if err != nil {
+			return err
+		}
+		if err := yapi.CheckErrCode(resp.ErrCode, resp.ErrMsg); err != nil {
+			return fmt.Errorf("create category %q: %v", cat.Name, err)
+		}
+		id, err := yapi.CreatedID(resp.Data)
+		if err != nil {
+			return fmt.Errorf("create category %q: %v", cat.Name, err)
+		}
+		p.remoteCats[cat.Name] = id
+	}
+
+	for _, change := range p.Interfaces {
+		var resp *yapi.ModifyResp
+		var err error
+		switch change.Action {
+		case ActionCreate, ActionUpdate:
+			d := *change.local
+			d.ProjectID = p.ProjectID
+			d.CatID = p.remoteCats[change.Category]
+			d.ID = change.RemoteID
+			if change.pathChanged {
+				// save matches on method and path, only up can change them
+				resp, err = c.Interface.Update(&d)
+			} else {
+				resp, err = c.Interface.AddOrUpdate(&d)
+			}
+		case ActionDelete:
+			resp, err = c.Interface.Delete(change.RemoteID)
+		}
+		if err == nil {
+			err = yapi.CheckErrCode(resp.ErrCode, resp.ErrMsg)
+		}
+		if err != nil {
+			return fmt.Errorf("%s %s %s: %v", change.Action, change.Method, change.Path, err)
+		}
+	}
+	return nil
+}
+
+// PlanApply loads the snapshot in dir and computes the plan against the
+// project the client token belongs to.
+func PlanApply(c *yapi.Client, dir string, opts *ApplyOptions) (*Plan, error) {
+	local, err := Load(dir)
+	if err != nil {
+		return nil, err
+	}
+	return PlanSnapshot(c, local, opts)
+}
+
+// PlanSnapshot computes the plan of a local snapshot against the project the
+// client token belongs to.
+func PlanSnapshot(c *yapi.Client, local *Snapshot, opts *ApplyOptions) (*Plan, error) {
+	remote, err := Dump(c)
+	if err != nil {
+		return nil, err
+	}
+	return ComputePlan(local, remote, opts), nil
+}
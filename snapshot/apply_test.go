@@ -0,0 +1,145 @@
+package snapshot
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	yapi "github.com/micrease/go-yapi"
+)
+
+func TestComputePlan(t *testing.T) {
+	remote := testSnapshot()
+	local := testSnapshot()
+
+	users := &local.Categories[0]
+	users.Interfaces[0].Title = "create user" // update
+	users.Interfaces[1].Path = "/users/{uid}" // path change matched by ID
+	users.Interfaces[1].ReqQuery = nil        // field removed
+	local.Categories[1].Interfaces = nil      // GET /orders/:id is deleted when pruning
+	local.Categories = append(local.Categories, Category{
+		CatData:    yapi.CatData{Name: "new"},
+		Interfaces: []yapi.InterfaceData{testInterface(0, 0, "get", "/new")},
+	})
+
+	plan := ComputePlan(local, remote, &ApplyOptions{Prune: true})
+	if plan.Creates != 2 || plan.Updates != 2 || plan.Deletes != 1 {
+		t.Fatalf("plan counts = %d/%d/%d, want 2/2/1", plan.Creates, plan.Updates, plan.Deletes)
+	}
+	if len(plan.Categories) != 1 || plan.Categories[0].Name != "new" {
+		t.Errorf("categories = %+v, want new", plan.Categories)
+	}
+
+	var summary []string
+	for _, c := range plan.Interfaces {
+		var fields []string
+		for _, f := range c.Changes {
+			fields = append(fields, string(f.Location)+":"+f.Name)
+		}
+		summary = append(summary, string(c.Action)+" "+c.Method+" "+c.Path+" "+strings.Join(fields, ","))
+	}
+	want := []string{
+		"update POST /users interface:title",
+		"update GET /users/{uid} interface:path,req_query:page",
+		"create GET /new ",
+		"delete GET /orders/:id ",
+	}
+	if !reflect.DeepEqual(summary, want) {
+		t.Errorf("plan =\n%s\nwant\n%s", strings.Join(summary, "\n"), strings.Join(want, "\n"))
+	}
+
+	if plan := ComputePlan(remote, testSnapshot(), nil); !plan.Empty() {
+		t.Errorf("identical snapshots produced a plan: %+v", plan)
+	}
+
+	var text bytes.Buffer
+	plan.WriteText(&text)
+	if !strings.Contains(text.String(), "Plan: 2 to create, 2 to update, 1 to delete.") {
+		t.Errorf("unexpected plan text:\n%s", text.String())
+	}
+}
+
+func TestComputePlan_IDsOfAnotherProject(t *testing.T) {
+	remote := testSnapshot()
+	local := testSnapshot()
+	local.Project.ID = remote.Project.ID + 100
+	local.Categories[0].Interfaces[1].Path = "/accounts/{id}"
+
+	plan := ComputePlan(local, remote, nil)
+	if plan.Creates != 1 || plan.Updates != 0 {
+		t.Fatalf("plan counts = %d/%d, want 1 create and no update", plan.Creates, plan.Updates)
+	}
+	if c := plan.Interfaces[0]; c.Action != ActionCreate || c.Path != "/accounts/{id}" || c.RemoteID != 0 {
+		t.Errorf("change = %+v, want a create of /accounts/{id}", c)
+	}
+
+	// within the project, an ID whose remote path is still used locally is not followed
+	local = testSnapshot()
+	moved := local.Categories[0].Interfaces[1]
+	moved.Path = "/accounts/{id}"
+	local.Categories[0].Interfaces = append(local.Categories[0].Interfaces, moved)
+	if plan := ComputePlan(local, remote, nil); plan.Creates != 1 || plan.Updates != 0 {
+		t.Errorf("plan counts = %d/%d, want 1 create and no update", plan.Creates, plan.Updates)
+	}
+}
+
+func TestPlan_Execute(t *testing.T) {
+	var calls []string
+	mux := http.NewServeMux()
+	record := func(reply string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			body, _ := ioutil.ReadAll(r.Body)
+			var params map[string]interface{}
+			json.Unmarshal(body, &params)
+			line := r.URL.Path
+			for _, key := range []string{"id", "catid", "path", "name"} {
+				if v, ok := params[key]; ok {
+					line += " " + key + "=" + strings.Trim(string(mustJSON(v)), `"`)
+				}
+			}
+			calls = append(calls, line)
+			w.Write([]byte(reply))
+		}
+	}
+	mux.HandleFunc("/api/interface/add_cat", record(`{"errcode":0,"errmsg":"成功！","data":{"_id":99,"name":"new"}}`))
+	mux.HandleFunc("/api/interface/save", record(`{"errcode":0,"errmsg":"成功！","data":[]}`))
+	mux.HandleFunc("/api/interface/up", record(`{"errcode":0,"errmsg":"成功！","data":{"n":1}}`))
+	mux.HandleFunc("/api/interface/del", record(`{"errcode":0,"errmsg":"成功！","data":{"n":1}}`))
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	remote := testSnapshot()
+	local := testSnapshot()
+	local.Categories[0].Interfaces[0].Title = "create user"
+	local.Categories[0].Interfaces[1].Path = "/users/{uid}"
+	local.Categories[1].Interfaces = nil
+	local.Categories = append(local.Categories, Category{
+		CatData:    yapi.CatData{Name: "new"},
+		Interfaces: []yapi.InterfaceData{testInterface(0, 0, "GET", "/new")},
+	})
+
+	c, _ := yapi.NewClient(server.URL, "token")
+	if err := ComputePlan(local, remote, &ApplyOptions{Prune: true}).Execute(c); err != nil {
+		t.Fatalf("Execute returned error: %v", err)
+	}
+	want := []string{
+		"/api/interface/add_cat name=new",
+		"/api/interface/save catid=2 path=/users",
+		"/api/interface/up id=21 catid=2 path=/users/{uid}",
+		"/api/interface/save catid=99 path=/new",
+		"/api/interface/del id=31",
+	}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("calls =\n%s\nwant\n%s", strings.Join(calls, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func mustJSON(v interface{}) []byte {
+	data, _ := json.Marshal(v)
+	return data
+}
@@ -0,0 +1,82 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/micrease/go-yapi/internal/jsonyaml"
+)
+
+// Format is the on-disk encoding of a snapshot.
+type Format string
+
+const (
+	FormatJSON Format = "json"
+	FormatYAML Format = "yaml"
+)
+
+// ext returns the file extension of the format.
+func (f Format) ext() string {
+	if f == FormatYAML {
+		return ".yaml"
+	}
+	return ".json"
+}
+
+// formatOf returns the format of a file from its extension.
+func formatOf(path string) (Format, bool) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return FormatJSON, true
+	case ".yaml", ".yml":
+		return FormatYAML, true
+	}
+	return "", false
+}
+
+// marshal encodes v in the format. YAML output keeps the field names and
+// order of the JSON encoding, so both formats describe the same document.
+func marshal(v interface{}, f Format) ([]byte, error) {
+	if f == FormatYAML {
+		return jsonyaml.Marshal(v)
+	}
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// unmarshal decodes data in the format into v through its JSON encoding.
+func unmarshal(data []byte, f Format, v interface{}) error {
+	if f == FormatYAML {
+		return jsonyaml.Unmarshal(data, v)
+	}
+	return json.Unmarshal(data, v)
+}
+
+func writeFile(path string, v interface{}, f Format) error {
+	data, err := marshal(v, f)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0644)
+}
+
+func readFile(path string, v interface{}) error {
+	f, ok := formatOf(path)
+	if !ok {
+		return fmt.Errorf("snapshot: unsupported file type %s", path)
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := unmarshal(data, f, v); err != nil {
+		return fmt.Errorf("snapshot: decode %s: %v", path, err)
+	}
+	return nil
+}
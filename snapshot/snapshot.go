@@ -0,0 +1,364 @@
+// Package snapshot dumps a whole YApi project — project metadata, category
+// tree and every interface — to a versioned directory layout and loads it
+// back into the typed go-yapi structures.
+//
+// A snapshot directory looks like this, with either .json or .yaml files:
+//
+//	snapshot.yaml                 manifest: layout version, format, category order
+//	project.yaml                  yapi.ProjectData
+//	<category>/category.yaml      yapi.CatData
+//	<category>/<method>-<path>.yaml  one yapi.InterfaceData per file
+package snapshot
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	yapi "github.com/micrease/go-yapi"
+)
+
+// Version is the layout version written to the manifest.
+const Version = 1
+
+const (
+	manifestName = "snapshot"
+	projectName  = "project"
+	categoryName = "category"
+)
+
+// Snapshot is the content of a project.
+type Snapshot struct {
+	Project    yapi.ProjectData
+	Categories []Category
+}
+
+// Category is a category with its interfaces in menu order.
+type Category struct {
+	yapi.CatData
+	Interfaces []yapi.InterfaceData
+}
+
+// Manifest describes the layout of a snapshot directory.
+type Manifest struct {
+	Version    int             `json:"version"`
+	Format     Format          `json:"format"`
+	ProjectID  int             `json:"project_id"`
+	Categories []ManifestEntry `json:"categories"`
+}
+
+// ManifestEntry lists the directory and interface files of a category in order.
+type ManifestEntry struct {
+	Dir        string   `json:"dir"`
+	Interfaces []string `json:"interfaces"`
+}
+
+// Interfaces returns every interface of the snapshot in category order.
+func (s *Snapshot) Interfaces() []yapi.InterfaceData {
+	var all []yapi.InterfaceData
+	for _, cat := range s.Categories {
+		all = append(all, cat.Interfaces...)
+	}
+	return all
+}
+
+// Dump fetches the project the client token belongs to, its category menu and
+// the full definition of every interface.
+func Dump(c *yapi.Client) (*Snapshot, error) {
+	project, err := c.Project.Get()
+	if err != nil {
+		return nil, err
+	}
+	if err := yapi.CheckErrCode(project.ErrCode, project.ErrMsg); err != nil {
+		return nil, err
+	}
+	catMenu, err := c.CatMenu.Get(project.Data.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := yapi.CheckErrCode(catMenu.ErrCode, catMenu.ErrMsg); err != nil {
+		return nil, err
+	}
+
+	all, err := c.Interface.GetAllInCats(catMenu.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Snapshot{Project: project.Data, Categories: make([]Category, len(catMenu.Data))}
+	byID := make(map[int]*Category, len(catMenu.Data))
+	for i, cat := range catMenu.Data {
+		s.Categories[i].CatData = cat
+		byID[cat.ID] = &s.Categories[i]
+	}
+	for _, d := range all {
+		if cat := byID[d.CatID]; cat != nil {
+			cat.Interfaces = append(cat.Interfaces, d)
+		}
+	}
+	return s, nil
+}
+
+// Write stores the snapshot in dir. The directories of a snapshot previously
+// written to dir are removed first, so deleted interfaces disappear.
+func (s *Snapshot) Write(dir string, format Format) error {
+	if format == "" {
+		format = FormatJSON
+	}
+	if format != FormatJSON && format != FormatYAML {
+		return fmt.Errorf("snapshot: unsupported format %q", format)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	if old, err := ReadManifest(dir); err == nil {
+		for _, entry := range old.Categories {
+			if err := checkDir(entry.Dir); err != nil {
+				return err
+			}
+		}
+		for _, entry := range old.Categories {
+			if err := os.RemoveAll(filepath.Join(dir, filepath.FromSlash(entry.Dir))); err != nil {
+				return err
+			}
+		}
+		for _, f := range []Format{FormatJSON, FormatYAML} {
+			os.Remove(filepath.Join(dir, manifestName+f.ext()))
+			os.Remove(filepath.Join(dir, projectName+f.ext()))
+		}
+	}
+
+	manifest := Manifest{Version: Version, Format: format, ProjectID: s.Project.ID}
+	usedDirs := make(map[string]bool)
+	for _, cat := range s.Categories {
+		entry := ManifestEntry{Dir: unique(slug(cat.Name, "cat-"+strconv.Itoa(cat.ID)), usedDirs), Interfaces: []string{}}
+		catDir := filepath.Join(dir, entry.Dir)
+		if err := os.MkdirAll(catDir, 0755); err != nil {
+			return err
+		}
+		if err := writeFile(filepath.Join(catDir, categoryName+format.ext()), cat.CatData, format); err != nil {
+			return err
+		}
+		usedFiles := map[string]bool{categoryName: true}
+		for _, d := range cat.Interfaces {
+			name := unique(InterfaceFileName(&d), usedFiles)
+			if err := writeFile(filepath.Join(catDir, name+format.ext()), d, format); err != nil {
+				return err
+			}
+			entry.Interfaces = append(entry.Interfaces, name+format.ext())
+		}
+		manifest.Categories = append(manifest.Categories, entry)
+	}
+
+	if err := writeFile(filepath.Join(dir, projectName+format.ext()), s.Project, format); err != nil {
+		return err
+	}
+	return writeFile(filepath.Join(dir, manifestName+format.ext()), manifest, format)
+}
+
+// ReadManifest reads the manifest of the snapshot in dir.
+func ReadManifest(dir string) (*Manifest, error) {
+	path, err := find(dir, manifestName)
+	if err != nil {
+		return nil, err
+	}
+	m := new(Manifest)
+	if err := readFile(path, m); err != nil {
+		return nil, err
+	}
+	if m.Version > Version {
+		return nil, fmt.Errorf("snapshot: layout version %d is newer than supported version %d", m.Version, Version)
+	}
+	return m, nil
+}
+
+// Load reads the snapshot stored in dir. Interface files that are not listed
+// in the manifest, e.g. added by hand, follow the listed ones in name order.
+func Load(dir string) (*Snapshot, error) {
+	manifest, err := ReadManifest(dir)
+	if err != nil {
+		return nil, err
+	}
+	s := new(Snapshot)
+	path, err := find(dir, projectName)
+	if err != nil {
+		return nil, err
+	}
+	if err := readFile(path, &s.Project); err != nil {
+		return nil, err
+	}
+
+	for _, entry := range manifest.Categories {
+		if err := checkDir(entry.Dir); err != nil {
+			return nil, err
+		}
+		catDir := filepath.Join(dir, filepath.FromSlash(entry.Dir))
+		cat, err := LoadCategory(catDir, entry.Interfaces)
+		if err != nil {
+			return nil, err
+		}
+		s.Categories = append(s.Categories, *cat)
+	}
+	return s, nil
+}
+
+// LoadCategory reads a category directory. order lists interface file names
+// that come first; the other interface files follow in name order.
+func LoadCategory(catDir string, order []string) (*Category, error) {
+	cat := new(Category)
+	path, err := find(catDir, categoryName)
+	if err != nil {
+		return nil, err
+	}
+	if err := readFile(path, &cat.CatData); err != nil {
+		return nil, err
+	}
+
+	files, err := ioutil.ReadDir(catDir)
+	if err != nil {
+		return nil, err
+	}
+	present := make(map[string]bool)
+	var rest []string
+	for _, f := range files {
+		name := f.Name()
+		if _, ok := formatOf(name); !ok || f.IsDir() || strings.TrimSuffix(name, filepath.Ext(name)) == categoryName {
+			continue
+		}
+		present[name] = true
+		rest = append(rest, name)
+	}
+	names := make([]string, 0, len(rest))
+	listed := make(map[string]bool)
+	for _, name := range order {
+		if present[name] && !listed[name] {
+			names = append(names, name)
+			listed[name] = true
+		}
+	}
+	sort.Strings(rest)
+	for _, name := range rest {
+		if !listed[name] {
+			names = append(names, name)
+		}
+	}
+
+	for _, name := range names {
+		var d yapi.InterfaceData
+		if err := readFile(filepath.Join(catDir, name), &d); err != nil {
+			return nil, err
+		}
+		cat.Interfaces = append(cat.Interfaces, d)
+	}
+	return cat, nil
+}
+
+// find returns the path of the file named base with a supported extension.
+func find(dir, base string) (string, error) {
+	for _, ext := range []string{".json", ".yaml", ".yml"} {
+		path := filepath.Join(dir, base+ext)
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("snapshot: no %s file in %s", base, dir)
+}
+
+var (
+	unsafeChars = regexp.MustCompile(`[^\p{L}\p{N}{}_-]+`)
+	dashes      = regexp.MustCompile(`-{2,}`)
+)
+
+// slug turns s into a file name, falling back to def when nothing is left.
+func slug(s, def string) string {
+	s = strings.Trim(unsafeChars.ReplaceAllString(s, "-"), "-")
+	s = dashes.ReplaceAllString(s, "-")
+	if s == "" {
+		return def
+	}
+	return s
+}
+
+// checkDir rejects a manifest category directory that is not a single name
+// inside the snapshot directory, so a hand-edited manifest cannot make Write
+// remove or Load read anything outside of it.
+func checkDir(name string) error {
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) || filepath.IsAbs(name) {
+		return fmt.Errorf("snapshot: invalid category directory %q in manifest", name)
+	}
+	return nil
+}
+
+// InterfaceFileName returns the base file name used for an interface, e.g. "get-api-users-{id}".
+func InterfaceFileName(d *yapi.InterfaceData) string {
+	return slug(strings.ToLower(d.Method)+"-"+d.Path, "interface-"+strconv.Itoa(d.ID))
+}
+
+// unique returns name, or name with a numeric suffix if it is already used.
+func unique(name string, used map[string]bool) string {
+	candidate := name
+	for i := 2; used[candidate]; i++ {
+		candidate = name + "-" + strconv.Itoa(i)
+	}
+	used[candidate] = true
+	return candidate
+}
+
+// Index adds the interfaces of the snapshot to ix.
+func (s *Snapshot) Index(ix *yapi.SearchIndex) {
+	cats := make([]yapi.CatData, len(s.Categories))
+	for i, cat := range s.Categories {
+		cats[i] = cat.CatData
+	}
+	ix.Add(s.Project, cats, s.Interfaces())
+}
+
+// Export converts the snapshot into the YApi "json" data export format, which
+// the YApi import accepts.
+func (s *Snapshot) Export() yapi.ExportData {
+	export := make(yapi.ExportData, 0, len(s.Categories))
+	for i, cat := range s.Categories {
+		list := cat.Interfaces
+		if list == nil {
+			list = []yapi.InterfaceData{}
+		}
+		export = append(export, yapi.ExportCat{Index: i, Name: cat.Name, Desc: cat.Desc, List: list})
+	}
+	return export
+}
+
+// FromExport converts a YApi "json" data export into a snapshot without
+// project metadata, for example to apply it with PlanSnapshot.
+func FromExport(e yapi.ExportData) *Snapshot {
+	s := &Snapshot{}
+	for _, cat := range e {
+		s.Categories = append(s.Categories, Category{CatData: yapi.CatData{Name: cat.Name, Desc: cat.Desc}, Interfaces: cat.List})
+	}
+	return s
+}
+
+// LoadInterfaces reads the interfaces of a snapshot directory or of a YApi
+// "json" export file.
+func LoadInterfaces(path string) ([]yapi.InterfaceData, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		s, err := Load(path)
+		if err != nil {
+			return nil, err
+		}
+		return s.Interfaces(), nil
+	}
+	export, err := yapi.ReadExportFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return export.Interfaces(), nil
+}
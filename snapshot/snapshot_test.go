@@ -0,0 +1,258 @@
+package snapshot
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	yapi "github.com/micrease/go-yapi"
+)
+
+func testInterface(id, catID int, method, path string) yapi.InterfaceData {
+	var d yapi.InterfaceData
+	d.ID, d.CatID, d.ProjectID = id, catID, 11
+	d.Method, d.Path, d.Title = method, path, fmt.Sprintf("api %d", id)
+	d.Tag = []string{"v1"}
+	d.ReqQuery = []yapi.ReqKVItemDetail{{ReqKVItemSimple: yapi.ReqKVItemSimple{Name: "page", Example: "1"}, Required: "1"}}
+	d.ResBodyType, d.ResBodyIsJsonSchema = "json", true
+	d.ResBody = "{\n  \"type\": \"object\",\n  \"properties\": {\n    \"id\": {\"type\": \"integer\"}\n  }\n}"
+	return d
+}
+
+func testSnapshot() *Snapshot {
+	return &Snapshot{
+		Project: yapi.ProjectData{ID: 11, Name: "demo", Basepath: "/api", Env: []yapi.ProjectEnv{{ID: "e1", Name: "local", Domain: "http://127.0.0.1"}}},
+		Categories: []Category{
+			{CatData: yapi.CatData{ID: 2, Name: "用户 / users", Desc: "users"}, Interfaces: []yapi.InterfaceData{
+				testInterface(22, 2, "POST", "/users"),
+				testInterface(21, 2, "GET", "/users/{id}"),
+			}},
+			{CatData: yapi.CatData{ID: 3, Name: "orders"}, Interfaces: []yapi.InterfaceData{
+				testInterface(31, 3, "GET", "/orders/:id"),
+			}},
+			{CatData: yapi.CatData{ID: 4, Name: "orders"}},
+		},
+	}
+}
+
+func TestSnapshot_WriteLoad(t *testing.T) {
+	for _, format := range []Format{FormatJSON, FormatYAML} {
+		dir, err := ioutil.TempDir("", "snapshot")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+
+		want := testSnapshot()
+		if err := want.Write(dir, format); err != nil {
+			t.Fatalf("%s: Write returned error: %v", format, err)
+		}
+		if _, err := os.Stat(filepath.Join(dir, "orders-2", "category"+format.ext())); err != nil {
+			t.Errorf("%s: expected a unique directory for the second orders category: %v", format, err)
+		}
+		got, err := Load(dir)
+		if err != nil {
+			t.Fatalf("%s: Load returned error: %v", format, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: loaded snapshot differs\ngot  %+v\nwant %+v", format, got, want)
+		}
+
+		// rewriting a smaller snapshot drops the files of removed categories
+		want.Categories = want.Categories[:1]
+		if err := want.Write(dir, format); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := os.Stat(filepath.Join(dir, "orders")); !os.IsNotExist(err) {
+			t.Errorf("%s: stale category directory was kept", format)
+		}
+	}
+}
+
+func TestSnapshot_WriteDottedNames(t *testing.T) {
+	dir, err := ioutil.TempDir("", "snapshot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	s := &Snapshot{Project: yapi.ProjectData{ID: 1, Name: "demo"}}
+	for i, name := range []string{"project.json", "snapshot.json", ".."} {
+		s.Categories = append(s.Categories, Category{CatData: yapi.CatData{ID: i + 1, Name: name}})
+	}
+	if err := s.Write(dir, FormatJSON); err != nil {
+		t.Fatal(err)
+	}
+	got, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Project.Name != "demo" || len(got.Categories) != 3 || got.Categories[0].Name != "project.json" {
+		t.Errorf("loaded snapshot = %+v", got)
+	}
+	m, _ := ReadManifest(dir)
+	for _, entry := range m.Categories {
+		if strings.Contains(entry.Dir, ".") {
+			t.Errorf("category directory %q contains a dot", entry.Dir)
+		}
+	}
+}
+
+func TestSnapshot_WriteBadManifest(t *testing.T) {
+	for _, bad := range []string{"", ".", "..", "../x", "/tmp"} {
+		root, err := ioutil.TempDir("", "snapshot")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(root)
+		dir := filepath.Join(root, "out")
+		if err := testSnapshot().Write(dir, FormatJSON); err != nil {
+			t.Fatal(err)
+		}
+		m, _ := ReadManifest(dir)
+		m.Categories[0].Dir = bad
+		if err := writeFile(filepath.Join(dir, "snapshot.json"), m, FormatJSON); err != nil {
+			t.Fatal(err)
+		}
+		if err := testSnapshot().Write(dir, FormatJSON); err == nil {
+			t.Errorf("Write accepted manifest directory %q", bad)
+		}
+		if _, err := Load(dir); err == nil {
+			t.Errorf("Load accepted manifest directory %q", bad)
+		}
+		if _, err := os.Stat(filepath.Join(dir, "project.json")); err != nil {
+			t.Errorf("manifest directory %q removed the snapshot: %v", bad, err)
+		}
+	}
+}
+
+func TestLoadCategory_UnlistedFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "snapshot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	testSnapshot().Write(dir, FormatYAML)
+
+	extra := []byte("method: DELETE\npath: /users/{id}\ntitle: remove user\n")
+	if err := ioutil.WriteFile(filepath.Join(dir, "用户-users", "added.yml"), extra, 0644); err != nil {
+		t.Fatal(err)
+	}
+	s, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	list := s.Categories[0].Interfaces
+	if len(list) != 3 || list[0].ID != 22 || list[2].Method != "DELETE" {
+		t.Errorf("unexpected interfaces: %+v", list)
+	}
+}
+
+func TestDump(t *testing.T) {
+	want := testSnapshot()
+	byID := map[int]yapi.InterfaceData{}
+	for _, d := range want.Interfaces() {
+		byID[d.ID] = d
+	}
+	reply := func(w http.ResponseWriter, data interface{}) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"errcode": 0, "errmsg": "成功！", "data": data})
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/project/get", func(w http.ResponseWriter, r *http.Request) { reply(w, want.Project) })
+	mux.HandleFunc("/api/interface/getCatMenu", func(w http.ResponseWriter, r *http.Request) {
+		var menu yapi.CatMenuData
+		for _, c := range want.Categories {
+			menu = append(menu, c.CatData)
+		}
+		reply(w, menu)
+	})
+	mux.HandleFunc("/api/interface/list_cat", func(w http.ResponseWriter, r *http.Request) {
+		catID, _ := strconv.Atoi(r.URL.Query().Get("catid"))
+		var list []yapi.InterfaceData
+		for _, c := range want.Categories {
+			if c.ID == catID {
+				list = c.Interfaces
+			}
+		}
+		reply(w, yapi.InterfaceListData{Count: len(list), Total: 1, List: list})
+	})
+	mux.HandleFunc("/api/interface/get", func(w http.ResponseWriter, r *http.Request) {
+		id, _ := strconv.Atoi(r.URL.Query().Get("id"))
+		reply(w, byID[id])
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	c, _ := yapi.NewClient(server.URL, "token")
+	got, err := Dump(c)
+	if err != nil {
+		t.Fatalf("Dump returned error: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Dump mismatch\ngot  %+v\nwant %+v", got, want)
+	}
+}
+
+func TestSnapshot_Export(t *testing.T) {
+	s := testSnapshot()
+	data, err := json.Marshal(s.Export())
+	if err != nil {
+		t.Fatal(err)
+	}
+	export, err := yapi.ReadExport(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(export) != 3 || export[1].Index != 1 || export[1].Name != "orders" || export[2].List == nil {
+		t.Errorf("export categories = %+v", export)
+	}
+	if !reflect.DeepEqual(export.Interfaces(), s.Interfaces()) {
+		t.Errorf("export interfaces differ from the snapshot")
+	}
+}
+
+func TestFromExport(t *testing.T) {
+	s := testSnapshot()
+	got := FromExport(s.Export())
+	if len(got.Categories) != len(s.Categories) || got.Categories[1].Name != "orders" || got.Categories[1].ID != 0 {
+		t.Errorf("categories = %+v", got.Categories)
+	}
+	if !reflect.DeepEqual(got.Interfaces(), s.Interfaces()) {
+		t.Errorf("interfaces differ from the export")
+	}
+}
+
+func TestLoadInterfaces(t *testing.T) {
+	dir, err := ioutil.TempDir("", "snapshot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	s := testSnapshot()
+	if err := s.Write(filepath.Join(dir, "snap"), FormatYAML); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := json.Marshal(s.Export())
+	if err := ioutil.WriteFile(filepath.Join(dir, "export.json"), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{"snap", "export.json"} {
+		got, err := LoadInterfaces(filepath.Join(dir, path))
+		if err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		if !reflect.DeepEqual(got, s.Interfaces()) {
+			t.Errorf("%s: interfaces = %+v", path, got)
+		}
+	}
+}
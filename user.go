@@ -0,0 +1,396 @@
+package yapi
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Project member roles.
+const (
+	RoleOwner = "owner"
+	RoleDev   = "dev"
+	RoleGuest = "guest"
+)
+
+// UserService manages user accounts and project members. Unlike the project
+// token APIs, these need a user session, see AuthenticationService.Login;
+// listing users and managing members of other projects need an admin.
+type UserService struct {
+	client *Client
+}
+
+type UserData struct {
+	ID       int    `json:"_id" structs:"_id"`
+	Username string `json:"username" structs:"username"`
+	Email    string `json:"email" structs:"email"`
+	Role     string `json:"role" structs:"role"`
+	Type     string `json:"type" structs:"type"`
+	AddTime  int    `json:"add_time" structs:"add_time"`
+	UpTime   int    `json:"up_time" structs:"up_time"`
+}
+
+type UserResp struct {
+	CommonResp
+	Data   UserData `json:"data" structs:"data"`
+	string string
+}
+
+func (u *UserResp) ToString() string {
+	return u.string
+}
+
+// UserSearchItem is a user found by Search, whose fields are named
+// differently from UserData.
+type UserSearchItem struct {
+	UID      int    `json:"uid" structs:"uid"`
+	Username string `json:"username" structs:"username"`
+	Email    string `json:"email" structs:"email"`
+	Role     string `json:"role" structs:"role"`
+	AddTime  int    `json:"addTime" structs:"addTime"`
+	UpTime   int    `json:"upTime" structs:"upTime"`
+}
+
+type UserSearchResp struct {
+	CommonResp
+	Data   []UserSearchItem `json:"data" structs:"data"`
+	string string
+}
+
+func (u *UserSearchResp) ToString() string {
+	return u.string
+}
+
+type UserListData struct {
+	Count int        `json:"count" structs:"count"`
+	Total int        `json:"total" structs:"total"`
+	List  []UserData `json:"list" structs:"list"`
+}
+
+type UserList struct {
+	CommonResp
+	Data   UserListData `json:"data" structs:"data"`
+	string string
+}
+
+func (u *UserList) ToString() string {
+	return u.string
+}
+
+type UserSearchParam struct {
+	Token string `url:"token,omitempty"`
+	Q     string `url:"q"`
+}
+
+type UserListParam struct {
+	Token string `url:"token,omitempty"`
+	Page  int    `url:"page"`
+	Limit int    `url:"limit"`
+}
+
+type LoginReq struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// MemberData is a member of a project.
+type MemberData struct {
+	UID         int    `json:"uid" structs:"uid"`
+	Username    string `json:"username" structs:"username"`
+	Email       string `json:"email" structs:"email"`
+	Role        string `json:"role" structs:"role"`
+	EmailNotice bool   `json:"email_notice" structs:"email_notice"`
+}
+
+type MemberList struct {
+	CommonResp
+	Data   []MemberData `json:"data" structs:"data"`
+	string string
+}
+
+func (m *MemberList) ToString() string {
+	return m.string
+}
+
+// AddMemberResult reports which of the users given to AddMembers were added,
+// already members, or not found.
+type AddMemberResult struct {
+	AddMembers   []MemberData `json:"add_members" structs:"add_members"`
+	ExistMembers []MemberData `json:"exist_members" structs:"exist_members"`
+	NoMembers    []int        `json:"no_members" structs:"no_members"`
+}
+
+type AddMemberResp struct {
+	CommonResp
+	Data   AddMemberResult `json:"data" structs:"data"`
+	string string
+}
+
+func (a *AddMemberResp) ToString() string {
+	return a.string
+}
+
+type MemberListParam struct {
+	Token string `url:"token,omitempty"`
+	ID    int    `url:"id"`
+}
+
+type AddMemberReq struct {
+	Token      string `json:"token,omitempty"`
+	ID         int    `json:"id"`
+	MemberUIDs []int  `json:"member_uids"`
+	Role       string `json:"role"`
+}
+
+type MemberReq struct {
+	Token     string `json:"token,omitempty"`
+	ID        int    `json:"id"`
+	MemberUID int    `json:"member_uid"`
+	Role      string `json:"role,omitempty"`
+}
+
+// Login signs in with api/user/login and keeps the session cookies, which
+// are then sent with every request of the client.
+func (s *AuthenticationService) Login(email, password string) (*UserResp, error) {
+	req, err := s.client.NewRequest("POST", "api/user/login", LoginReq{Email: email, Password: password})
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.client.doer().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if err := CheckResponse(resp); err != nil {
+		return nil, err
+	}
+	content, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	result := UserResp{}
+	result.string = string(content)
+	if err := json.Unmarshal(content, &result); err != nil {
+		return &result, err
+	}
+	if result.ErrCode == 0 {
+		s.cookies = resp.Cookies()
+	}
+	return &result, nil
+}
+
+// Status returns the user of the session.
+func (s *UserService) Status() (*UserResp, error) {
+	apiEndpoint := "api/user/status"
+	resp, err := s.client.Get(apiEndpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	result := UserResp{}
+	result.string = resp
+	err = json.Unmarshal([]byte(resp), &result)
+	return &result, err
+}
+
+// Search finds the users whose name or email contains q.
+func (s *UserService) Search(q string) (*UserSearchResp, error) {
+	apiEndpoint := "api/user/search"
+	url, err := addOptions(apiEndpoint, &UserSearchParam{Token: s.client.Authentication.token, Q: q})
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.client.Get(url, nil)
+	if err != nil {
+		return nil, err
+	}
+	result := UserSearchResp{}
+	result.string = resp
+	err = json.Unmarshal([]byte(resp), &result)
+	return &result, err
+}
+
+// List returns a page of the users of the instance, which needs an admin.
+func (s *UserService) List(opt *UserListParam) (*UserList, error) {
+	apiEndpoint := "api/user/list"
+	opt.Token = s.client.Authentication.token
+	url, err := addOptions(apiEndpoint, opt)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.client.Get(url, nil)
+	if err != nil {
+		return nil, err
+	}
+	result := UserList{}
+	result.string = resp
+	err = json.Unmarshal([]byte(resp), &result)
+	return &result, err
+}
+
+// ListAll pages through List and returns every user.
+func (s *UserService) ListAll() ([]UserData, error) {
+	var all []UserData
+	for page := 1; ; page++ {
+		list, err := s.List(&UserListParam{Page: page, Limit: 100})
+		if err != nil {
+			return nil, err
+		}
+		if err := CheckErrCode(list.ErrCode, list.ErrMsg); err != nil {
+			return nil, err
+		}
+		all = append(all, list.Data.List...)
+		// like list_cat, total is the number of pages
+		if page >= list.Data.Total || len(list.Data.List) == 0 {
+			return all, nil
+		}
+	}
+}
+
+// Members returns the members of a project.
+func (s *UserService) Members(projectID int) (*MemberList, error) {
+	apiEndpoint := "api/project/get_member_list"
+	url, err := addOptions(apiEndpoint, &MemberListParam{Token: s.client.Authentication.token, ID: projectID})
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.client.Get(url, nil)
+	if err != nil {
+		return nil, err
+	}
+	result := MemberList{}
+	result.string = resp
+	err = json.Unmarshal([]byte(resp), &result)
+	return &result, err
+}
+
+// AddMembers adds users to a project with role.
+func (s *UserService) AddMembers(projectID int, uids []int, role string) (*AddMemberResp, error) {
+	apiEndpoint := "api/project/add_member"
+	addMemberReq := AddMemberReq{}
+	addMemberReq.Token = s.client.Authentication.token
+	addMemberReq.ID = projectID
+	addMemberReq.MemberUIDs = uids
+	addMemberReq.Role = role
+	resp, err := s.client.Post(apiEndpoint, addMemberReq)
+	if err != nil {
+		return nil, err
+	}
+	result := AddMemberResp{}
+	result.string = resp
+	err = json.Unmarshal([]byte(resp), &result)
+	return &result, err
+}
+
+// DeleteMember removes a user from a project.
+func (s *UserService) DeleteMember(projectID, uid int) (*ModifyResp, error) {
+	return s.postMember("api/project/del_member", projectID, uid, "")
+}
+
+// ChangeMemberRole changes the role of a member of a project.
+func (s *UserService) ChangeMemberRole(projectID, uid int, role string) (*ModifyResp, error) {
+	return s.postMember("api/project/change_member_role", projectID, uid, role)
+}
+
+func (s *UserService) postMember(apiEndpoint string, projectID, uid int, role string) (*ModifyResp, error) {
+	memberReq := MemberReq{}
+	memberReq.Token = s.client.Authentication.token
+	memberReq.ID = projectID
+	memberReq.MemberUID = uid
+	memberReq.Role = role
+	resp, err := s.client.Post(apiEndpoint, memberReq)
+	if err != nil {
+		return nil, err
+	}
+	result := ModifyResp{}
+	result.string = resp
+	err = json.Unmarshal([]byte(resp), &result)
+	return &result, err
+}
+
+// Find returns the user whose email or name is exactly user, ignoring case,
+// or nil if there is none.
+func (s *UserService) Find(user string) (*UserSearchItem, error) {
+	found, err := s.Search(user)
+	if err != nil {
+		return nil, err
+	}
+	if err := CheckErrCode(found.ErrCode, found.ErrMsg); err != nil {
+		return nil, err
+	}
+	for i, u := range found.Data {
+		if strings.EqualFold(u.Email, user) || strings.EqualFold(u.Username, user) {
+			return &found.Data[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// GrantResult lists what Grant did for each user, given by email or name.
+type GrantResult struct {
+	Added     []string `json:"added,omitempty"`
+	Changed   []string `json:"changed,omitempty"`
+	Unchanged []string `json:"unchanged,omitempty"`
+	NotFound  []string `json:"not_found,omitempty"`
+}
+
+// Grant gives the users, found by email or name with Find, the role in a
+// project: non-members are added and members with another role changed.
+// Users that do not exist are listed in NotFound.
+func (s *UserService) Grant(projectID int, role string, users ...string) (*GrantResult, error) {
+	members, err := s.Members(projectID)
+	if err != nil {
+		return nil, err
+	}
+	if err := CheckErrCode(members.ErrCode, members.ErrMsg); err != nil {
+		return nil, err
+	}
+	roles := map[int]string{}
+	for _, m := range members.Data {
+		roles[m.UID] = m.Role
+	}
+	res := &GrantResult{}
+	var add []int
+	var added []string
+	for _, name := range users {
+		u, err := s.Find(name)
+		if err != nil {
+			return res, errors.Wrapf(err, "find %s", name)
+		}
+		if u == nil {
+			res.NotFound = append(res.NotFound, name)
+			continue
+		}
+		current, isMember := roles[u.UID]
+		switch {
+		case !isMember:
+			add = append(add, u.UID)
+			added = append(added, name)
+			roles[u.UID] = role
+		case current == role:
+			res.Unchanged = append(res.Unchanged, name)
+		default:
+			resp, err := s.ChangeMemberRole(projectID, u.UID, role)
+			if err == nil {
+				err = CheckErrCode(resp.ErrCode, resp.ErrMsg)
+			}
+			if err != nil {
+				return res, errors.Wrapf(err, "change role of %s", name)
+			}
+			res.Changed = append(res.Changed, name)
+		}
+	}
+	if len(add) > 0 {
+		resp, err := s.AddMembers(projectID, add, role)
+		if err == nil {
+			err = CheckErrCode(resp.ErrCode, resp.ErrMsg)
+		}
+		if err != nil {
+			return res, errors.Wrap(err, "add members")
+		}
+		res.Added = added
+	}
+	return res, nil
+}
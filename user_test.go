@@ -0,0 +1,124 @@
+package yapi
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestUserService(t *testing.T) {
+	var requests []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/user/login", func(w http.ResponseWriter, r *http.Request) {
+		var req LoginReq
+		body, _ := ioutil.ReadAll(r.Body)
+		json.Unmarshal(body, &req)
+		if req.Password != "secret" {
+			w.Write([]byte(`{"errcode": 405, "errmsg": "密码错误"}`))
+			return
+		}
+		http.SetCookie(w, &http.Cookie{Name: "_yapi_token", Value: "session"})
+		http.SetCookie(w, &http.Cookie{Name: "_yapi_uid", Value: "1"})
+		w.Write([]byte(`{"errcode": 0, "data": {"uid": 1, "username": "admin", "role": "admin"}}`))
+	})
+	mux.HandleFunc("/api/user/status", func(w http.ResponseWriter, r *http.Request) {
+		if c, err := r.Cookie("_yapi_token"); err != nil || c.Value != "session" {
+			w.Write([]byte(`{"errcode": 40011, "errmsg": "请登录..."}`))
+			return
+		}
+		w.Write([]byte(`{"errcode": 0, "data": {"_id": 1, "username": "admin", "email": "admin@example.com", "role": "admin"}}`))
+	})
+	mux.HandleFunc("/api/user/search", func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("q") {
+		case "bob@example.com":
+			w.Write([]byte(`{"errcode": 0, "data": [{"uid": 3, "username": "bob", "email": "bob@example.com"}]}`))
+		case "carol":
+			w.Write([]byte(`{"errcode": 0, "data": [{"uid": 5, "username": "carolyn"}, {"uid": 4, "username": "Carol"}]}`))
+		case "dave":
+			w.Write([]byte(`{"errcode": 0, "data": [{"uid": 6, "username": "dave"}]}`))
+		default:
+			w.Write([]byte(`{"errcode": 0, "data": []}`))
+		}
+	})
+	mux.HandleFunc("/api/user/list", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "1" {
+			w.Write([]byte(`{"errcode": 0, "data": {"count": 3, "total": 2, "list": [{"_id": 1}, {"_id": 3}]}}`))
+			return
+		}
+		w.Write([]byte(`{"errcode": 0, "data": {"count": 3, "total": 2, "list": [{"_id": 4}]}}`))
+	})
+	mux.HandleFunc("/api/project/get_member_list", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("id") != "11" {
+			t.Errorf("member list query = %s", r.URL.RawQuery)
+		}
+		w.Write([]byte(`{"errcode": 0, "data": [{"uid": 1, "role": "owner"}, {"uid": 4, "role": "guest"}, {"uid": 6, "role": "dev"}]}`))
+	})
+	member := func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		requests = append(requests, r.URL.Path+" "+string(body))
+		w.Write([]byte(`{"errcode": 0, "data": {}}`))
+	}
+	mux.HandleFunc("/api/project/add_member", member)
+	mux.HandleFunc("/api/project/change_member_role", member)
+	mux.HandleFunc("/api/project/del_member", member)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	client, err := NewClient(server.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp, err := client.Authentication.Login("admin@example.com", "wrong"); err != nil || resp.ErrCode != 405 {
+		t.Fatalf("login with a wrong password = %+v, %v", resp, err)
+	}
+	if resp, err := client.User.Status(); err != nil || resp.ErrCode != 40011 {
+		t.Fatalf("status without session = %+v, %v", resp, err)
+	}
+	if _, err := client.Authentication.Login("admin@example.com", "secret"); err != nil {
+		t.Fatal(err)
+	}
+	status, err := client.User.Status()
+	if err != nil || status.ErrCode != 0 || status.Data.Email != "admin@example.com" {
+		t.Fatalf("status = %+v, %v", status, err)
+	}
+
+	users, err := client.User.ListAll()
+	if err != nil || len(users) != 3 || users[2].ID != 4 {
+		t.Errorf("users = %+v, %v", users, err)
+	}
+	u, err := client.User.Find("carol")
+	if err != nil || u == nil || u.UID != 4 {
+		t.Errorf("find carol = %+v, %v", u, err)
+	}
+
+	res, err := client.User.Grant(11, RoleDev, "bob@example.com", "carol", "dave", "nobody")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &GrantResult{
+		Added:     []string{"bob@example.com"},
+		Changed:   []string{"carol"},
+		Unchanged: []string{"dave"},
+		NotFound:  []string{"nobody"},
+	}
+	if !reflect.DeepEqual(res, want) {
+		t.Errorf("grant = %+v, want %+v", res, want)
+	}
+	if _, err := client.User.DeleteMember(11, 6); err != nil {
+		t.Fatal(err)
+	}
+	wantRequests := []string{
+		`/api/project/change_member_role {"id":11,"member_uid":4,"role":"dev"}`,
+		`/api/project/add_member {"id":11,"member_uids":[3],"role":"dev"}`,
+		`/api/project/del_member {"id":11,"member_uid":6}`,
+	}
+	for i := range requests {
+		requests[i] = requests[i][:len(requests[i])-1]
+	}
+	if !reflect.DeepEqual(requests, wantRequests) {
+		t.Errorf("requests = %q, want %q", requests, wantRequests)
+	}
+}
@@ -0,0 +1,298 @@
+// Package validation provides an http middleware that rejects requests which do
+// not conform to their YApi interface definition: missing required headers,
+// query params, path params or form fields, and JSON bodies that do not match
+// the documented request body schema.
+package validation
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	yapi "github.com/micrease/go-yapi"
+	"github.com/micrease/go-yapi/internal/route"
+)
+
+// DefaultMaxBodyBytes is the body size limit used when Options.MaxBodyBytes is zero.
+const DefaultMaxBodyBytes = 10 << 20
+
+// errBodyTooLarge is returned by readBody for bodies over the size limit.
+var errBodyTooLarge = errors.New("body too large")
+
+// Options configures a Validator.
+type Options struct {
+	// ReportOnly passes invalid requests through to the next handler after
+	// calling OnViolation, for a gradual rollout.
+	ReportOnly bool
+
+	// RejectUnknown rejects requests that match no interface. By default they are passed through.
+	RejectUnknown bool
+
+	// Prefix is stripped from request paths before matching, e.g. the project basepath.
+	// Requests whose path is not Prefix or below it are passed through unvalidated.
+	Prefix string
+
+	// OnViolation is called for every invalid request. Defaults to log.Printf.
+	OnViolation func(r *http.Request, v *Violation)
+
+	// MaxBodyBytes limits the size of the request bodies read for validation.
+	// Larger requests are rejected with 413. Defaults to DefaultMaxBodyBytes,
+	// a negative value disables the limit.
+	MaxBodyBytes int64
+}
+
+// Violation describes an invalid request. The pointer of each error is rooted
+// at the part of the request it refers to: /header, /query, /params, /form or /body.
+type Violation struct {
+	InterfaceID int                    `json:"interface_id,omitempty"`
+	Method      string                 `json:"method"`
+	Path        string                 `json:"path"`
+	Errors      []yapi.ValidationError `json:"errors"`
+
+	// status is the http status of the rejection, 400 when unset.
+	status int
+}
+
+// Validator checks incoming requests against interface definitions.
+type Validator struct {
+	interfaces []yapi.InterfaceData
+	schemas    []*yapi.Schema
+	table      route.Table
+	opts       Options
+}
+
+// New returns a Validator for interfaces. opts may be nil.
+func New(interfaces []yapi.InterfaceData, opts *Options) *Validator {
+	v := &Validator{
+		interfaces: interfaces,
+		schemas:    make([]*yapi.Schema, len(interfaces)),
+	}
+	if opts != nil {
+		v.opts = *opts
+	}
+	v.opts.Prefix = strings.TrimRight(v.opts.Prefix, "/")
+	for i, d := range interfaces {
+		v.table.Add(d.Method, d.Path, i)
+		if d.ReqBodyType == "json" && d.ReqBodyIsJsonSchema {
+			// an unparsable documented schema disables body validation for the interface
+			v.schemas[i], _ = d.ReqBodySchema()
+		}
+	}
+	return v
+}
+
+// Middleware returns a middleware validating requests against interfaces.
+func Middleware(interfaces []yapi.InterfaceData, opts *Options) func(http.Handler) http.Handler {
+	return New(interfaces, opts).Handler
+}
+
+// Handler wraps next with request validation.
+func (v *Validator) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		violation := v.Validate(r)
+		if violation == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if v.opts.OnViolation != nil {
+			v.opts.OnViolation(r, violation)
+		} else {
+			log.Printf("yapi validation: %s %s: %v", violation.Method, violation.Path, violation.Errors)
+		}
+		if v.opts.ReportOnly {
+			next.ServeHTTP(w, r)
+			return
+		}
+		status, msg := http.StatusBadRequest, "request does not match the api definition"
+		if violation.status == http.StatusRequestEntityTooLarge {
+			status, msg = violation.status, "request body is too large"
+		}
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(struct {
+			yapi.CommonResp
+			Errors []yapi.ValidationError `json:"errors"`
+		}{yapi.CommonResp{ErrCode: status, ErrMsg: msg}, violation.Errors})
+	})
+}
+
+// Validate checks r and returns nil when it conforms to its interface. The
+// request body is read and replaced, so r can still be served afterwards.
+func (v *Validator) Validate(r *http.Request) *Violation {
+	path := r.URL.Path
+	if prefix := v.opts.Prefix; prefix != "" {
+		if !strings.HasPrefix(path, prefix) || (len(path) > len(prefix) && path[len(prefix)] != '/') {
+			return nil
+		}
+		if path = strings.TrimPrefix(path, prefix); path == "" {
+			path = "/"
+		}
+	}
+	index, params, _ := v.table.Match(r.Method, path)
+	if index < 0 {
+		if !v.opts.RejectUnknown {
+			return nil
+		}
+		return &Violation{Method: r.Method, Path: r.URL.Path, Errors: []yapi.ValidationError{{Message: "no api is defined for this method and path"}}}
+	}
+	d := &v.interfaces[index]
+
+	var errs []yapi.ValidationError
+	fail := func(pointer, msg string) {
+		errs = append(errs, yapi.ValidationError{Pointer: pointer, Message: msg})
+	}
+
+	for _, h := range d.ReqHeaders {
+		if h.Required == "1" && r.Header.Get(h.Name) == "" {
+			fail("/header/"+h.Name, "required header is missing")
+		}
+	}
+	query := r.URL.Query()
+	for _, q := range d.ReqQuery {
+		values, ok := query[q.Name]
+		if !ok || len(values) == 0 {
+			if q.Required == "1" {
+				fail("/query/"+q.Name, "required query param is missing")
+			}
+			continue
+		}
+		if msg := checkType(q.Type, values[0]); msg != "" {
+			fail("/query/"+q.Name, msg)
+		}
+	}
+	for _, p := range d.ReqParams {
+		if params[p.Name] == "" {
+			fail("/params/"+p.Name, "path param is missing")
+		}
+	}
+
+	body, err := v.readBody(r)
+	if err == errBodyTooLarge {
+		fail("/body", fmt.Sprintf("body is larger than %d bytes", v.maxBodyBytes()))
+		return &Violation{InterfaceID: d.ID, Method: r.Method, Path: r.URL.Path, Errors: errs, status: http.StatusRequestEntityTooLarge}
+	}
+	if err != nil {
+		fail("/body", "cannot read body: "+err.Error())
+	}
+	switch d.ReqBodyType {
+	case "form":
+		errs = append(errs, v.validateForm(r, d, body)...)
+	case "json":
+		if schema := v.schemas[index]; schema != nil {
+			var value interface{}
+			if err := json.Unmarshal(body, &value); err != nil {
+				fail("/body", "body is not valid json")
+				break
+			}
+			for _, e := range schema.Validate(value) {
+				fail("/body"+e.Pointer, e.Message)
+			}
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return &Violation{InterfaceID: d.ID, Method: r.Method, Path: r.URL.Path, Errors: errs}
+}
+
+func (v *Validator) validateForm(r *http.Request, d *yapi.InterfaceData, body []byte) []yapi.ValidationError {
+	var errs []yapi.ValidationError
+	form, files := formValues(r, body)
+	for _, f := range d.ReqBodyForm {
+		if f.Required != "1" {
+			continue
+		}
+		if f.Type == "file" {
+			if files[f.Name] == 0 {
+				errs = append(errs, yapi.ValidationError{Pointer: "/form/" + f.Name, Message: "required file is missing"})
+			}
+			continue
+		}
+		if _, ok := form[f.Name]; !ok {
+			errs = append(errs, yapi.ValidationError{Pointer: "/form/" + f.Name, Message: "required form field is missing"})
+		}
+	}
+	return errs
+}
+
+// formValues parses the body of a url-encoded or multipart request without
+// consuming it, so that the next handler can parse it again.
+func formValues(r *http.Request, body []byte) (map[string][]string, map[string]int) {
+	clone := r.Clone(r.Context())
+	clone.Body = ioutil.NopCloser(bytes.NewReader(body))
+	clone.Form, clone.PostForm, clone.MultipartForm = nil, nil, nil
+
+	files := make(map[string]int)
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "multipart/form-data" {
+		if err := clone.ParseMultipartForm(32 << 20); err != nil {
+			return nil, files
+		}
+		for name, fh := range clone.MultipartForm.File {
+			files[name] = len(fh)
+		}
+		return clone.MultipartForm.Value, files
+	}
+	clone.ParseForm()
+	return clone.PostForm, files
+}
+
+func (v *Validator) maxBodyBytes() int64 {
+	if v.opts.MaxBodyBytes == 0 {
+		return DefaultMaxBodyBytes
+	}
+	return v.opts.MaxBodyBytes
+}
+
+// readBody returns the request body and restores it for later readers. At
+// most the size limit plus one byte is read; errBodyTooLarge is returned
+// beyond the limit and the unread rest stays available to later readers.
+func (v *Validator) readBody(r *http.Request) ([]byte, error) {
+	if r.Body == nil {
+		return nil, nil
+	}
+	limit := v.maxBodyBytes()
+	reader := io.Reader(r.Body)
+	if limit > 0 {
+		reader = io.LimitReader(r.Body, limit+1)
+	}
+	body, err := ioutil.ReadAll(reader)
+	if err == nil && limit > 0 && int64(len(body)) > limit {
+		r.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), r.Body), r.Body}
+		return nil, errBodyTooLarge
+	}
+	r.Body.Close()
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return body, err
+}
+
+// checkType validates a query param value against its documented type.
+func checkType(typ, value string) string {
+	switch typ {
+	case "integer":
+		if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+			return "expected an integer"
+		}
+	case "number":
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return "expected a number"
+		}
+	case "boolean":
+		if _, err := strconv.ParseBool(value); err != nil {
+			return "expected a boolean"
+		}
+	}
+	return ""
+}
@@ -0,0 +1,160 @@
+package validation
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	yapi "github.com/micrease/go-yapi"
+)
+
+func detail(name, required, typ string) yapi.ReqKVItemDetail {
+	return yapi.ReqKVItemDetail{ReqKVItemSimple: yapi.ReqKVItemSimple{Name: name}, Required: required, Type: typ}
+}
+
+func testInterfaces() []yapi.InterfaceData {
+	var create, search, login yapi.InterfaceData
+	create.ID, create.Method, create.Path = 1, "POST", "/orders/:shop"
+	create.ReqParams = []yapi.ReqKVItemSimple{{Name: "shop"}}
+	create.ReqHeaders = []yapi.ReqKVItemDetail{detail("X-Token", "1", "")}
+	create.ReqBodyType, create.ReqBodyIsJsonSchema = "json", true
+	create.ReqBodyOther = `{"type":"object","properties":{"qty":{"type":"integer","minimum":1}},"required":["qty"]}`
+
+	search.ID, search.Method, search.Path = 2, "GET", "/orders"
+	search.ReqQuery = []yapi.ReqKVItemDetail{detail("page", "1", "integer"), detail("q", "0", "")}
+
+	login.ID, login.Method, login.Path = 3, "POST", "/login"
+	login.ReqBodyType = "form"
+	login.ReqBodyForm = []yapi.ReqKVItemDetail{detail("user", "1", "text"), detail("remember", "0", "text")}
+	return []yapi.InterfaceData{create, search, login}
+}
+
+func okHandler(body *string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := ioutil.ReadAll(r.Body)
+		*body = string(data)
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+func TestMiddleware_Reject(t *testing.T) {
+	var violations []*Violation
+	var seen string
+	handler := Middleware(testInterfaces(), &Options{
+		Prefix:      "/api",
+		OnViolation: func(r *http.Request, v *Violation) { violations = append(violations, v) },
+	})(okHandler(&seen))
+
+	tests := []struct {
+		method, target, body string
+		header               map[string]string
+		status               int
+		pointers             []string
+	}{
+		{"POST", "/api/orders/s1", `{"qty":2}`, map[string]string{"X-Token": "t"}, http.StatusNoContent, nil},
+		{"POST", "/api/orders/s1", `{"qty":0.5}`, nil, http.StatusBadRequest, []string{"/header/X-Token", "/body/qty"}},
+		{"POST", "/api/orders/s1", `not json`, map[string]string{"X-Token": "t"}, http.StatusBadRequest, []string{"/body"}},
+		{"GET", "/api/orders?page=2", "", nil, http.StatusNoContent, nil},
+		{"GET", "/api/orders?page=x", "", nil, http.StatusBadRequest, []string{"/query/page"}},
+		{"GET", "/api/orders?q=1", "", nil, http.StatusBadRequest, []string{"/query/page"}},
+		{"POST", "/api/login", url.Values{"user": {"bob"}}.Encode(), map[string]string{"Content-Type": "application/x-www-form-urlencoded"}, http.StatusNoContent, nil},
+		{"POST", "/api/login", "remember=1", map[string]string{"Content-Type": "application/x-www-form-urlencoded"}, http.StatusBadRequest, []string{"/form/user"}},
+		{"GET", "/api/unknown", "", nil, http.StatusNoContent, nil},
+	}
+	for _, tt := range tests {
+		violations = nil
+		req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+		for k, v := range tt.header {
+			req.Header.Set(k, v)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		if w.Code != tt.status {
+			t.Errorf("%s %s: status = %d, want %d (%s)", tt.method, tt.target, w.Code, tt.status, w.Body.String())
+			continue
+		}
+		if tt.status == http.StatusNoContent {
+			if seen != tt.body {
+				t.Errorf("%s %s: next handler got body %q, want %q", tt.method, tt.target, seen, tt.body)
+			}
+			continue
+		}
+		var resp struct {
+			ErrCode int                    `json:"errcode"`
+			Errors  []yapi.ValidationError `json:"errors"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		if len(resp.Errors) != len(tt.pointers) || len(violations) != 1 {
+			t.Errorf("%s %s: errors = %+v, want %v", tt.method, tt.target, resp.Errors, tt.pointers)
+			continue
+		}
+		for i, p := range tt.pointers {
+			if resp.Errors[i].Pointer != p {
+				t.Errorf("%s %s: error %d at %s, want %s", tt.method, tt.target, i, resp.Errors[i].Pointer, p)
+			}
+		}
+	}
+}
+
+func TestMiddleware_ReportOnly(t *testing.T) {
+	var reported *Violation
+	var seen string
+	handler := Middleware(testInterfaces(), &Options{
+		ReportOnly:  true,
+		OnViolation: func(r *http.Request, v *Violation) { reported = v },
+	})(okHandler(&seen))
+
+	req := httptest.NewRequest("POST", "/orders/s1", strings.NewReader(`{}`))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusNoContent || seen != `{}` {
+		t.Errorf("report only mode blocked the request: %d %q", w.Code, seen)
+	}
+	if reported == nil || reported.InterfaceID != 1 || len(reported.Errors) != 2 {
+		t.Errorf("reported violation = %+v, want 2 errors for interface 1", reported)
+	}
+}
+
+func TestMiddleware_MaxBodyBytes(t *testing.T) {
+	body := `{"items": [1, 2, 3]}`
+	var seen string
+	handler := Middleware(testInterfaces(), &Options{MaxBodyBytes: 8, OnViolation: func(*http.Request, *Violation) {}})(okHandler(&seen))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("POST", "/orders/s1", strings.NewReader(body)))
+	if w.Code != http.StatusRequestEntityTooLarge || seen != "" {
+		t.Errorf("oversized body = %d %q, want 413", w.Code, seen)
+	}
+
+	handler = Middleware(testInterfaces(), &Options{MaxBodyBytes: 8, ReportOnly: true, OnViolation: func(*http.Request, *Violation) {}})(okHandler(&seen))
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("POST", "/orders/s1", strings.NewReader(body)))
+	if w.Code != http.StatusNoContent || seen != body {
+		t.Errorf("report only mode with an oversized body = %d %q, want the whole body", w.Code, seen)
+	}
+}
+
+func TestValidator_RejectUnknown(t *testing.T) {
+	v := New(testInterfaces(), &Options{RejectUnknown: true})
+	if got := v.Validate(httptest.NewRequest("GET", "/nope", nil)); got == nil {
+		t.Error("expected a violation for an unknown api")
+	}
+}
+
+func TestValidator_Prefix(t *testing.T) {
+	v := New(testInterfaces(), &Options{Prefix: "/api/", RejectUnknown: true})
+	for _, target := range []string{"/apiorders?q=x", "/orders", "/other/orders"} {
+		if got := v.Validate(httptest.NewRequest("GET", target, nil)); got != nil {
+			t.Errorf("%s outside the prefix was validated: %+v", target, got)
+		}
+	}
+	if got := v.Validate(httptest.NewRequest("GET", "/api/orders", nil)); got == nil || got.InterfaceID != 2 {
+		t.Errorf("GET /api/orders = %+v, want the missing page param", got)
+	}
+	if got := v.Validate(httptest.NewRequest("GET", "/api", nil)); got == nil {
+		t.Error("GET /api was not validated")
+	}
+}
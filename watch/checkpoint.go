@@ -0,0 +1,69 @@
+package watch
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	yapi "github.com/micrease/go-yapi"
+)
+
+// Checkpoint is the state of the project seen by the last poll: the category
+// menu and the list summaries of the interfaces, including their up_time.
+type Checkpoint struct {
+	ProjectID  int                  `json:"project_id"`
+	Categories yapi.CatMenuData     `json:"categories"`
+	Interfaces []yapi.InterfaceData `json:"interfaces"`
+}
+
+// Store persists checkpoints. Load returns nil and no error when no
+// checkpoint was saved yet.
+type Store interface {
+	Load() (*Checkpoint, error)
+	Save(cp *Checkpoint) error
+}
+
+// FileStore stores the checkpoint as a JSON file.
+type FileStore struct {
+	Path string
+}
+
+// Load reads the checkpoint file.
+func (s *FileStore) Load() (*Checkpoint, error) {
+	data, err := ioutil.ReadFile(s.Path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	cp := new(Checkpoint)
+	if err := json.Unmarshal(data, cp); err != nil {
+		return nil, err
+	}
+	return cp, nil
+}
+
+// Save writes the checkpoint to a temporary file next to Path and renames it,
+// so an interrupted write never leaves a truncated checkpoint.
+func (s *FileStore) Save(cp *Checkpoint) error {
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(s.Path), filepath.Base(s.Path)+".*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), s.Path)
+}
@@ -0,0 +1,278 @@
+// Package watch polls a YApi project and reports changes to its interfaces
+// and categories as typed events.
+package watch
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	yapi "github.com/micrease/go-yapi"
+)
+
+// EventType is the kind of change an Event reports.
+type EventType string
+
+const (
+	InterfaceAdded   EventType = "interface_added"
+	InterfaceUpdated EventType = "interface_updated"
+	InterfaceDeleted EventType = "interface_deleted"
+	CategoryChanged  EventType = "category_changed"
+)
+
+// Event is a change detected between two polls. Interface is set for the
+// interface events and holds the list summary of the interface, or its last
+// known summary when it was deleted. Category and Previous are set for
+// CategoryChanged: Previous is nil for a new category and Category is nil for
+// a removed one.
+type Event struct {
+	Type      EventType           `json:"type"`
+	ProjectID int                 `json:"project_id"`
+	Interface *yapi.InterfaceData `json:"interface,omitempty"`
+	Category  *yapi.CatData       `json:"category,omitempty"`
+	Previous  *yapi.CatData       `json:"previous,omitempty"`
+	Time      time.Time           `json:"time"`
+}
+
+// DefaultInterval is the poll interval used when Options.Interval is zero.
+const DefaultInterval = time.Minute
+
+// Options configures a Watcher.
+type Options struct {
+	// Interval between two polls of Run.
+	Interval time.Duration
+	// Store persists the checkpoint between restarts; the checkpoint is kept
+	// in memory only when nil.
+	Store Store
+	// EmitInitial reports every interface as added on the very first poll.
+	// By default the first poll only records a checkpoint.
+	EmitInitial bool
+	// OnError is called by Run with poll errors; Run keeps polling.
+	OnError func(err error)
+}
+
+// Watcher polls the project the client token belongs to.
+type Watcher struct {
+	client     *yapi.Client
+	opts       Options
+	checkpoint *Checkpoint
+	now        func() time.Time
+}
+
+// New returns a Watcher. opts may be nil.
+func New(c *yapi.Client, opts *Options) *Watcher {
+	w := &Watcher{client: c, now: time.Now}
+	if opts != nil {
+		w.opts = *opts
+	}
+	if w.opts.Interval <= 0 {
+		w.opts.Interval = DefaultInterval
+	}
+	return w
+}
+
+// Poll fetches the project once and returns the changes since the last
+// checkpoint, which is then replaced and saved.
+func (w *Watcher) Poll() ([]Event, error) {
+	events, next, err := w.poll()
+	if err != nil {
+		return nil, err
+	}
+	return events, w.commit(next)
+}
+
+// Run polls every interval until ctx is done and calls handler with every
+// event. The checkpoint is saved after handler returned for all events of a
+// poll, so events are delivered again if the process stops in between. A
+// poll whose events are cut short by ctx is not saved either.
+func (w *Watcher) Run(ctx context.Context, handler func(Event)) error {
+	return w.run(ctx, func(e Event) bool {
+		handler(e)
+		return true
+	})
+}
+
+// run implements Run with a handler reporting whether the event was
+// delivered.
+func (w *Watcher) run(ctx context.Context, deliver func(Event) bool) error {
+	ticker := time.NewTicker(w.opts.Interval)
+	defer ticker.Stop()
+	for {
+		events, next, err := w.poll()
+		if err == nil {
+			delivered := true
+			for _, e := range events {
+				if ctx.Err() != nil || !deliver(e) {
+					delivered = false
+					break
+				}
+			}
+			if !delivered || ctx.Err() != nil {
+				return ctx.Err()
+			}
+			err = w.commit(next)
+		}
+		if err != nil && w.opts.OnError != nil {
+			w.opts.OnError(err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Events runs the watcher in a goroutine and returns a channel of its events,
+// closed once ctx is done.
+func (w *Watcher) Events(ctx context.Context) <-chan Event {
+	ch := make(chan Event)
+	go func() {
+		defer close(ch)
+		w.run(ctx, func(e Event) bool {
+			select {
+			case ch <- e:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		})
+	}()
+	return ch
+}
+
+func (w *Watcher) load() (*Checkpoint, error) {
+	if w.checkpoint == nil && w.opts.Store != nil {
+		cp, err := w.opts.Store.Load()
+		if err != nil {
+			return nil, err
+		}
+		w.checkpoint = cp
+	}
+	return w.checkpoint, nil
+}
+
+func (w *Watcher) commit(next *Checkpoint) error {
+	w.checkpoint = next
+	if w.opts.Store == nil {
+		return nil
+	}
+	return w.opts.Store.Save(next)
+}
+
+// poll fetches the current state and compares it with the checkpoint.
+func (w *Watcher) poll() ([]Event, *Checkpoint, error) {
+	prev, err := w.load()
+	if err != nil {
+		return nil, nil, err
+	}
+	next, err := w.fetch()
+	if err != nil {
+		return nil, nil, err
+	}
+	if prev == nil {
+		if !w.opts.EmitInitial {
+			return nil, next, nil
+		}
+		prev = &Checkpoint{}
+	}
+	return w.compare(prev, next), next, nil
+}
+
+func (w *Watcher) fetch() (*Checkpoint, error) {
+	project, err := w.client.Project.Get()
+	if err != nil {
+		return nil, err
+	}
+	if err := yapi.CheckErrCode(project.ErrCode, project.ErrMsg); err != nil {
+		return nil, err
+	}
+	menu, err := w.client.CatMenu.Get(project.Data.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := yapi.CheckErrCode(menu.ErrCode, menu.ErrMsg); err != nil {
+		return nil, err
+	}
+	cp := &Checkpoint{ProjectID: project.Data.ID, Categories: menu.Data, Interfaces: []yapi.InterfaceData{}}
+	for _, cat := range menu.Data {
+		list, err := w.client.Interface.GetCatAll(cat.ID)
+		if err != nil {
+			return nil, err
+		}
+		cp.Interfaces = append(cp.Interfaces, list...)
+	}
+	return cp, nil
+}
+
+func (w *Watcher) compare(prev, next *Checkpoint) []Event {
+	now := w.now()
+	var events []Event
+	event := func(t EventType) Event {
+		return Event{Type: t, ProjectID: next.ProjectID, Time: now}
+	}
+
+	prevCats := make(map[int]yapi.CatData, len(prev.Categories))
+	for _, c := range prev.Categories {
+		prevCats[c.ID] = c
+	}
+	for i := range next.Categories {
+		c := next.Categories[i]
+		old, ok := prevCats[c.ID]
+		delete(prevCats, c.ID)
+		if ok && old.Name == c.Name && old.Desc == c.Desc {
+			continue
+		}
+		e := event(CategoryChanged)
+		e.Category = &c
+		if ok {
+			e.Previous = &old
+		}
+		events = append(events, e)
+	}
+	for _, c := range sortedCats(prevCats) {
+		c := c
+		e := event(CategoryChanged)
+		e.Previous = &c
+		events = append(events, e)
+	}
+
+	prevIfaces := make(map[int]yapi.InterfaceData, len(prev.Interfaces))
+	for _, d := range prev.Interfaces {
+		prevIfaces[d.ID] = d
+	}
+	for i := range next.Interfaces {
+		d := next.Interfaces[i]
+		old, ok := prevIfaces[d.ID]
+		delete(prevIfaces, d.ID)
+		var e Event
+		switch {
+		case !ok:
+			e = event(InterfaceAdded)
+		case old.UpTime != d.UpTime || old.CatID != d.CatID:
+			e = event(InterfaceUpdated)
+		default:
+			continue
+		}
+		e.Interface = &d
+		events = append(events, e)
+	}
+	for i := range prev.Interfaces {
+		d := prev.Interfaces[i]
+		if _, ok := prevIfaces[d.ID]; ok {
+			e := event(InterfaceDeleted)
+			e.Interface = &d
+			events = append(events, e)
+		}
+	}
+	return events
+}
+
+func sortedCats(m map[int]yapi.CatData) []yapi.CatData {
+	cats := make([]yapi.CatData, 0, len(m))
+	for _, c := range m {
+		cats = append(cats, c)
+	}
+	sort.Slice(cats, func(i, j int) bool { return cats[i].ID < cats[j].ID })
+	return cats
+}
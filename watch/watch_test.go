@@ -0,0 +1,198 @@
+package watch
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	yapi "github.com/micrease/go-yapi"
+)
+
+type fakeProject struct {
+	sync.Mutex
+	cats       yapi.CatMenuData
+	interfaces []yapi.InterfaceData
+}
+
+func (p *fakeProject) serve(t *testing.T) *yapi.Client {
+	reply := func(w http.ResponseWriter, data interface{}) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"errcode": 0, "errmsg": "成功！", "data": data})
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/project/get", func(w http.ResponseWriter, r *http.Request) {
+		reply(w, yapi.ProjectData{ID: 1})
+	})
+	mux.HandleFunc("/api/interface/getCatMenu", func(w http.ResponseWriter, r *http.Request) {
+		p.Lock()
+		defer p.Unlock()
+		reply(w, p.cats)
+	})
+	mux.HandleFunc("/api/interface/list_cat", func(w http.ResponseWriter, r *http.Request) {
+		p.Lock()
+		defer p.Unlock()
+		catID, _ := strconv.Atoi(r.URL.Query().Get("catid"))
+		list := []yapi.InterfaceData{}
+		for _, d := range p.interfaces {
+			if d.CatID == catID {
+				list = append(list, d)
+			}
+		}
+		reply(w, yapi.InterfaceListData{Count: len(list), Total: 1, List: list})
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	c, _ := yapi.NewClient(server.URL, "token")
+	return c
+}
+
+func testInterface(id, catID, upTime int, path string) yapi.InterfaceData {
+	var d yapi.InterfaceData
+	d.ID, d.CatID, d.UpTime, d.Method, d.Path = id, catID, upTime, "GET", path
+	return d
+}
+
+func describe(events []Event) []string {
+	var out []string
+	for _, e := range events {
+		s := string(e.Type)
+		if e.Interface != nil {
+			s += " " + e.Interface.Path
+		}
+		if e.Previous != nil {
+			s += " " + e.Previous.Name + " ->"
+		}
+		if e.Category != nil {
+			s += " " + e.Category.Name
+		}
+		out = append(out, s)
+	}
+	return out
+}
+
+func TestWatcher_Poll(t *testing.T) {
+	dir, err := ioutil.TempDir("", "watch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	store := &FileStore{Path: filepath.Join(dir, "checkpoint.json")}
+
+	p := &fakeProject{
+		cats:       yapi.CatMenuData{{ID: 10, Name: "users"}, {ID: 11, Name: "orders"}},
+		interfaces: []yapi.InterfaceData{testInterface(1, 10, 100, "/users"), testInterface(2, 11, 100, "/orders")},
+	}
+	c := p.serve(t)
+
+	w := New(c, &Options{Store: store})
+	if events, err := w.Poll(); err != nil || len(events) != 0 {
+		t.Fatalf("first poll = %v, %v; want a silent checkpoint", describe(events), err)
+	}
+
+	p.cats = yapi.CatMenuData{{ID: 10, Name: "members"}, {ID: 12, Name: "carts"}}
+	p.interfaces = []yapi.InterfaceData{testInterface(1, 10, 200, "/users"), testInterface(3, 12, 200, "/carts")}
+
+	// a restarted watcher continues from the saved checkpoint
+	w = New(c, &Options{Store: store})
+	events, err := w.Poll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"category_changed users -> members",
+		"category_changed carts",
+		"category_changed orders ->",
+		"interface_updated /users",
+		"interface_added /carts",
+		"interface_deleted /orders",
+	}
+	if got := describe(events); !reflect.DeepEqual(got, want) {
+		t.Errorf("events = %v, want %v", got, want)
+	}
+	if events, err := New(c, &Options{Store: store}).Poll(); err != nil || len(events) != 0 {
+		t.Errorf("unchanged project after restart = %v, %v", describe(events), err)
+	}
+}
+
+func TestWatcher_Events(t *testing.T) {
+	p := &fakeProject{
+		cats:       yapi.CatMenuData{{ID: 10, Name: "users"}},
+		interfaces: []yapi.InterfaceData{testInterface(1, 10, 100, "/users")},
+	}
+	w := New(p.serve(t), &Options{Interval: 10 * time.Millisecond, EmitInitial: true})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := w.Events(ctx)
+
+	if e := <-events; e.Type != CategoryChanged || e.Category.Name != "users" {
+		t.Fatalf("unexpected first event %+v", e)
+	}
+	if e := <-events; e.Type != InterfaceAdded || e.Interface.Path != "/users" || e.ProjectID != 1 {
+		t.Fatalf("unexpected second event %+v", e)
+	}
+
+	p.Lock()
+	p.interfaces[0].UpTime = 300
+	p.Unlock()
+	if e := <-events; e.Type != InterfaceUpdated || e.Interface.UpTime != 300 {
+		t.Fatalf("unexpected update event %+v", e)
+	}
+
+	cancel()
+	for range events {
+	}
+}
+
+func TestWatcher_CancelMidBatch(t *testing.T) {
+	dir, err := ioutil.TempDir("", "watch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	store := &FileStore{Path: filepath.Join(dir, "checkpoint.json")}
+
+	p := &fakeProject{
+		cats:       yapi.CatMenuData{{ID: 10, Name: "users"}},
+		interfaces: []yapi.InterfaceData{testInterface(1, 10, 100, "/users")},
+	}
+	c := p.serve(t)
+
+	// the handler stops the watcher after the first of two events
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var got []Event
+	err = New(c, &Options{Store: store, EmitInitial: true}).Run(ctx, func(e Event) {
+		got = append(got, e)
+		cancel()
+	})
+	if err != context.Canceled || len(got) != 1 {
+		t.Fatalf("Run = %v after %v", err, describe(got))
+	}
+	if cp, err := store.Load(); err != nil || cp != nil {
+		t.Fatalf("checkpoint advanced to %+v, %v", cp, err)
+	}
+
+	// events are not dropped by a cancelled channel consumer either
+	ctx, cancel = context.WithCancel(context.Background())
+	events := New(c, &Options{Store: store, EmitInitial: true}).Events(ctx)
+	<-events
+	cancel()
+	for range events {
+	}
+	if cp, err := store.Load(); err != nil || cp != nil {
+		t.Fatalf("checkpoint advanced to %+v, %v", cp, err)
+	}
+
+	events2, err := New(c, &Options{Store: store, EmitInitial: true}).Poll()
+	if err != nil || len(events2) != 2 {
+		t.Errorf("poll after cancellation = %v, %v; want both events again", describe(events2), err)
+	}
+}
@@ -0,0 +1,52 @@
+package webhook
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/micrease/go-yapi/watch"
+)
+
+// Text returns the default message text of an event, e.g.
+// "[YApi] interface updated: GET /users (list users)".
+func Text(e watch.Event) string {
+	switch e.Type {
+	case watch.InterfaceAdded, watch.InterfaceUpdated, watch.InterfaceDeleted:
+		verb := strings.TrimPrefix(string(e.Type), "interface_")
+		s := fmt.Sprintf("[YApi] interface %s: %s %s", verb, strings.ToUpper(e.Interface.Method), e.Interface.Path)
+		if e.Interface.Title != "" {
+			s += " (" + e.Interface.Title + ")"
+		}
+		return s
+	case watch.CategoryChanged:
+		switch {
+		case e.Previous == nil:
+			return "[YApi] category added: " + e.Category.Name
+		case e.Category == nil:
+			return "[YApi] category removed: " + e.Previous.Name
+		case e.Previous.Name != e.Category.Name:
+			return fmt.Sprintf("[YApi] category renamed: %s -> %s", e.Previous.Name, e.Category.Name)
+		default:
+			return "[YApi] category changed: " + e.Category.Name
+		}
+	}
+	return "[YApi] " + string(e.Type)
+}
+
+// templateData is passed to target templates.
+type templateData struct {
+	watch.Event
+	Text string
+}
+
+func (t *Target) text(e watch.Event) (string, error) {
+	text := Text(e)
+	if t.tmpl == nil {
+		return text, nil
+	}
+	var b strings.Builder
+	if err := t.tmpl.Execute(&b, templateData{e, text}); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,295 @@
+// Package webhook delivers watch events to HTTP endpoints such as chat bots
+// and CI systems, signing the payloads and retrying failed deliveries.
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/micrease/go-yapi/watch"
+)
+
+// Format is the body format of a target.
+type Format string
+
+const (
+	// FormatJSON posts {"event": ..., "text": ...} and signs it with the
+	// X-YApi-Signature header.
+	FormatJSON     Format = "json"
+	FormatDingTalk Format = "dingtalk"
+	FormatFeishu   Format = "feishu"
+	FormatSlack    Format = "slack"
+)
+
+// SignatureHeader carries the signature of json and slack payloads, see Sign.
+const SignatureHeader = "X-YApi-Signature"
+
+// TimestampHeader carries the unix time the json and slack payloads were signed at.
+const TimestampHeader = "X-YApi-Timestamp"
+
+// Target is a webhook endpoint.
+type Target struct {
+	Name   string `json:"name" yaml:"name"`
+	URL    string `json:"url" yaml:"url"`
+	Format Format `json:"format" yaml:"format"`
+	// Secret signs the payload; DingTalk and Feishu targets use their own
+	// signing schemes.
+	Secret string `json:"secret" yaml:"secret"`
+	// Template is a text/template for the message text. It is executed with
+	// the event and its default Text.
+	Template string `json:"template" yaml:"template"`
+	// Events restricts the target to the listed event types; all events are
+	// delivered when empty.
+	Events []watch.EventType `json:"events" yaml:"events"`
+
+	tmpl *template.Template
+}
+
+// Options configures a Dispatcher.
+type Options struct {
+	HTTPClient *http.Client
+	// Retries is the number of attempts after the first failed delivery.
+	Retries int
+	// Backoff is the delay before the first retry, doubled for every
+	// following retry.
+	Backoff time.Duration
+	// DeadLetter is a file that undeliverable payloads are appended to as
+	// JSON lines.
+	DeadLetter string
+}
+
+// Dispatcher posts events to its targets.
+type Dispatcher struct {
+	targets []Target
+	opts    Options
+	mu      sync.Mutex
+	now     func() time.Time
+	sleep   func(time.Duration)
+}
+
+// New returns a Dispatcher for targets. opts may be nil.
+func New(targets []Target, opts *Options) (*Dispatcher, error) {
+	d := &Dispatcher{now: time.Now, sleep: time.Sleep}
+	if opts != nil {
+		d.opts = *opts
+	}
+	if d.opts.HTTPClient == nil {
+		d.opts.HTTPClient = &http.Client{Timeout: 30 * time.Second}
+	}
+	for _, t := range targets {
+		if t.URL == "" {
+			return nil, fmt.Errorf("webhook: target %q has no url", t.Name)
+		}
+		switch t.Format {
+		case "":
+			t.Format = FormatJSON
+		case FormatJSON, FormatDingTalk, FormatFeishu, FormatSlack:
+		default:
+			return nil, fmt.Errorf("webhook: target %q has unknown format %q", t.Name, t.Format)
+		}
+		if t.Template != "" {
+			tmpl, err := template.New(t.Name).Parse(t.Template)
+			if err != nil {
+				return nil, fmt.Errorf("webhook: target %q: %v", t.Name, err)
+			}
+			t.tmpl = tmpl
+		}
+		d.targets = append(d.targets, t)
+	}
+	return d, nil
+}
+
+// Dispatch delivers e to every target subscribed to its type. Deliveries that
+// still fail after the retries are written to the dead-letter file; the
+// returned error lists them.
+func (d *Dispatcher) Dispatch(e watch.Event) error {
+	var failed []string
+	for i := range d.targets {
+		t := &d.targets[i]
+		if !t.wants(e.Type) {
+			continue
+		}
+		if err := d.deliver(t, e); err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", t.Name, err))
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("webhook: %s", strings.Join(failed, "; "))
+	}
+	return nil
+}
+
+func (t *Target) wants(typ watch.EventType) bool {
+	if len(t.Events) == 0 {
+		return true
+	}
+	for _, e := range t.Events {
+		if e == typ {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *Dispatcher) deliver(t *Target, e watch.Event) error {
+	text, err := t.text(e)
+	if err != nil {
+		return err
+	}
+	var lastErr error
+	backoff := d.opts.Backoff
+	for attempt := 0; attempt <= d.opts.Retries; attempt++ {
+		if attempt > 0 {
+			d.sleep(backoff)
+			backoff *= 2
+		}
+		// the body is rebuilt for every attempt so signatures carry a fresh timestamp
+		var req *http.Request
+		if req, lastErr = d.request(t, e, text); lastErr != nil {
+			break
+		}
+		if lastErr = d.post(req); lastErr == nil {
+			return nil
+		}
+	}
+	if dlErr := d.deadLetter(t, e, text, lastErr); dlErr != nil {
+		return fmt.Errorf("%v (dead letter: %v)", lastErr, dlErr)
+	}
+	return lastErr
+}
+
+func (d *Dispatcher) post(req *http.Request) error {
+	resp, err := d.opts.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%s: %s", resp.Status, bytes.TrimSpace(body))
+	}
+	return nil
+}
+
+// request builds the signed request of t for e.
+func (d *Dispatcher) request(t *Target, e watch.Event, text string) (*http.Request, error) {
+	ts := d.now()
+	target := t.URL
+	var payload interface{}
+	switch t.Format {
+	case FormatDingTalk:
+		payload = map[string]interface{}{"msgtype": "text", "text": map[string]string{"content": text}}
+		if t.Secret != "" {
+			// https://open.dingtalk.com/document/robots/customize-robot-security-settings
+			ms := strconv.FormatInt(ts.UnixNano()/int64(time.Millisecond), 10)
+			u, err := url.Parse(t.URL)
+			if err != nil {
+				return nil, err
+			}
+			q := u.Query()
+			q.Set("timestamp", ms)
+			q.Set("sign", base64HMAC(t.Secret, ms+"\n"+t.Secret))
+			u.RawQuery = q.Encode()
+			target = u.String()
+		}
+	case FormatFeishu:
+		body := map[string]interface{}{"msg_type": "text", "content": map[string]string{"text": text}}
+		if t.Secret != "" {
+			// Feishu signs an empty message with timestamp + "\n" + secret as key
+			sec := strconv.FormatInt(ts.Unix(), 10)
+			body["timestamp"] = sec
+			body["sign"] = base64HMAC(sec+"\n"+t.Secret, "")
+		}
+		payload = body
+	case FormatSlack:
+		payload = map[string]string{"text": text}
+	default:
+		payload = struct {
+			Event watch.Event `json:"event"`
+			Text  string      `json:"text"`
+		}{e, text}
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if t.Secret != "" && (t.Format == FormatJSON || t.Format == FormatSlack) {
+		sec := strconv.FormatInt(ts.Unix(), 10)
+		req.Header.Set(TimestampHeader, sec)
+		req.Header.Set(SignatureHeader, Sign(t.Secret, sec, body))
+	}
+	return req, nil
+}
+
+// Sign returns the value of the SignatureHeader for a body signed at
+// timestamp: the HMAC-SHA256 of timestamp + "." + body.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks the signature headers of a payload posted to a json or slack
+// target. Receivers should also reject stale timestamps.
+func Verify(secret string, header http.Header, body []byte) bool {
+	want := Sign(secret, header.Get(TimestampHeader), body)
+	return hmac.Equal([]byte(want), []byte(header.Get(SignatureHeader)))
+}
+
+func base64HMAC(key, msg string) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(msg))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// DeadLetter is a line of the dead-letter file.
+type DeadLetter struct {
+	Time   time.Time   `json:"time"`
+	Target string      `json:"target"`
+	URL    string      `json:"url"`
+	Event  watch.Event `json:"event"`
+	Text   string      `json:"text"`
+	Error  string      `json:"error"`
+}
+
+func (d *Dispatcher) deadLetter(t *Target, e watch.Event, text string, cause error) error {
+	if d.opts.DeadLetter == "" {
+		return nil
+	}
+	line, err := json.Marshal(DeadLetter{Time: d.now(), Target: t.Name, URL: t.URL, Event: e, Text: text, Error: cause.Error()})
+	if err != nil {
+		return err
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	f, err := os.OpenFile(d.opts.DeadLetter, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
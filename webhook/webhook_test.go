@@ -0,0 +1,167 @@
+package webhook
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	yapi "github.com/micrease/go-yapi"
+	"github.com/micrease/go-yapi/watch"
+)
+
+type received struct {
+	query  string
+	header http.Header
+	body   map[string]interface{}
+	raw    []byte
+}
+
+// standIn records the requests it receives and fails the first failures of them.
+func standIn(t *testing.T, failures int) (*httptest.Server, *[]received) {
+	var got []received
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		raw, _ := ioutil.ReadAll(r.Body)
+		rec := received{query: r.URL.RawQuery, header: r.Header, raw: raw}
+		json.Unmarshal(raw, &rec.body)
+		got = append(got, rec)
+		if len(got) <= failures {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, &got
+}
+
+func testEvent() watch.Event {
+	d := new(yapi.InterfaceData)
+	d.Method, d.Path, d.Title = "get", "/users", "list users"
+	return watch.Event{Type: watch.InterfaceUpdated, ProjectID: 11, Interface: d, Time: time.Unix(1650000000, 0)}
+}
+
+func newDispatcher(t *testing.T, targets []Target, opts *Options) *Dispatcher {
+	d, err := New(targets, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.now = func() time.Time { return time.Unix(1650000000, 0) }
+	d.sleep = func(time.Duration) {}
+	return d
+}
+
+func TestDispatch_Formats(t *testing.T) {
+	jsonServer, jsonGot := standIn(t, 0)
+	ding, dingGot := standIn(t, 0)
+	feishu, feishuGot := standIn(t, 0)
+	slack, slackGot := standIn(t, 0)
+
+	d := newDispatcher(t, []Target{
+		{Name: "ci", URL: jsonServer.URL, Secret: "s3cret"},
+		{Name: "ding", URL: ding.URL + "?access_token=abc", Format: FormatDingTalk, Secret: "SECxyz"},
+		{Name: "feishu", URL: feishu.URL, Format: FormatFeishu, Secret: "fs", Template: "{{.Text}} in project {{.ProjectID}}"},
+		{Name: "slack", URL: slack.URL, Format: FormatSlack, Events: []watch.EventType{watch.InterfaceDeleted}},
+	}, nil)
+	if err := d.Dispatch(testEvent()); err != nil {
+		t.Fatalf("Dispatch returned error: %v", err)
+	}
+
+	const text = "[YApi] interface updated: GET /users (list users)"
+	if len(*jsonGot) != 1 {
+		t.Fatalf("json target received %d requests", len(*jsonGot))
+	}
+	rec := (*jsonGot)[0]
+	if !Verify("s3cret", rec.header, rec.raw) || rec.header.Get(TimestampHeader) != "1650000000" {
+		t.Errorf("json signature does not verify: %v", rec.header)
+	}
+	if Verify("other", rec.header, rec.raw) {
+		t.Error("signature verified with the wrong secret")
+	}
+	if rec.body["text"] != text || rec.body["event"].(map[string]interface{})["type"] != "interface_updated" {
+		t.Errorf("json body = %s", rec.raw)
+	}
+
+	rec = (*dingGot)[0]
+	if rec.body["msgtype"] != "text" || rec.body["text"].(map[string]interface{})["content"] != text {
+		t.Errorf("dingtalk body = %s", rec.raw)
+	}
+	if q, _ := url.ParseQuery(rec.query); q.Get("access_token") != "abc" || q.Get("timestamp") != "1650000000000" ||
+		q.Get("sign") != base64HMAC("SECxyz", "1650000000000\nSECxyz") {
+		t.Errorf("dingtalk query = %s", rec.query)
+	}
+
+	rec = (*feishuGot)[0]
+	if rec.body["content"].(map[string]interface{})["text"] != text+" in project 11" ||
+		rec.body["timestamp"] != "1650000000" || rec.body["sign"] != base64HMAC("1650000000\nfs", "") {
+		t.Errorf("feishu body = %s", rec.raw)
+	}
+
+	if len(*slackGot) != 0 {
+		t.Errorf("slack target is not subscribed to updates but received %s", (*slackGot)[0].raw)
+	}
+}
+
+func TestDispatch_RetryAndDeadLetter(t *testing.T) {
+	dir, err := ioutil.TempDir("", "webhook")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	deadLetter := filepath.Join(dir, "dead.jsonl")
+
+	flaky, flakyGot := standIn(t, 2)
+	down, downGot := standIn(t, 100)
+	d := newDispatcher(t, []Target{
+		{Name: "flaky", URL: flaky.URL, Format: FormatSlack},
+		{Name: "down", URL: down.URL, Format: FormatSlack},
+	}, &Options{Retries: 2, Backoff: time.Second, DeadLetter: deadLetter})
+	var delays []time.Duration
+	d.sleep = func(delay time.Duration) { delays = append(delays, delay) }
+
+	err = d.Dispatch(testEvent())
+	if err == nil || !strings.Contains(err.Error(), "down: 503") || strings.Contains(err.Error(), "flaky") {
+		t.Errorf("Dispatch error = %v, want the down target only", err)
+	}
+	if len(*flakyGot) != 3 || len(*downGot) != 3 {
+		t.Errorf("attempts = %d/%d, want 3/3", len(*flakyGot), len(*downGot))
+	}
+	if len(delays) != 4 || delays[0] != time.Second || delays[1] != 2*time.Second {
+		t.Errorf("backoff delays = %v", delays)
+	}
+
+	f, err := os.Open(deadLetter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var lines []DeadLetter
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var l DeadLetter
+		if err := json.Unmarshal(scanner.Bytes(), &l); err != nil {
+			t.Fatal(err)
+		}
+		lines = append(lines, l)
+	}
+	if len(lines) != 1 || lines[0].Target != "down" || lines[0].Event.Interface.Path != "/users" || !strings.Contains(lines[0].Error, "503") {
+		t.Errorf("dead letters = %+v", lines)
+	}
+}
+
+func TestNew_Invalid(t *testing.T) {
+	for _, target := range []Target{
+		{Name: "no url"},
+		{Name: "format", URL: "http://localhost", Format: "teams"},
+		{Name: "template", URL: "http://localhost", Template: "{{.Text"},
+	} {
+		if _, err := New([]Target{target}, nil); err == nil {
+			t.Errorf("target %q accepted", target.Name)
+		}
+	}
+}
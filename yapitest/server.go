@@ -0,0 +1,792 @@
+// Package yapitest provides an in-memory fake of the YApi open API, so that
+// code using go-yapi can be tested without a YApi host:
+//
+//	srv := yapitest.NewServer()
+//	defer srv.Close()
+//	token := srv.AddProject(yapi.ProjectData{Name: "demo", Basepath: "/api"})
+//	client, _ := yapi.NewClient(srv.URL, token)
+//
+// The fake keeps projects, categories and interfaces, validates tokens and
+// parameters and answers with the errcode and errmsg YApi uses.
+package yapitest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	yapi "github.com/micrease/go-yapi"
+)
+
+// The errcode values returned by the fake, as by YApi.
+const (
+	ErrCodeOK           = 0
+	ErrCodeBadRequest   = 400
+	ErrCodeNoPermission = 405
+	ErrCodeNotFound     = 490
+	ErrCodeInvalidToken = 40011
+	ErrCodeExists       = 40022
+)
+
+// Server is a fake YApi. Its methods are safe for concurrent use.
+type Server struct {
+	// URL is the base URL of the server started by NewServer.
+	URL string
+
+	// Now returns the time stored in add_time and up_time, time.Now by
+	// default.
+	Now func() time.Time
+
+	srv    *httptest.Server
+	mu     sync.Mutex
+	nextID int
+	// projects by token; cats and interfaces by ID, and their creation order
+	projects   map[string]*yapi.ProjectData
+	cats       map[int]*category
+	catOrder   []int
+	interfaces map[int]*yapi.InterfaceData
+	ifaceOrder []int
+}
+
+type category struct {
+	ID        int    `json:"_id"`
+	UID       int    `json:"uid"`
+	ProjectID int    `json:"project_id"`
+	Name      string `json:"name"`
+	Desc      string `json:"desc"`
+	AddTime   int    `json:"add_time"`
+	UpTime    int    `json:"up_time"`
+	Index     int    `json:"index"`
+}
+
+// New returns a fake that is not listening; use it as an http.Handler.
+func New() *Server {
+	return &Server{
+		Now:        time.Now,
+		nextID:     10,
+		projects:   map[string]*yapi.ProjectData{},
+		cats:       map[int]*category{},
+		interfaces: map[int]*yapi.InterfaceData{},
+	}
+}
+
+// NewServer starts a fake on a local port. Call Close when done.
+func NewServer() *Server {
+	s := New()
+	s.srv = httptest.NewServer(s)
+	s.URL = s.srv.URL
+	return s
+}
+
+// Close stops the server started by NewServer.
+func (s *Server) Close() {
+	if s.srv != nil {
+		s.srv.Close()
+	}
+}
+
+// Client returns a client of the project of token.
+func (s *Server) Client(token string) *yapi.Client {
+	c, err := yapi.NewClient(s.URL, token)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+func (s *Server) id() int {
+	s.nextID++
+	return s.nextID
+}
+
+func (s *Server) now() int {
+	return int(s.Now().Unix())
+}
+
+// AddProject creates a project with a "公共分类" category, as YApi does, and
+// returns its token. The project gets an ID unless p has one.
+func (s *Server) AddProject(p yapi.ProjectData) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if p.ID == 0 {
+		p.ID = s.id()
+	}
+	token := fmt.Sprintf("token-%d", p.ID)
+	s.projects[token] = &p
+	s.addCat(&category{ProjectID: p.ID, Name: "公共分类", Desc: "公共分类"})
+	return token
+}
+
+// AddCategory creates a category and returns its ID.
+func (s *Server) AddCategory(projectID int, c yapi.CatData) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addCat(&category{ID: c.ID, UID: c.UID, ProjectID: projectID, Name: c.Name, Desc: c.Desc, Index: c.Index})
+}
+
+func (s *Server) addCat(c *category) int {
+	if c.ID == 0 {
+		c.ID = s.id()
+	}
+	c.AddTime, c.UpTime = s.now(), s.now()
+	s.cats[c.ID] = c
+	s.catOrder = append(s.catOrder, c.ID)
+	return c.ID
+}
+
+// AddInterface stores an interface, which must have a ProjectID and CatID,
+// and returns its ID.
+func (s *Server) AddInterface(d yapi.InterfaceData) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addInterface(&d)
+}
+
+func (s *Server) addInterface(d *yapi.InterfaceData) int {
+	if d.ID == 0 {
+		d.ID = s.id()
+	}
+	if d.Status == "" {
+		d.Status = "undone"
+	}
+	d.Method = strings.ToUpper(d.Method)
+	d.AddTime, d.UpTime = s.now(), s.now()
+	s.interfaces[d.ID] = d
+	s.ifaceOrder = append(s.ifaceOrder, d.ID)
+	return d.ID
+}
+
+// Categories returns the categories of a project in menu order: by index,
+// then in creation order.
+func (s *Server) Categories(projectID int) []yapi.CatData {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var list []yapi.CatData
+	for _, c := range s.projectCats(projectID) {
+		list = append(list, yapi.CatData{ID: c.ID, UID: c.UID, Name: c.Name, Desc: c.Desc, Index: c.Index})
+	}
+	return list
+}
+
+// Interfaces returns the interfaces of a project in menu order: by index,
+// then in creation order.
+func (s *Server) Interfaces(projectID int) []yapi.InterfaceData {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var list []yapi.InterfaceData
+	for _, d := range s.ordered() {
+		if d.ProjectID == projectID {
+			list = append(list, *d)
+		}
+	}
+	return list
+}
+
+func (s *Server) projectCats(projectID int) []*category {
+	var list []*category
+	for _, id := range s.catOrder {
+		if c := s.cats[id]; c.ProjectID == projectID {
+			list = append(list, c)
+		}
+	}
+	sort.SliceStable(list, func(i, j int) bool { return list[i].Index < list[j].Index })
+	return list
+}
+
+// ordered returns every interface sorted by index, then in creation order.
+func (s *Server) ordered() []*yapi.InterfaceData {
+	list := make([]*yapi.InterfaceData, len(s.ifaceOrder))
+	for i, id := range s.ifaceOrder {
+		list[i] = s.interfaces[id]
+	}
+	sort.SliceStable(list, func(i, j int) bool { return list[i].Index < list[j].Index })
+	return list
+}
+
+// request holds the parameters of a call, from the query string for GET
+// and from the JSON body otherwise.
+type request struct {
+	query url.Values
+	body  map[string]json.RawMessage
+	raw   []byte
+}
+
+func (r *request) param(name string) string {
+	if raw, ok := r.body[name]; ok {
+		var v string
+		if json.Unmarshal(raw, &v) == nil {
+			return v
+		}
+		return strings.Trim(string(raw), `"`)
+	}
+	return r.query.Get(name)
+}
+
+func (r *request) intParam(name string) int {
+	n, _ := strconv.Atoi(r.param(name))
+	return n
+}
+
+// apiError is an answer with a non zero errcode.
+type apiError struct {
+	code int
+	msg  string
+}
+
+func fail(code int, format string, args ...interface{}) *apiError {
+	return &apiError{code: code, msg: fmt.Sprintf(format, args...)}
+}
+
+type handler func(s *Server, p *yapi.ProjectData, r *request) (interface{}, *apiError)
+
+var routes = map[string]handler{
+	"GET /api/project/get":             getProject,
+	"GET /api/interface/getCatMenu":    getCatMenu,
+	"POST /api/interface/add_cat":      addCat,
+	"POST /api/interface/up_cat":       upCat,
+	"POST /api/interface/del_cat":      delCat,
+	"GET /api/interface/list_cat":      listCat,
+	"GET /api/interface/list":          listInterfaces,
+	"GET /api/interface/list_menu":     listMenu,
+	"GET /api/interface/get":           getInterface,
+	"POST /api/interface/add":          addInterface,
+	"POST /api/interface/save":         saveInterface,
+	"POST /api/interface/up":           upInterface,
+	"POST /api/interface/del":          delInterface,
+	"POST /api/interface/up_index":     upIndex,
+	"POST /api/interface/up_cat_index": upCatIndex,
+	"POST /api/open/import_data":       importData,
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	h, ok := routes[req.Method+" "+req.URL.Path]
+	if !ok {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"errcode":404,"errmsg":"不存在的api"}`))
+		return
+	}
+	r := &request{query: req.URL.Query()}
+	if req.Method != http.MethodGet {
+		r.raw, _ = ioutil.ReadAll(req.Body)
+		bad := len(r.raw) > 0 && !json.Valid(r.raw)
+		// array bodies, as sent to the index apis, are only kept in raw
+		if !bad && len(r.raw) > 0 && r.raw[0] == '{' {
+			bad = json.Unmarshal(r.raw, &r.body) != nil
+		}
+		if bad {
+			reply(w, nil, fail(ErrCodeBadRequest, "请求参数格式错误"))
+			return
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, ok := s.projects[r.param("token")]
+	if !ok {
+		reply(w, nil, fail(ErrCodeInvalidToken, "请登录..."))
+		return
+	}
+	data, err := h(s, p, r)
+	reply(w, data, err)
+}
+
+func reply(w http.ResponseWriter, data interface{}, err *apiError) {
+	resp := struct {
+		ErrCode int         `json:"errcode"`
+		ErrMsg  string      `json:"errmsg"`
+		Data    interface{} `json:"data"`
+	}{ErrCodeOK, "成功！", data}
+	if err != nil {
+		resp.ErrCode, resp.ErrMsg, resp.Data = err.code, err.msg, nil
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+func modified(n int) yapi.ModifyResult {
+	return yapi.ModifyResult{Ok: 1, N: n, NModified: n}
+}
+
+func getProject(s *Server, p *yapi.ProjectData, r *request) (interface{}, *apiError) {
+	return p, nil
+}
+
+func getCatMenu(s *Server, p *yapi.ProjectData, r *request) (interface{}, *apiError) {
+	if err := checkProject(p, r); err != nil {
+		return nil, err
+	}
+	list := s.projectCats(p.ID)
+	if list == nil {
+		list = []*category{}
+	}
+	return list, nil
+}
+
+// checkProject verifies that the project_id parameter is the project of the token.
+func checkProject(p *yapi.ProjectData, r *request) *apiError {
+	id := r.param("project_id")
+	if id == "" {
+		return fail(ErrCodeBadRequest, "项目id不能为空")
+	}
+	if id != strconv.Itoa(p.ID) {
+		return fail(ErrCodeNoPermission, "没有权限")
+	}
+	return nil
+}
+
+// category returns the category id of the project.
+func (s *Server) category(p *yapi.ProjectData, id int) (*category, *apiError) {
+	c, ok := s.cats[id]
+	if !ok {
+		return nil, fail(ErrCodeBadRequest, "不存在的分类")
+	}
+	if c.ProjectID != p.ID {
+		return nil, fail(ErrCodeNoPermission, "没有权限")
+	}
+	return c, nil
+}
+
+func addCat(s *Server, p *yapi.ProjectData, r *request) (interface{}, *apiError) {
+	if err := checkProject(p, r); err != nil {
+		return nil, err
+	}
+	name := r.param("name")
+	if name == "" {
+		return nil, fail(ErrCodeBadRequest, "名称不能为空")
+	}
+	c := &category{ProjectID: p.ID, Name: name, Desc: r.param("desc")}
+	s.addCat(c)
+	return c, nil
+}
+
+func upCat(s *Server, p *yapi.ProjectData, r *request) (interface{}, *apiError) {
+	c, err := s.category(p, r.intParam("catid"))
+	if err != nil {
+		return nil, err
+	}
+	if name := r.param("name"); name != "" {
+		c.Name = name
+	}
+	if _, ok := r.body["desc"]; ok {
+		c.Desc = r.param("desc")
+	}
+	c.UpTime = s.now()
+	return modified(1), nil
+}
+
+func delCat(s *Server, p *yapi.ProjectData, r *request) (interface{}, *apiError) {
+	c, err := s.category(p, r.intParam("catid"))
+	if err != nil {
+		return nil, err
+	}
+	delete(s.cats, c.ID)
+	s.catOrder = remove(s.catOrder, c.ID)
+	for _, id := range append([]int(nil), s.ifaceOrder...) {
+		if s.interfaces[id].CatID == c.ID {
+			delete(s.interfaces, id)
+			s.ifaceOrder = remove(s.ifaceOrder, id)
+		}
+	}
+	return modified(1), nil
+}
+
+func remove(ids []int, id int) []int {
+	for i, v := range ids {
+		if v == id {
+			return append(ids[:i], ids[i+1:]...)
+		}
+	}
+	return ids
+}
+
+// summary returns the fields of an interface sent by the list apis.
+func summary(d *yapi.InterfaceData) yapi.InterfaceData {
+	var sum yapi.InterfaceData
+	sum.ID, sum.UID, sum.CatID, sum.ProjectID = d.ID, d.UID, d.CatID, d.ProjectID
+	sum.EditUID, sum.AddTime, sum.UpTime = d.EditUID, d.AddTime, d.UpTime
+	sum.Status, sum.Title, sum.Path, sum.Method, sum.Tag, sum.Index = d.Status, d.Title, d.Path, d.Method, d.Tag, d.Index
+	return sum
+}
+
+// page returns the page of the summaries of the interfaces matching keep,
+// with the number of pages as total like YApi.
+func (s *Server) page(r *request, keep func(d *yapi.InterfaceData) bool) interface{} {
+	var all []yapi.InterfaceData
+	for _, d := range s.ordered() {
+		if keep(d) {
+			all = append(all, summary(d))
+		}
+	}
+	pageNo, limit := r.intParam("page"), r.intParam("limit")
+	if pageNo < 1 {
+		pageNo = 1
+	}
+	if limit < 1 {
+		limit = 10
+	}
+	list := []yapi.InterfaceData{}
+	if start := (pageNo - 1) * limit; start < len(all) {
+		end := start + limit
+		if end > len(all) {
+			end = len(all)
+		}
+		list = all[start:end]
+	}
+	return yapi.InterfaceListData{Count: len(all), Total: (len(all) + limit - 1) / limit, List: list}
+}
+
+func listCat(s *Server, p *yapi.ProjectData, r *request) (interface{}, *apiError) {
+	if r.param("catid") == "" {
+		return nil, fail(ErrCodeBadRequest, "catid不能为空")
+	}
+	c, err := s.category(p, r.intParam("catid"))
+	if err != nil {
+		return nil, err
+	}
+	return s.page(r, func(d *yapi.InterfaceData) bool { return d.CatID == c.ID }), nil
+}
+
+func listInterfaces(s *Server, p *yapi.ProjectData, r *request) (interface{}, *apiError) {
+	if err := checkProject(p, r); err != nil {
+		return nil, err
+	}
+	return s.page(r, func(d *yapi.InterfaceData) bool { return d.ProjectID == p.ID }), nil
+}
+
+func listMenu(s *Server, p *yapi.ProjectData, r *request) (interface{}, *apiError) {
+	if err := checkProject(p, r); err != nil {
+		return nil, err
+	}
+	type menu struct {
+		*category
+		List []yapi.InterfaceData `json:"list"`
+	}
+	menus := []menu{}
+	for _, c := range s.projectCats(p.ID) {
+		m := menu{category: c, List: []yapi.InterfaceData{}}
+		for _, d := range s.ordered() {
+			if d.CatID == c.ID {
+				m.List = append(m.List, summary(d))
+			}
+		}
+		menus = append(menus, m)
+	}
+	return menus, nil
+}
+
+// iface returns the interface id of the project.
+func (s *Server) iface(p *yapi.ProjectData, id int) (*yapi.InterfaceData, *apiError) {
+	d, ok := s.interfaces[id]
+	if !ok {
+		return nil, fail(ErrCodeNotFound, "不存在的")
+	}
+	if d.ProjectID != p.ID {
+		return nil, fail(ErrCodeNoPermission, "没有权限")
+	}
+	return d, nil
+}
+
+func getInterface(s *Server, p *yapi.ProjectData, r *request) (interface{}, *apiError) {
+	if r.param("id") == "" {
+		return nil, fail(ErrCodeBadRequest, "接口id不能为空")
+	}
+	return s.iface(p, r.intParam("id"))
+}
+
+// decode reads the interface sent in the body and checks its path and
+// method.
+func decode(p *yapi.ProjectData, r *request) (*yapi.InterfaceData, *apiError) {
+	d := new(yapi.InterfaceData)
+	if err := json.Unmarshal(r.raw, d); err != nil {
+		return nil, fail(ErrCodeBadRequest, "请求参数格式错误")
+	}
+	d.ProjectID = p.ID
+	d.Method = strings.ToUpper(d.Method)
+	if d.Method == "" {
+		d.Method = http.MethodGet
+	}
+	if !validPath(d.Path) {
+		return nil, fail(ErrCodeBadRequest, "path第一位必需为 /, 只允许由 字母数字-/_:.! 组成")
+	}
+	return d, nil
+}
+
+func validPath(path string) bool {
+	if !strings.HasPrefix(path, "/") {
+		return false
+	}
+	for _, c := range path {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || strings.ContainsRune("-/_:.!{}=", c)) {
+			return false
+		}
+	}
+	return true
+}
+
+// find returns the interface of the project with the method and path.
+func (s *Server) find(projectID int, method, path string) *yapi.InterfaceData {
+	for _, id := range s.ifaceOrder {
+		if d := s.interfaces[id]; d.ProjectID == projectID && d.Method == method && d.Path == path {
+			return d
+		}
+	}
+	return nil
+}
+
+func (s *Server) create(p *yapi.ProjectData, d *yapi.InterfaceData) (interface{}, *apiError) {
+	if d.Title == "" {
+		return nil, fail(ErrCodeBadRequest, "接口名称不能为空")
+	}
+	if _, err := s.category(p, d.CatID); err != nil {
+		return nil, err
+	}
+	d.ID = 0
+	s.addInterface(d)
+	return d, nil
+}
+
+func addInterface(s *Server, p *yapi.ProjectData, r *request) (interface{}, *apiError) {
+	d, err := decode(p, r)
+	if err != nil {
+		return nil, err
+	}
+	if s.find(p.ID, d.Method, d.Path) != nil {
+		return nil, fail(ErrCodeExists, "已存在的接口:%s[%s]", d.Path, d.Method)
+	}
+	return s.create(p, d)
+}
+
+// saveInterface updates the interface with the same method and path, or
+// creates it.
+func saveInterface(s *Server, p *yapi.ProjectData, r *request) (interface{}, *apiError) {
+	d, err := decode(p, r)
+	if err != nil {
+		return nil, err
+	}
+	old := s.find(p.ID, d.Method, d.Path)
+	if old == nil {
+		return s.create(p, d)
+	}
+	if d.CatID == 0 {
+		d.CatID = old.CatID
+	} else if _, err := s.category(p, d.CatID); err != nil {
+		return nil, err
+	}
+	if d.Title == "" {
+		d.Title = old.Title
+	}
+	keepIndex(old, d, r)
+	d.ID, d.AddTime, d.UpTime = old.ID, old.AddTime, s.now()
+	*old = *d
+	return []yapi.ModifyResult{modified(1)}, nil
+}
+
+func upInterface(s *Server, p *yapi.ProjectData, r *request) (interface{}, *apiError) {
+	old, err := s.iface(p, r.intParam("id"))
+	if err != nil {
+		return nil, err
+	}
+	d, err := decode(p, r)
+	if err != nil {
+		return nil, err
+	}
+	if other := s.find(p.ID, d.Method, d.Path); other != nil && other != old {
+		return nil, fail(ErrCodeExists, "已存在的接口:%s[%s]", d.Path, d.Method)
+	}
+	if d.CatID == 0 {
+		d.CatID = old.CatID
+	} else if _, err := s.category(p, d.CatID); err != nil {
+		return nil, err
+	}
+	keepIndex(old, d, r)
+	d.ID, d.AddTime, d.UpTime = old.ID, old.AddTime, s.now()
+	*old = *d
+	return modified(1), nil
+}
+
+// keepIndex keeps the menu position of an updated interface unless the
+// request sets it, as YApi only updates the fields it is sent.
+func keepIndex(old, d *yapi.InterfaceData, r *request) {
+	if _, ok := r.body["index"]; !ok {
+		d.Index = old.Index
+	}
+}
+
+// decodeIndex reads the [{"id": 1, "index": 0}] body of the index apis.
+func decodeIndex(r *request) ([]yapi.IndexItem, *apiError) {
+	var items []yapi.IndexItem
+	if err := json.Unmarshal(r.raw, &items); err != nil {
+		return nil, fail(ErrCodeBadRequest, "请求参数格式错误")
+	}
+	return items, nil
+}
+
+func upIndex(s *Server, p *yapi.ProjectData, r *request) (interface{}, *apiError) {
+	items, err := decodeIndex(r)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range items {
+		d, err := s.iface(p, item.ID)
+		if err != nil {
+			return nil, err
+		}
+		d.Index = item.Index
+	}
+	return "成功！", nil
+}
+
+func upCatIndex(s *Server, p *yapi.ProjectData, r *request) (interface{}, *apiError) {
+	items, err := decodeIndex(r)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range items {
+		c, err := s.category(p, item.ID)
+		if err != nil {
+			return nil, err
+		}
+		c.Index = item.Index
+	}
+	return "成功！", nil
+}
+
+func delInterface(s *Server, p *yapi.ProjectData, r *request) (interface{}, *apiError) {
+	d, err := s.iface(p, r.intParam("id"))
+	if err != nil {
+		return nil, err
+	}
+	delete(s.interfaces, d.ID)
+	s.ifaceOrder = remove(s.ifaceOrder, d.ID)
+	return modified(1), nil
+}
+
+// importData imports a "json" export or a swagger 2.0 document. The merge
+// modes follow YApi: "normal" skips the existing interfaces, "good" updates
+// them but keeps their response body, "merge" replaces them.
+func importData(s *Server, p *yapi.ProjectData, r *request) (interface{}, *apiError) {
+	var export yapi.ExportData
+	data := r.param("json")
+	switch r.param("type") {
+	case "json":
+		if err := json.Unmarshal([]byte(data), &export); err != nil {
+			return nil, fail(ErrCodeBadRequest, "json 格式有误")
+		}
+	case "swagger":
+		var err error
+		if export, err = swaggerExport(data); err != nil {
+			return nil, fail(ErrCodeBadRequest, "swagger 格式有误")
+		}
+	default:
+		return nil, fail(ErrCodeBadRequest, "不存在的导入方式")
+	}
+	merge := r.param("merge")
+	if merge == "" {
+		merge = "normal"
+	}
+	if merge != "normal" && merge != "good" && merge != "merge" {
+		return nil, fail(ErrCodeBadRequest, "merge 参数有误")
+	}
+	for _, ec := range export {
+		var cat *category
+		for _, c := range s.projectCats(p.ID) {
+			if c.Name == ec.Name {
+				cat = c
+				break
+			}
+		}
+		if cat == nil {
+			cat = &category{ProjectID: p.ID, Name: ec.Name, Desc: ec.Desc}
+			s.addCat(cat)
+		}
+		for i := range ec.List {
+			d := ec.List[i]
+			d.ProjectID, d.CatID = p.ID, cat.ID
+			d.Method = strings.ToUpper(d.Method)
+			old := s.find(p.ID, d.Method, d.Path)
+			switch {
+			case old == nil:
+				d.ID = 0
+				s.addInterface(&d)
+			case merge == "normal":
+			default:
+				if merge == "good" && old.ResBody != "" {
+					d.ResBodyType, d.ResBody, d.ResBodyIsJsonSchema = old.ResBodyType, old.ResBody, old.ResBodyIsJsonSchema
+				}
+				d.ID, d.AddTime, d.UpTime = old.ID, old.AddTime, s.now()
+				*old = d
+			}
+		}
+	}
+	return nil, nil
+}
+
+// swaggerExport converts the paths of a swagger 2.0 document, grouped by
+// their first tag.
+func swaggerExport(data string) (yapi.ExportData, error) {
+	var doc struct {
+		Tags []struct {
+			Name        string `json:"name"`
+			Description string `json:"description"`
+		} `json:"tags"`
+		Paths map[string]map[string]struct {
+			Summary     string   `json:"summary"`
+			Description string   `json:"description"`
+			Tags        []string `json:"tags"`
+		} `json:"paths"`
+	}
+	if err := json.Unmarshal([]byte(data), &doc); err != nil {
+		return nil, err
+	}
+	var export yapi.ExportData
+	index := map[string]int{}
+	cat := func(name, desc string) int {
+		i, ok := index[name]
+		if !ok {
+			i = len(export)
+			index[name] = i
+			export = append(export, yapi.ExportCat{Index: i, Name: name, Desc: desc})
+		}
+		return i
+	}
+	for _, t := range doc.Tags {
+		cat(t.Name, t.Description)
+	}
+	paths := make([]string, 0, len(doc.Paths))
+	for path := range doc.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		methods := make([]string, 0, len(doc.Paths[path]))
+		for m := range doc.Paths[path] {
+			methods = append(methods, m)
+		}
+		sort.Strings(methods)
+		for _, m := range methods {
+			op := doc.Paths[path][m]
+			name := "default"
+			if len(op.Tags) > 0 {
+				name = op.Tags[0]
+			}
+			var d yapi.InterfaceData
+			d.Method, d.Path, d.Title = strings.ToUpper(m), path, op.Summary
+			if d.Title == "" {
+				d.Title = path
+			}
+			i := cat(name, name)
+			export[i].List = append(export[i].List, d)
+		}
+	}
+	return export, nil
+}
@@ -0,0 +1,153 @@
+package yapitest
+
+import (
+	"encoding/json"
+	"testing"
+
+	yapi "github.com/micrease/go-yapi"
+)
+
+func TestServer(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	token := srv.AddProject(yapi.ProjectData{Name: "demo", Basepath: "/api"})
+	other := srv.AddProject(yapi.ProjectData{Name: "other"})
+	client := srv.Client(token)
+
+	project, err := client.Project.Get()
+	if err != nil || project.ErrCode != 0 || project.Data.Name != "demo" {
+		t.Fatalf("project = %+v, %v", project, err)
+	}
+	projectID := project.Data.ID
+	if resp, err := srv.Client("bad").Project.Get(); err != nil || resp.ErrCode != ErrCodeInvalidToken {
+		t.Errorf("bad token = %+v, %v", resp, err)
+	}
+
+	param := new(yapi.ModifyMenuParam)
+	param.ProjectID, param.Name = projectID, "orders"
+	added, err := client.CatMenu.AddOrUpdate(param)
+	if err != nil || added.ErrCode != 0 {
+		t.Fatalf("add_cat = %+v, %v", added, err)
+	}
+	param.Name = ""
+	if resp, _ := client.CatMenu.AddOrUpdate(param); resp.ErrCode != ErrCodeBadRequest {
+		t.Errorf("add_cat without name = %+v", resp)
+	}
+	menu, err := client.CatMenu.Get(projectID)
+	if err != nil || len(menu.Data) != 2 || menu.Data[0].Name != "公共分类" || menu.Data[1].Name != "orders" {
+		t.Fatalf("menu = %+v, %v", menu, err)
+	}
+	catID := menu.Data[1].ID
+	if resp, _ := srv.Client(other).CatMenu.Get(projectID); resp.ErrCode != ErrCodeNoPermission {
+		t.Errorf("menu of another project = %+v", resp)
+	}
+
+	var d yapi.InterfaceData
+	d.ProjectID, d.CatID, d.Method, d.Path, d.Title = projectID, catID, "get", "/orders/{id}", "get order"
+	if resp, err := client.Interface.AddOrUpdate(&d); err != nil || resp.ErrCode != 0 {
+		t.Fatalf("save = %+v, %v", resp, err)
+	}
+	d.Title = "get one order"
+	if resp, err := client.Interface.AddOrUpdate(&d); err != nil || resp.ErrCode != 0 {
+		t.Fatalf("second save = %+v, %v", resp, err)
+	}
+	d.Path = "orders"
+	if resp, _ := client.Interface.AddOrUpdate(&d); resp.ErrCode != ErrCodeBadRequest {
+		t.Errorf("save with a bad path = %+v", resp)
+	}
+	list := srv.Interfaces(projectID)
+	if len(list) != 1 || list[0].Title != "get one order" || list[0].Method != "GET" {
+		t.Fatalf("interfaces = %+v", list)
+	}
+	id := list[0].ID
+
+	all, err := client.Interface.GetAll(projectID)
+	if err != nil || len(all) != 1 || all[0].ID != id {
+		t.Errorf("all = %+v, %v", all, err)
+	}
+	if resp, _ := client.Interface.Get(id + 100); resp.ErrCode != ErrCodeNotFound {
+		t.Errorf("get unknown = %+v", resp)
+	}
+	got, _ := client.Interface.Get(id)
+	got.Data.Path = "/orders/{id}/items"
+	if resp, err := client.Interface.Update(&got.Data); err != nil || resp.ErrCode != 0 {
+		t.Fatalf("up = %+v, %v", resp, err)
+	}
+	if list := srv.Interfaces(projectID); list[0].Path != "/orders/{id}/items" || list[0].ID != id {
+		t.Errorf("after up = %+v", list)
+	}
+
+	export := yapi.ExportData{{Name: "orders", List: make([]yapi.InterfaceData, 2)}}
+	export[0].List[0].Method, export[0].List[0].Path, export[0].List[0].Title = "GET", "/orders/{id}/items", "replaced"
+	export[0].List[1].Method, export[0].List[1].Path, export[0].List[1].Title = "POST", "/orders", "create order"
+	data, _ := json.Marshal(export)
+	if resp, err := client.Interface.Import("json", "normal", string(data)); err != nil || resp.ErrCode != 0 {
+		t.Fatalf("import = %+v, %v", resp, err)
+	}
+	list = srv.Interfaces(projectID)
+	if len(list) != 2 || list[0].Title != "get one order" || list[1].CatID != catID {
+		t.Errorf("after normal import = %+v", list)
+	}
+	client.Interface.Import("json", "merge", string(data))
+	if list := srv.Interfaces(projectID); list[0].Title != "replaced" {
+		t.Errorf("after merge import = %+v", list)
+	}
+	swagger := `{"swagger": "2.0", "tags": [{"name": "pets"}], "paths": {"/pets": {"get": {"summary": "list pets", "tags": ["pets"]}}}}`
+	if resp, err := client.Interface.UploadSwagger(&swagger); err != nil || resp.ErrCode != 0 {
+		t.Fatalf("swagger import = %+v, %v", resp, err)
+	}
+	if cats := srv.Categories(projectID); len(cats) != 3 || cats[2].Name != "pets" {
+		t.Errorf("categories after swagger import = %+v", cats)
+	}
+
+	if resp, err := client.Interface.Delete(id); err != nil || resp.ErrCode != 0 {
+		t.Fatalf("del = %+v, %v", resp, err)
+	}
+	if resp, err := client.CatMenu.Delete(catID); err != nil || resp.ErrCode != 0 {
+		t.Fatalf("del_cat = %+v, %v", resp, err)
+	}
+	if list := srv.Interfaces(projectID); len(list) != 1 || list[0].Path != "/pets" {
+		t.Errorf("after deletes = %+v", list)
+	}
+}
+
+func TestServer_Index(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	client := srv.Client(srv.AddProject(yapi.ProjectData{Name: "demo"}))
+	project, _ := client.Project.Get()
+	projectID := project.Data.ID
+	catID := srv.AddCategory(projectID, yapi.CatData{Name: "orders"})
+	var ids []int
+	for _, path := range []string{"/a", "/b", "/c"} {
+		var d yapi.InterfaceData
+		d.ProjectID, d.CatID, d.Method, d.Path, d.Title = projectID, catID, "GET", path, path
+		ids = append(ids, srv.AddInterface(d))
+	}
+
+	items := []yapi.IndexItem{{ID: ids[0], Index: 2}, {ID: ids[1], Index: 1}}
+	if resp, err := client.Interface.UpIndex(items); err != nil || resp.ErrCode != 0 {
+		t.Fatalf("up_index = %+v, %v", resp, err)
+	}
+	list, err := client.Interface.GetCatAll(catID)
+	if err != nil || len(list) != 3 || list[0].Path != "/c" || list[1].Path != "/b" || list[2].Index != 2 {
+		t.Errorf("list after up_index = %+v, %v", list, err)
+	}
+	// saving an interface keeps its position
+	list[2].Title = "renamed"
+	client.Interface.AddOrUpdate(&list[2])
+	if all := srv.Interfaces(projectID); all[2].Path != "/a" || all[2].Title != "renamed" {
+		t.Errorf("interfaces after save = %+v", all)
+	}
+
+	cats := srv.Categories(projectID)
+	if resp, err := client.CatMenu.UpIndex([]yapi.IndexItem{{ID: cats[0].ID, Index: 1}}); err != nil || resp.ErrCode != 0 {
+		t.Fatalf("up_cat_index = %+v, %v", resp, err)
+	}
+	if menu, _ := client.CatMenu.Get(projectID); menu.Data[0].Name != "orders" || menu.Data[1].Index != 1 {
+		t.Errorf("menu after up_cat_index = %+v", menu.Data)
+	}
+	if resp, _ := client.Interface.UpIndex([]yapi.IndexItem{{ID: 9999}}); resp.ErrCode != ErrCodeNotFound {
+		t.Errorf("up_index of an unknown interface = %+v", resp)
+	}
+}